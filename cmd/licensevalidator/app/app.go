@@ -2,17 +2,19 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
+	"io/ioutil"
 	"log"
-	"net"
 	"net/http"
 	"net/http/pprof"
 	"net/url"
 	"regexp"
 	"strings"
+	texttemplate "text/template"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -30,19 +32,32 @@ import (
 	"go.opentelemetry.io/otel/sdk/metric/controller/push"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
-	"golang.org/x/oauth2"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
 
 	"github.com/xakep666/licensevalidator/internal/preload"
+	"github.com/xakep666/licensevalidator/pkg/api"
 	"github.com/xakep666/licensevalidator/pkg/athens"
+	"github.com/xakep666/licensevalidator/pkg/auth"
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/bitbucket"
 	"github.com/xakep666/licensevalidator/pkg/cache"
+	"github.com/xakep666/licensevalidator/pkg/git"
+	"github.com/xakep666/licensevalidator/pkg/gitea"
 	"github.com/xakep666/licensevalidator/pkg/github"
+	"github.com/xakep666/licensevalidator/pkg/gitlab"
 	"github.com/xakep666/licensevalidator/pkg/golang"
 	"github.com/xakep666/licensevalidator/pkg/gopkg"
 	"github.com/xakep666/licensevalidator/pkg/goproxy"
 	"github.com/xakep666/licensevalidator/pkg/health"
+	"github.com/xakep666/licensevalidator/pkg/modcache"
+	"github.com/xakep666/licensevalidator/pkg/netacl"
+	"github.com/xakep666/licensevalidator/pkg/notice"
 	"github.com/xakep666/licensevalidator/pkg/observ"
 	"github.com/xakep666/licensevalidator/pkg/override"
+	"github.com/xakep666/licensevalidator/pkg/proxy"
+	"github.com/xakep666/licensevalidator/pkg/sbom"
 	"github.com/xakep666/licensevalidator/pkg/spdx"
+	"github.com/xakep666/licensevalidator/pkg/tlsreload"
 	"github.com/xakep666/licensevalidator/pkg/validation"
 )
 
@@ -52,6 +67,10 @@ type App struct {
 	healthServer  *http.Server // non-nil if enabled in config
 	healthChecker *health.Health
 	tracerFlush   func()
+
+	// background contains long-running tasks which should work while app runs
+	background     []func(ctx context.Context)
+	stopBackground context.CancelFunc
 }
 
 func NewApp(cfg Config) (*App, error) {
@@ -79,63 +98,176 @@ func NewApp(cfg Config) (*App, error) {
 
 	meter := pushController.Meter("")
 
-	translator, err := translator(logger, &cfg)
+	stack, err := setupValidation(&cfg, logger, tracer, meter, hc)
 	if err != nil {
-		return nil, fmt.Errorf("translator init failed: %w", err)
+		return nil, err
 	}
 
-	c, err := setupCache(&cfg, cache.Direct{
-		LicenseResolver: &observ.LicenseResolver{
-			LicenseResolver: &validation.ChainedLicenseResolver{
-				LicenseResolvers: []validation.LicenseResolver{
-					githubClient(logger, &cfg, tracer, meter, hc),
-					goproxyClient(logger, &cfg, tracer, meter, hc),
-				},
-			},
-			Meter: meter,
-		},
-	}, hc)
+	validator, denialMessages := stack.validator, stack.denialMessages
+
+	goproxyResolver, err := goproxyResolver(&cfg, logger)
 	if err != nil {
-		return nil, fmt.Errorf("setup cache failed: %w", err)
+		return nil, fmt.Errorf("goproxy hosts resolver setup failed: %w", err)
 	}
 
-	validator, err := validator(logger, &cfg, translator, c, tracer, meter)
+	hc.RegisterObserver("goproxy-hosts-resolver", goproxyResolver)
+
+	acl, err := setupACL(&cfg, logger, goproxyResolver)
 	if err != nil {
-		return nil, fmt.Errorf("validator init failed: %w", err)
+		return nil, fmt.Errorf("network acl setup failed: %w", err)
 	}
 
-	logger.Info("Trying to resolve goproxy addresses", zap.String("goproxy", string(cfg.GoProxy.BaseURL)))
-
-	goproxyAddrs, err := goproxyAddrs(&cfg)
+	clientIP, err := setupClientIP(&cfg)
 	if err != nil {
-		return nil, fmt.Errorf("get goproxy addrs failed: %w", err)
+		return nil, fmt.Errorf("trusted proxies setup failed: %w", err)
 	}
 
-	logger.Info("Found forbidden admission request sources", zap.Strings("sources", goproxyAddrs))
+	authMiddleware, err := setupAuth(&cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("auth setup failed: %w", err)
+	}
 
 	mux := http.NewServeMux()
 	observMiddleware := observ.Middleware(logger, pushController.Meter("http_requests"))
 	mux.Handle("/athens/admission",
 		othttp.NewHandler(
 			observMiddleware(
-				athens.AdmissionHandler(
-					&athens.InternalValidator{Validator: validator},
-					goproxyAddrs...,
+				acl.Middleware(
+					authMiddleware(
+						athens.AdmissionHandler(
+							&athens.InternalValidator{
+								Validator: validator,
+								HelpURL:   cfg.Validation.Denial.HelpURL,
+								Messages:  denialMessages,
+							},
+						),
+					),
 				),
 			),
 			"athens admission hook",
 			othttp.WithTracer(tracer),
 		),
 	)
+	mux.Handle("/athens/admission/list",
+		othttp.NewHandler(
+			observMiddleware(
+				acl.Middleware(
+					authMiddleware(
+						athens.ListAdmissionHandler(validator, cfg.Validation.ListConcurrency),
+					),
+				),
+			),
+			"athens list admission hook",
+			othttp.WithTracer(tracer),
+		),
+	)
+	mux.Handle("/api/v1/validate",
+		othttp.NewHandler(
+			observMiddleware(
+				acl.Middleware(
+					authMiddleware(
+						api.ValidateHandler(batchValidator(logger, &cfg, stack)),
+					),
+				),
+			),
+			"batch validation",
+			othttp.WithTracer(tracer),
+		),
+	)
+	mux.Handle("/api/v1/explain",
+		othttp.NewHandler(
+			observMiddleware(
+				acl.Middleware(
+					authMiddleware(
+						api.ExplainHandler(validation.NewExplainer(logger, validation.ExplainerParams{
+							Validator: validator,
+							HelpURL:   cfg.Validation.Denial.HelpURL,
+							Messages:  denialMessages,
+						})),
+					),
+				),
+			),
+			"validation explanation",
+			othttp.WithTracer(tracer),
+		),
+	)
+	mux.Handle("/api/v1/notices",
+		othttp.NewHandler(
+			observMiddleware(
+				acl.Middleware(
+					authMiddleware(
+						api.NoticesHandler(noticeCollector(logger, &cfg, stack)),
+					),
+				),
+			),
+			"attribution notices",
+			othttp.WithTracer(tracer),
+		),
+	)
+	mux.Handle("/api/v1/sbom",
+		othttp.NewHandler(
+			observMiddleware(
+				acl.Middleware(
+					authMiddleware(
+						api.SBOMHandler(sbom.NewGenerator(logger, sbom.GeneratorParams{
+							LicenseResolver: stack.licenseResolver,
+							Concurrency:     cfg.Validation.BatchConcurrency,
+						})),
+					),
+				),
+			),
+			"sbom generation",
+			othttp.WithTracer(tracer),
+		),
+	)
+	mux.Handle("/api/v1/validate/sbom",
+		othttp.NewHandler(
+			observMiddleware(
+				acl.Middleware(
+					authMiddleware(
+						api.ValidateSBOMHandler(sbomChecker(logger, &cfg, stack)),
+					),
+				),
+			),
+			"sbom validation",
+			othttp.WithTracer(tracer),
+		),
+	)
+	switch cfg.Server.Mode {
+	case "", ServerModeAthens:
+		// admission handler is always available
+	case ServerModeGoProxy:
+		handler, err := proxyHandler(logger, &cfg, validator, denialMessages, tracer, meter)
+		if err != nil {
+			return nil, fmt.Errorf("goproxy handler init failed: %w", err)
+		}
+
+		mux.Handle("/",
+			othttp.NewHandler(
+				observMiddleware(
+					acl.Middleware(
+						authMiddleware(
+							handler,
+						),
+					),
+				),
+				"goproxy",
+				othttp.WithTracer(tracer),
+			),
+		)
+	default:
+		return nil, fmt.Errorf("unknown server mode %s", cfg.Server.Mode)
+	}
+
 	mux.Handle("/loglevel", observMiddleware(loglevel))
 	mux.HandleFunc("/metrics", metricHandler)
 	addPprofHandlers(&cfg, mux)
 
-	return &App{
+	a := &App{
 		logger: logger,
 		server: &http.Server{
 			Addr:    cfg.Server.ListenAddr,
-			Handler: mux,
+			Handler: clientIP.Middleware(mux),
 			ErrorLog: func() *log.Logger {
 				l, _ := zap.NewStdLogAt(logger.With(zap.String("component", "http_server")), zap.ErrorLevel)
 				return l
@@ -143,22 +275,137 @@ func NewApp(cfg Config) (*App, error) {
 		},
 		healthServer: setupHealthServer(&cfg, logger, hc),
 		tracerFlush:  tracerFlush,
+		background:   []func(ctx context.Context){goproxyResolver.Watch},
+	}
+
+	a.server.TLSConfig, err = a.setupTLS(cfg.Server.TLS, logger, hc, "server")
+	if err != nil {
+		return nil, fmt.Errorf("server tls setup failed: %w", err)
+	}
+
+	if a.healthServer != nil {
+		a.healthServer.TLSConfig, err = a.setupTLS(cfg.HealthServer.TLS, logger, hc, "health-server")
+		if err != nil {
+			return nil, fmt.Errorf("health server tls setup failed: %w", err)
+		}
+	}
+
+	return a, nil
+}
+
+// validationStack contains components built from validation config
+type validationStack struct {
+	validator validation.Validator
+
+	// licenseResolver resolves license the same way as validator does it (with translation and cache)
+	licenseResolver validation.LicenseResolver
+
+	// batchResolver resolves licenses of many modules at once the same way as licenseResolver does it
+	batchResolver validation.BatchLicenseResolver
+
+	// declaredValidator builds validator applying the same rules as validator but taking licenses from provided resolver
+	declaredValidator func(resolver validation.LicenseResolver) validation.Validator
+
+	// filerOpeners provide module files in order of configured resolvers
+	filerOpeners []notice.FilerOpener
+
+	denialMessages validation.DenialMessages
+}
+
+// setupValidation builds module validator (translators, license resolvers, cache and rule set) and denial messages
+func setupValidation(
+	cfg *Config,
+	logger *zap.Logger,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	hc *health.Health,
+) (*validationStack, error) {
+	translator, err := translator(logger, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("translator init failed: %w", err)
+	}
+
+	proxyClient, err := goproxyClient(logger, cfg, tracer, meter)
+	if err != nil {
+		return nil, fmt.Errorf("goproxy client init failed: %w", err)
+	}
+
+	resolvers, filerOpeners, err := licenseResolvers(logger, cfg, tracer, meter, hc, proxyClient)
+	if err != nil {
+		return nil, fmt.Errorf("license resolvers init failed: %w", err)
+	}
+
+	c, err := setupCache(cfg, cache.Direct{
+		LicenseResolver: &observ.LicenseResolver{
+			LicenseResolver: &validation.ChainedLicenseResolver{
+				LicenseResolvers: resolvers,
+			},
+			Meter: meter,
+		},
+	}, hc)
+	if err != nil {
+		return nil, fmt.Errorf("setup cache failed: %w", err)
+	}
+
+	ruleSetValidator, err := ruleSetValidator(logger, cfg, translator, c)
+	if err != nil {
+		return nil, fmt.Errorf("validator init failed: %w", err)
+	}
+
+	validator, err := validator(logger, cfg, ruleSetValidator, tracer, meter)
+	if err != nil {
+		return nil, fmt.Errorf("validator init failed: %w", err)
+	}
+
+	denialMessages, err := denialMessages(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("denial messages init failed: %w", err)
+	}
+
+	return &validationStack{
+		validator:       validator,
+		licenseResolver: ruleSetValidator,
+		batchResolver:   ruleSetValidator,
+		declaredValidator: func(resolver validation.LicenseResolver) validation.Validator {
+			params := validator.NotifyingValidatorParams
+			params.Validator = validation.NewRuleSetValidator(logger, validation.RuleSetValidatorParams{
+				Translator:      &validation.ChainedTranslator{},
+				LicenseResolver: resolver,
+				RuleSet:         ruleSetValidator.RuleSet,
+			})
+
+			return validation.NewNotifyingValidator(logger, params)
+		},
+		filerOpeners:   filerOpeners,
+		denialMessages: denialMessages,
 	}, nil
 }
 
 func (a *App) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopBackground = cancel
+	for _, task := range a.background {
+		go task(ctx)
+	}
+
 	if a.healthServer != nil {
 		go func() {
-			a.logger.Info("Serving HTTP health check requests", zap.String("listen_addr", a.healthServer.Addr))
-			err := a.healthServer.ListenAndServe()
+			a.logger.Info("Serving HTTP health check requests",
+				zap.String("listen_addr", a.healthServer.Addr),
+				zap.Bool("tls", a.healthServer.TLSConfig != nil),
+			)
+			err := serve(a.healthServer)
 			if !errors.Is(err, http.ErrServerClosed) {
 				a.logger.Error("Failed to run HTTP health check server", zap.Error(err))
 			}
 		}()
 	}
 
-	a.logger.Info("Serving HTTP Requests", zap.String("listen_addr", a.server.Addr))
-	err := a.server.ListenAndServe()
+	a.logger.Info("Serving HTTP Requests",
+		zap.String("listen_addr", a.server.Addr),
+		zap.Bool("tls", a.server.TLSConfig != nil),
+	)
+	err := serve(a.server)
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
@@ -169,9 +416,81 @@ func (a *App) Run() error {
 func (a *App) Stop(ctx context.Context) error {
 	a.logger.Info("Stopping")
 	defer a.tracerFlush()
+
+	if a.stopBackground != nil {
+		a.stopBackground()
+	}
+
+	if a.healthServer != nil {
+		if err := a.healthServer.Shutdown(ctx); err != nil {
+			a.logger.Error("Health server shutdown failed", zap.Error(err))
+		}
+	}
+
 	return a.server.Shutdown(ctx)
 }
 
+func serve(server *http.Server) error {
+	if server.TLSConfig != nil {
+		// certificates provided by tls config
+		return server.ListenAndServeTLS("", "")
+	}
+
+	return server.ListenAndServe()
+}
+
+// setupTLS creates tls config with certificates reloading. It returns nil config if tls not configured.
+func (a *App) setupTLS(cfg *TLS, logger *zap.Logger, hc *health.Health, name string) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	minVersion, err := tlsVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader, err := tlsreload.NewReloader(logger.With(zap.String("server", name)), tlsreload.ReloaderParams{
+		CertFile:      cfg.CertFile,
+		KeyFile:       cfg.KeyFile,
+		ClientCAFile:  cfg.ClientCAFile,
+		CheckInterval: cfg.ReloadInterval,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("certificates load failed: %w", err)
+	}
+
+	hc.RegisterObserver(name+"-tls", reloader)
+	a.background = append(a.background, reloader.Watch)
+
+	base := &tls.Config{MinVersion: minVersion}
+	switch {
+	case cfg.ClientCAFile != "" && cfg.RequireClientCert:
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	case cfg.ClientCAFile != "":
+		base.ClientAuth = tls.VerifyClientCertIfGiven
+	case cfg.RequireClientCert:
+		return nil, fmt.Errorf("client CA file required to verify client certificates")
+	}
+
+	return reloader.TLSConfig(base), nil
+}
+
+func tlsVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown tls version %s", version)
+	}
+}
+
 func setupLogger(cfg *Config) (*zap.Logger, *zap.AtomicLevel, error) {
 	var logcfg zap.Config
 	if cfg.Debug {
@@ -258,35 +577,254 @@ func setupCache(cfg *Config, cacher cache.Cacher, hc *health.Health) (cache.Cach
 	}
 }
 
-func githubClient(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metric.Meter, hc *health.Health) *github.Client {
-	httpClient := &http.Client{}
+// licenseResolvers builds license resolvers in configured order and registers their health checkers.
+// Resolvers which have access to module files are also returned as filer openers in the same order.
+func licenseResolvers(
+	log *zap.Logger,
+	cfg *Config,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	hc *health.Health,
+	proxyClient *goproxy.Client,
+) ([]validation.LicenseResolver, []notice.FilerOpener, error) {
+	types := cfg.Resolvers
+	if len(types) == 0 {
+		types = []ResolverType{ResolverGithub, ResolverGoProxy}
+	}
+
+	var (
+		resolvers    []validation.LicenseResolver
+		filerOpeners []notice.FilerOpener
+		seen         = make(map[ResolverType]bool)
+	)
 
-	if cfg.Github.AccessToken != "" {
-		httpClient = oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{
-			AccessToken: string(cfg.Github.AccessToken),
-		}))
+	for _, typ := range types {
+		if seen[typ] {
+			return nil, nil, fmt.Errorf("resolver %s specified more than once", typ)
+		}
+
+		seen[typ] = true
+
+		switch typ {
+		case ResolverGithub:
+			clients, err := githubClients(log, cfg, tracer, meter)
+			if err != nil {
+				return nil, nil, fmt.Errorf("github client init failed: %w", err)
+			}
+
+			for _, client := range clients {
+				hc.RegisterChecker("github-client-"+client.Host, client)
+				hc.RegisterObserver("github-rate-limit-"+client.Host, health.CheckerFunc(client.CheckRateLimit))
+				resolvers = append(resolvers, client)
+			}
+		case ResolverGoProxy:
+			hc.RegisterChecker("goproxy-client", proxyClient)
+			resolvers = append(resolvers, proxyClient)
+			filerOpeners = append(filerOpeners, goproxyFilerOpener(proxyClient))
+		case ResolverModCache:
+			client := modcache.NewClient(log, modcache.ClientParams{
+				Dir:                 cfg.ModCache.Dir,
+				ConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
+			})
+			hc.RegisterChecker("modcache", client)
+			resolvers = append(resolvers, client)
+			filerOpeners = append(filerOpeners, notice.FilerOpenerFunc(func(_ context.Context, m validation.Module) (filer.Filer, error) {
+				return client.Filer(m)
+			}))
+		case ResolverGit:
+			client, err := gitClient(log, cfg, tracer, meter)
+			if err != nil {
+				return nil, nil, fmt.Errorf("git client init failed: %w", err)
+			}
+
+			hc.RegisterChecker("git-client", client)
+			resolvers = append(resolvers, client)
+			filerOpeners = append(filerOpeners, notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+				tf, err := client.Filer(ctx, m)
+				if err != nil {
+					return nil, err
+				}
+
+				return tf, nil
+			}))
+		case ResolverGitLab:
+			clients, err := gitlabClients(log, cfg, tracer, meter)
+			if err != nil {
+				return nil, nil, fmt.Errorf("gitlab client init failed: %w", err)
+			}
+
+			for _, client := range clients {
+				hc.RegisterChecker("gitlab-client-"+client.Hosts[0], client)
+				resolvers = append(resolvers, client)
+				filerOpeners = append(filerOpeners, gitlabFilerOpener(client))
+			}
+		case ResolverBitbucket:
+			clients, err := bitbucketClients(log, cfg, tracer, meter)
+			if err != nil {
+				return nil, nil, fmt.Errorf("bitbucket client init failed: %w", err)
+			}
+
+			for _, client := range clients {
+				hc.RegisterChecker("bitbucket-client-"+client.Hosts[0], client)
+				resolvers = append(resolvers, client)
+				filerOpeners = append(filerOpeners, bitbucketFilerOpener(client))
+			}
+		case ResolverGitea:
+			clients, err := giteaClients(log, cfg, tracer, meter)
+			if err != nil {
+				return nil, nil, fmt.Errorf("gitea client init failed: %w", err)
+			}
+
+			for _, client := range clients {
+				hc.RegisterChecker("gitea-client-"+client.Hosts[0], client)
+				resolvers = append(resolvers, client)
+				filerOpeners = append(filerOpeners, giteaFilerOpener(client))
+			}
+		default:
+			return nil, nil, fmt.Errorf("unknown resolver %s", typ)
+		}
 	}
 
-	httpClient.Transport = &observ.TraceTransport{
-		RoundTripper: httpClient.Transport,
-		ServiceName:  "github",
-		Tracer:       tracer,
-		Meter:        meter,
+	// goproxy is used to get module files when no configured resolver can provide them
+	if len(filerOpeners) == 0 {
+		filerOpeners = append(filerOpeners, goproxyFilerOpener(proxyClient))
 	}
 
-	client := github.NewClient(log, github.ClientParams{
-		Client:                      gh.NewClient(httpClient),
+	return resolvers, filerOpeners, nil
+}
+
+func githubClients(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metric.Meter) ([]*github.Client, error) {
+	instances := cfg.Github
+	if len(instances) == 0 {
+		instances = []Github{{}}
+	}
+
+	clients := make([]*github.Client, 0, len(instances))
+	for _, instance := range instances {
+		client, err := githubClient(log, cfg, instance, tracer, meter)
+		if err != nil {
+			return nil, fmt.Errorf("github instance %q: %w", instance.Host, err)
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, nil
+}
+
+func githubClient(log *zap.Logger, cfg *Config, instance Github, tracer trace.Tracer, meter metric.Meter) (*github.Client, error) {
+	tokens, err := githubPoolTokens(instance, tracer, meter)
+	if err != nil {
+		return nil, err
+	}
+
+	var transport http.RoundTripper
+	if len(tokens) > 0 {
+		pool, err := github.NewTokenPool(tokens, meter)
+		if err != nil {
+			return nil, fmt.Errorf("token pool init failed: %w", err)
+		}
+
+		transport = pool.Transport(nil)
+	}
+
+	httpClient := &http.Client{
+		Transport: &observ.TraceTransport{
+			RoundTripper: transport,
+			ServiceName:  "github",
+			Tracer:       tracer,
+			Meter:        meter,
+		},
+	}
+
+	ghClient := gh.NewClient(httpClient)
+	if instance.BaseURL != "" {
+		uploadURL := instance.UploadURL
+		if uploadURL == "" {
+			uploadURL = instance.BaseURL
+		}
+
+		ghClient, err = gh.NewEnterpriseClient(string(instance.BaseURL), string(uploadURL), httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("enterprise client init failed: %w", err)
+		}
+	}
+
+	return github.NewClient(log, github.ClientParams{
+		Client:                      ghClient,
+		Host:                        instance.Host,
 		FallbackConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
-	})
-	hc.RegisterChecker("github-client", client)
-	return client
+		DefaultBranchFallback:       instance.DefaultBranchFallback,
+		MaxRateLimitWait:            instance.MaxRateLimitWait,
+		GraphQL:                     instance.GraphQL,
+	}), nil
+}
+
+// githubPoolTokens returns tokens of instance. Token names are prefixed with instance host.
+func githubPoolTokens(instance Github, tracer trace.Tracer, meter metric.Meter) ([]github.PoolToken, error) {
+	host := instance.Host
+	if host == "" {
+		host = github.DefaultHost
+	}
+
+	var accessTokens []string
+	if instance.AccessToken != "" {
+		accessTokens = append(accessTokens, string(instance.AccessToken))
+	}
+
+	for _, token := range instance.AccessTokens {
+		accessTokens = append(accessTokens, string(token))
+	}
+
+	tokens := github.StaticPoolTokens(accessTokens...)
+
+	for _, app := range instance.Apps {
+		keyPEM := []byte(app.PrivateKey)
+		if app.PrivateKeyFile != "" {
+			var err error
+			keyPEM, err = ioutil.ReadFile(app.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("app %d private key read failed: %w", app.AppID, err)
+			}
+		}
+
+		key, err := github.ParsePrivateKey(keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("app %d: %w", app.AppID, err)
+		}
+
+		baseURL := string(instance.BaseURL)
+		if baseURL == "" {
+			baseURL = github.DefaultBaseURL
+		}
+
+		tokens = append(tokens, github.AppPoolToken(app.AppID, app.InstallationID, github.NewAppTokenSource(github.AppTokenSourceParams{
+			HTTPClient: &http.Client{
+				Transport: &observ.TraceTransport{
+					ServiceName: "github",
+					Tracer:      tracer,
+					Meter:       meter,
+				},
+			},
+			BaseURL:        baseURL,
+			AppID:          app.AppID,
+			InstallationID: app.InstallationID,
+			PrivateKey:     key,
+		})))
+	}
+
+	for i := range tokens {
+		tokens[i].Name = host + "/" + tokens[i].Name
+	}
+
+	return tokens, nil
 }
 
-func goproxyClient(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metric.Meter, hc *health.Health) *goproxy.Client {
+func goproxyClient(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metric.Meter) (*goproxy.Client, error) {
 	if cfg.GoProxy.BaseURL == "" {
 		cfg.GoProxy.BaseURL = "https://proxy.golang.org"
 	}
-	client := goproxy.NewClient(log, goproxy.ClientParams{
+	return goproxy.NewClient(log, goproxy.ClientParams{
 		HTTPClient: &http.Client{
 			Transport: &observ.TraceTransport{
 				ServiceName: "goproxy",
@@ -297,29 +835,329 @@ func goproxyClient(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metr
 		BaseURL:             string(cfg.GoProxy.BaseURL),
 		ConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
 	})
-	hc.RegisterChecker("goproxy-client", client)
-	return client
 }
 
-func goproxyAddrs(cfg *Config) ([]string, error) {
-	u, err := url.Parse(string(cfg.GoProxy.BaseURL))
-	if err != nil {
-		return nil, fmt.Errorf("goproxy url parse failed: %w", err)
+func gitClient(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metric.Meter) (*git.Client, error) {
+	repos := make([]git.RepoRule, 0, len(cfg.Git.Repos))
+	for _, repo := range cfg.Git.Repos {
+		match, err := regexp.Compile(repo.Match)
+		if err != nil {
+			return nil, fmt.Errorf("repo match %q compile failed: %w", repo.Match, err)
+		}
+
+		repos = append(repos, git.RepoRule{Match: match, URL: string(repo.URL)})
+	}
+
+	return git.NewClient(log, git.ClientParams{
+		HTTPClient: &http.Client{
+			Transport: &observ.TraceTransport{
+				ServiceName: "go-get",
+				Tracer:      tracer,
+				Meter:       meter,
+			},
+		},
+		Repos:               repos,
+		DiscoveryHosts:      cfg.Git.DiscoveryHosts,
+		TempDir:             cfg.Git.TempDir,
+		MaxSize:             cfg.Git.MaxSize,
+		Timeout:             cfg.Git.Timeout,
+		ConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
+	}), nil
+}
+
+func gitlabClients(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metric.Meter) ([]*gitlab.Client, error) {
+	if len(cfg.GitLab) == 0 {
+		return nil, fmt.Errorf("no GitLab instances configured")
+	}
+
+	clients := make([]*gitlab.Client, 0, len(cfg.GitLab))
+	for _, instance := range cfg.GitLab {
+		client, err := gitlab.NewClient(log, gitlab.ClientParams{
+			HTTPClient: &http.Client{
+				Transport: &observ.TraceTransport{
+					ServiceName: "gitlab",
+					Tracer:      tracer,
+					Meter:       meter,
+				},
+			},
+			BaseURL:             string(instance.BaseURL),
+			Hosts:               instance.Hosts,
+			Token:               string(instance.Token),
+			ConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, client)
 	}
 
-	ips, err := (&net.Resolver{
-		PreferGo: true,
-	}).LookupIPAddr(context.Background(), u.Hostname())
+	return clients, nil
+}
+
+func gitlabFilerOpener(client *gitlab.Client) notice.FilerOpener {
+	return notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+		af, err := client.Filer(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+
+		return af, nil
+	})
+}
+
+func bitbucketClients(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metric.Meter) ([]*bitbucket.Client, error) {
+	if len(cfg.Bitbucket) == 0 {
+		return nil, fmt.Errorf("no Bitbucket instances configured")
+	}
+
+	clients := make([]*bitbucket.Client, 0, len(cfg.Bitbucket))
+	for _, instance := range cfg.Bitbucket {
+		client, err := bitbucket.NewClient(log, bitbucket.ClientParams{
+			HTTPClient: &http.Client{
+				Transport: &observ.TraceTransport{
+					ServiceName: "bitbucket",
+					Tracer:      tracer,
+					Meter:       meter,
+				},
+			},
+			BaseURL:             string(instance.BaseURL),
+			Hosts:               instance.Hosts,
+			Token:               string(instance.Token),
+			Username:            instance.Username,
+			Password:            string(instance.Password),
+			ConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, nil
+}
+
+func bitbucketFilerOpener(client *bitbucket.Client) notice.FilerOpener {
+	return notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+		af, err := client.Filer(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+
+		return af, nil
+	})
+}
+
+func giteaClients(log *zap.Logger, cfg *Config, tracer trace.Tracer, meter metric.Meter) ([]*gitea.Client, error) {
+	if len(cfg.Gitea) == 0 {
+		return nil, fmt.Errorf("no Gitea instances configured")
+	}
+
+	clients := make([]*gitea.Client, 0, len(cfg.Gitea))
+	for _, instance := range cfg.Gitea {
+		client, err := gitea.NewClient(log, gitea.ClientParams{
+			HTTPClient: &http.Client{
+				Transport: &observ.TraceTransport{
+					ServiceName: "gitea",
+					Tracer:      tracer,
+					Meter:       meter,
+				},
+			},
+			BaseURL:             string(instance.BaseURL),
+			Hosts:               instance.Hosts,
+			Token:               string(instance.Token),
+			Username:            instance.Username,
+			Password:            string(instance.Password),
+			ConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, nil
+}
+
+func giteaFilerOpener(client *gitea.Client) notice.FilerOpener {
+	return notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+		af, err := client.Filer(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+
+		return af, nil
+	})
+}
+
+func goproxyFilerOpener(client *goproxy.Client) notice.FilerOpener {
+	return notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+		zf, err := client.ZipFiler(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+
+		return zf, nil
+	})
+}
+
+func noticeCollector(log *zap.Logger, cfg *Config, stack *validationStack) *notice.Collector {
+	return notice.NewCollector(log, notice.CollectorParams{
+		// module files are taken from the first source which has module
+		Opener: notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+			var err error
+			for _, opener := range stack.filerOpeners {
+				var fs filer.Filer
+				fs, err = opener.OpenFiler(ctx, m)
+				if !moduleNotFound(err) {
+					return fs, err
+				}
+			}
+
+			return nil, err
+		}),
+		LicenseResolver: stack.licenseResolver,
+		Concurrency:     cfg.Validation.NoticeConcurrency,
+	})
+}
+
+// moduleNotFound reports if error means that module files source doesn't have module
+func moduleNotFound(err error) bool {
+	for _, target := range []error{
+		goproxy.ErrModuleNotFound,
+		goproxy.ErrProxyOff,
+		modcache.ErrModuleNotFound,
+		git.ErrRepoNotFound,
+		git.ErrModuleNotFound,
+		git.ErrTooLarge,
+		gitlab.ErrProjectNotFound,
+		gitlab.ErrModuleNotFound,
+		bitbucket.ErrRepoNotFound,
+		bitbucket.ErrModuleNotFound,
+		gitea.ErrRepoNotFound,
+		gitea.ErrModuleNotFound,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// batchValidator builds validator of module sets which resolves licenses of all modules at once before validation
+func batchValidator(log *zap.Logger, cfg *Config, stack *validationStack) *batch.Validator {
+	return batch.NewValidator(log, batch.ValidatorParams{
+		Validator:         stack.validator,
+		Resolver:          stack.batchResolver,
+		ResolvedValidator: stack.declaredValidator,
+		Concurrency:       cfg.Validation.BatchConcurrency,
+		HelpURL:           cfg.Validation.Denial.HelpURL,
+		Messages:          stack.denialMessages,
+	})
+}
+
+func sbomChecker(log *zap.Logger, cfg *Config, stack *validationStack) *sbom.Checker {
+	return sbom.NewChecker(log, sbom.CheckerParams{
+		Validator:         stack.validator,
+		DeclaredValidator: stack.declaredValidator,
+		Concurrency:       cfg.Validation.BatchConcurrency,
+		HelpURL:           cfg.Validation.Denial.HelpURL,
+		Messages:          stack.denialMessages,
+	})
+}
+
+func proxyHandler(
+	log *zap.Logger,
+	cfg *Config,
+	validator validation.Validator,
+	messages validation.DenialMessages,
+	tracer trace.Tracer,
+	meter metric.Meter,
+) (*proxy.Handler, error) {
+	return proxy.NewHandler(log, proxy.HandlerParams{
+		Client: &http.Client{
+			Transport: &observ.TraceTransport{
+				ServiceName: "goproxy_upstream",
+				Tracer:      tracer,
+				Meter:       meter,
+			},
+		},
+		UpstreamURL:       string(cfg.GoProxy.BaseURL),
+		Validator:         validator,
+		HelpURL:           cfg.Validation.Denial.HelpURL,
+		Messages:          messages,
+		DenyStatus:        cfg.Proxy.DenyStatus,
+		FilterConcurrency: cfg.Validation.ListConcurrency,
+	})
+}
+
+func goproxyResolver(cfg *Config, logger *zap.Logger) (*netacl.HostResolver, error) {
+	proxies, err := goproxy.ParseProxyList(string(cfg.GoProxy.BaseURL))
 	if err != nil {
-		return nil, fmt.Errorf("goproxy addresses lookup failed: %w", err)
+		return nil, fmt.Errorf("goproxy list parse failed: %w", err)
 	}
 
-	var addrs []string
-	for _, v := range ips {
-		addrs = append(addrs, v.String())
+	var hosts []string
+	for _, item := range proxies {
+		if item.Off {
+			continue
+		}
+
+		u, err := url.Parse(item.URL)
+		if err != nil {
+			return nil, fmt.Errorf("goproxy url parse failed: %w", err)
+		}
+
+		hosts = append(hosts, u.Host)
 	}
 
-	return append(addrs, u.Hostname(), u.Host), nil
+	resolver := netacl.NewHostResolver(logger, netacl.HostResolverParams{
+		Hosts:    hosts,
+		Interval: cfg.GoProxy.ResolveInterval,
+	})
+
+	logger.Info("Trying to resolve goproxy addresses", zap.Strings("goproxy", hosts))
+
+	if err := resolver.Refresh(context.Background()); err != nil {
+		// not fatal, addresses will be re-resolved later
+		logger.Warn("Goproxy addresses resolution failed", zap.Error(err))
+	}
+
+	logger.Info("Found forbidden admission request sources", zap.Strings("sources", resolver.Addrs()))
+
+	return resolver, nil
+}
+
+func setupACL(cfg *Config, logger *zap.Logger, goproxyResolver netacl.Source) (*netacl.ACL, error) {
+	params := netacl.ACLParams{Forbidden: goproxyResolver}
+
+	if cfg.Server.Access != nil {
+		var err error
+
+		params.Allowed, err = netacl.ParseCIDRs(cfg.Server.Access.AllowedCIDRs)
+		if err != nil {
+			return nil, fmt.Errorf("allowed networks parse failed: %w", err)
+		}
+
+		params.Denied, err = netacl.ParseCIDRs(cfg.Server.Access.DeniedCIDRs)
+		if err != nil {
+			return nil, fmt.Errorf("denied networks parse failed: %w", err)
+		}
+	}
+
+	return netacl.NewACL(logger, params), nil
+}
+
+func setupClientIP(cfg *Config) (*netacl.ClientIP, error) {
+	trusted, err := netacl.ParseCIDRs(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	return &netacl.ClientIP{TrustedProxies: trusted}, nil
 }
 
 func translator(log *zap.Logger, cfg *Config) (*validation.ChainedTranslator, error) {
@@ -346,35 +1184,12 @@ func translator(log *zap.Logger, cfg *Config) (*validation.ChainedTranslator, er
 	}, nil
 }
 
-func validator(
+func ruleSetValidator(
 	log *zap.Logger,
 	cfg *Config,
 	translator validation.Translator,
 	resolver validation.LicenseResolver,
-	tracer trace.Tracer,
-	meter metric.Meter,
-) (*validation.NotifyingValidator, error) {
-	var (
-		unknownLicenseAction   validation.UnknownLicenseAction
-		unknownLicenseNotifier validation.UnknownLicenseNotifier
-	)
-
-	switch cfg.Validation.UnknownLicenseAction {
-	case UnknownLicenseAllow:
-		unknownLicenseAction = validation.UnknownLicenseAllow
-	case UnknownLicenseWarn:
-		var err error
-		unknownLicenseAction = validation.UnknownLicenseWarn
-		unknownLicenseNotifier, err = setupUnknownLicenseNotifier(log, cfg, tracer, meter)
-		if err != nil {
-			return nil, fmt.Errorf("setup unknown license notifier failed: %w", err)
-		}
-	case UnknownLicenseDeny:
-		unknownLicenseAction = validation.UnknownLicenseDeny
-	default:
-		return nil, fmt.Errorf("unexpected unknown license action %s", cfg.Validation.UnknownLicenseAction)
-	}
-
+) (*validation.RuleSetValidator, error) {
 	var (
 		ruleSet validation.RuleSet
 		err     error
@@ -400,18 +1215,86 @@ func validator(
 		return nil, fmt.Errorf("denied licenses parse failed: %w", err)
 	}
 
+	return validation.NewRuleSetValidator(log, validation.RuleSetValidatorParams{
+		Translator:      translator,
+		LicenseResolver: resolver,
+		RuleSet:         ruleSet,
+	}), nil
+}
+
+func validator(
+	log *zap.Logger,
+	cfg *Config,
+	ruleSetValidator *validation.RuleSetValidator,
+	tracer trace.Tracer,
+	meter metric.Meter,
+) (*validation.NotifyingValidator, error) {
+	var (
+		unknownLicenseAction   validation.UnknownLicenseAction
+		unknownLicenseNotifier validation.UnknownLicenseNotifier
+	)
+
+	switch cfg.Validation.UnknownLicenseAction {
+	case UnknownLicenseAllow:
+		unknownLicenseAction = validation.UnknownLicenseAllow
+	case UnknownLicenseWarn:
+		var err error
+		unknownLicenseAction = validation.UnknownLicenseWarn
+		unknownLicenseNotifier, err = setupUnknownLicenseNotifier(log, cfg, tracer, meter)
+		if err != nil {
+			return nil, fmt.Errorf("setup unknown license notifier failed: %w", err)
+		}
+	case UnknownLicenseDeny:
+		unknownLicenseAction = validation.UnknownLicenseDeny
+	default:
+		return nil, fmt.Errorf("unexpected unknown license action %s", cfg.Validation.UnknownLicenseAction)
+	}
+
 	return validation.NewNotifyingValidator(
 		log, validation.NotifyingValidatorParams{
-			Validator: validation.NewRuleSetValidator(log, validation.RuleSetValidatorParams{
-				Translator:      translator,
-				LicenseResolver: resolver,
-				RuleSet:         ruleSet,
-			}),
+			Validator:              ruleSetValidator,
 			UnknownLicenseAction:   unknownLicenseAction,
 			UnknownLicenseNotifier: unknownLicenseNotifier,
 		}), nil
 }
 
+func denialMessages(cfg *Config) (validation.DenialMessages, error) {
+	messages := make(validation.DenialMessages)
+
+	for reason, text := range map[validation.DenialReason]string{
+		validation.DenialBlacklisted:    cfg.Validation.Denial.BlacklistedTemplate,
+		validation.DenialDeniedLicense:  cfg.Validation.Denial.DeniedLicenseTemplate,
+		validation.DenialUnknownLicense: cfg.Validation.Denial.UnknownLicenseTemplate,
+	} {
+		if text == "" {
+			continue
+		}
+
+		tpl, err := texttemplate.New(string(reason)).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s template parse failed: %w", reason, err)
+		}
+
+		messages[reason] = tpl
+
+		// execute template with sample to catch errors (i.e. unknown fields) on startup
+		sample := validation.Denial{
+			Module:  "example.com/module",
+			Version: "v1.0.0",
+			License: "MIT",
+			Rule:    "allowed_licenses",
+			Reason:  reason,
+			Message: "sample message",
+			HelpURL: cfg.Validation.Denial.HelpURL,
+		}
+		if err := messages.Render(&sample); err != nil {
+			return nil, fmt.Errorf("%s template check failed: %w", reason, err)
+		}
+	}
+
+	return messages, nil
+}
+
 func parseModuleMatchers(ms []ModuleMatcher) ([]validation.ModuleMatcher, error) {
 	ret := make([]validation.ModuleMatcher, 0, len(ms))
 	for _, item := range ms {
@@ -463,6 +1346,45 @@ func parseLicenses(ls []License) ([]validation.License, error) {
 	return ret, nil
 }
 
+func setupAuth(cfg *Config, logger *zap.Logger) (func(next http.Handler) http.Handler, error) {
+	if cfg.Server.Auth == nil {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	var authenticators auth.AnyOf
+
+	if len(cfg.Server.Auth.SharedSecrets) > 0 {
+		secrets := make([]string, 0, len(cfg.Server.Auth.SharedSecrets))
+		for _, secret := range cfg.Server.Auth.SharedSecrets {
+			secrets = append(secrets, string(secret))
+		}
+
+		authenticators = append(authenticators, &auth.SharedSecret{
+			Header:  cfg.Server.Auth.SharedSecretHeader,
+			Secrets: secrets,
+		})
+	}
+
+	if len(cfg.Server.Auth.BasicUsers) > 0 {
+		users := make(map[string]string, len(cfg.Server.Auth.BasicUsers))
+		for user, password := range cfg.Server.Auth.BasicUsers {
+			users[user] = string(password)
+		}
+
+		authenticators = append(authenticators, &auth.Basic{Users: users})
+	}
+
+	if len(cfg.Server.Auth.ClientCertSubjects) > 0 {
+		authenticators = append(authenticators, &auth.ClientCert{Subjects: cfg.Server.Auth.ClientCertSubjects})
+	}
+
+	if len(authenticators) == 0 {
+		return nil, fmt.Errorf("auth section present but no authentication methods configured")
+	}
+
+	return auth.Middleware(logger, authenticators), nil
+}
+
 func addPprofHandlers(cfg *Config, mux *http.ServeMux) {
 	if cfg.Server.EnablePprof {
 		mux.HandleFunc("/pprof/", pprof.Index)
@@ -1,9 +1,11 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/pelletier/go-toml"
 )
@@ -31,6 +33,34 @@ func (m MaskedURL) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf(`"%s"`, u.String())), nil
 }
 
+// MaskedProxyList is a GOPROXY-like list of urls with masked passwords
+type MaskedProxyList string
+
+func (m MaskedProxyList) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+
+	for rest := string(m); rest != ""; {
+		item, sep := rest, ""
+		if i := strings.IndexAny(rest, ",|"); i >= 0 {
+			item, sep, rest = rest[:i], rest[i:i+1], rest[i+1:]
+		} else {
+			rest = ""
+		}
+
+		if u, err := url.Parse(item); err == nil {
+			if _, hasPass := u.User.Password(); hasPass {
+				u.User = url.UserPassword(u.User.Username(), "****")
+				item = u.String()
+			}
+		}
+
+		b.WriteString(item)
+		b.WriteString(sep)
+	}
+
+	return json.Marshal(b.String())
+}
+
 func ConfigFromFile(cfgFilePath string) (Config, error) {
 	var cfg Config
 
@@ -41,9 +71,34 @@ func ConfigFromFile(cfgFilePath string) (Config, error) {
 
 	defer cfgFile.Close()
 
-	if err := toml.NewDecoder(cfgFile).Decode(&cfg); err != nil {
+	tree, err := toml.LoadReader(cfgFile)
+	if err != nil {
+		return cfg, fmt.Errorf("config parsee failed: %w", err)
+	}
+
+	upgradeLegacyGithub(tree)
+
+	if err := tree.Unmarshal(&cfg); err != nil {
 		return cfg, fmt.Errorf("config parsee failed: %w", err)
 	}
 
 	return cfg, nil
 }
+
+// upgradeLegacyGithub converts single [Github] table used before multiple instances support
+// to array of tables with one instance. Position of converted table returned if conversion happened.
+func upgradeLegacyGithub(tree *toml.Tree) (toml.Position, bool) {
+	key, ok := lookupKey(tree, "Github")
+	if !ok {
+		return toml.Position{}, false
+	}
+
+	single, ok := tree.GetPath([]string{key}).(*toml.Tree)
+	if !ok {
+		return toml.Position{}, false
+	}
+
+	tree.SetPath([]string{key}, []*toml.Tree{single})
+
+	return single.Position(), true
+}
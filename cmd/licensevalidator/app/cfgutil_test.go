@@ -0,0 +1,40 @@
+package app_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/cmd/licensevalidator/app"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFromFile_legacyGithub(t *testing.T) {
+	t.Parallel()
+
+	f, err := ioutil.TempFile("", "licensevalidator-cfg")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.Remove(f.Name()) })
+
+	// single github instance config used before multiple instances support
+	_, err = f.WriteString(`
+[Github]
+  # Provide github access token to decrease rate-limit
+  AccessToken = "test-github-token"
+
+[GoProxy]
+  BaseURL = "https://proxy.golang.org"
+`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	cfg, err := app.ConfigFromFile(f.Name())
+	require.NoError(t, err)
+
+	if assert.Len(t, cfg.Github, 1) {
+		assert.Equal(t, "test-github-token", string(cfg.Github[0].AccessToken))
+	}
+}
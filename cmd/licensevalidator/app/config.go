@@ -25,6 +25,25 @@ const (
 	JaegerTracer TracerType = "jaeger"
 )
 
+type ServerMode string
+
+const (
+	ServerModeAthens  ServerMode = "athens"
+	ServerModeGoProxy ServerMode = "goproxy"
+)
+
+type ResolverType string
+
+const (
+	ResolverGithub    ResolverType = "github"
+	ResolverGoProxy   ResolverType = "goproxy"
+	ResolverModCache  ResolverType = "modcache"
+	ResolverGit       ResolverType = "git"
+	ResolverGitLab    ResolverType = "gitlab"
+	ResolverBitbucket ResolverType = "bitbucket"
+	ResolverGitea     ResolverType = "gitea"
+)
+
 type NotificationType string
 
 const (
@@ -36,14 +55,40 @@ type Config struct {
 	// Debug is a flag to enable debug logging
 	Debug bool
 
+	// Resolvers defines license resolvers and order in which they are tried. Default is ["github", "goproxy"].
+	// Available resolvers:
+	// * github - uses github API (see Github sections)
+	// * goproxy - detects license from module zip downloaded from goproxy (see GoProxy section)
+	// * modcache - detects license from local go modules cache (see ModCache section)
+	// * git - detects license from module version fetched from any git repository (see Git section)
+	// * gitlab - detects license from project files read through GitLab API (see GitLab section)
+	// * bitbucket - detects license from repository files read through Bitbucket Server API (see Bitbucket section)
+	// * gitea - detects license from repository files read through Gitea API (see Gitea section)
+	Resolvers []ResolverType `toml:",omitempty"`
+
 	// Cache is optional cache configuration.
 	// Cache will not be used if not present (not recommended).
 	Cache *Cache
 
-	Github Github
+	// Github contains GitHub instances (github.com and GitHub Enterprise Servers) used by github resolver.
+	// Default is github.com without access token. Single [Github] table of older configs is accepted as the only instance.
+	Github []Github `toml:",omitempty"`
 
 	GoProxy GoProxy
 
+	ModCache ModCache
+
+	Git Git
+
+	// GitLab contains GitLab instances (gitlab.com or self-hosted) used by gitlab resolver
+	GitLab []GitLab `toml:",omitempty"`
+
+	// Bitbucket contains Bitbucket Server instances used by bitbucket resolver
+	Bitbucket []APIInstance `toml:",omitempty"`
+
+	// Gitea contains Gitea instances used by gitea resolver
+	Gitea []APIInstance `toml:",omitempty"`
+
 	// PathOverrides contains set of rules for translation module names
 	PathOverrides []OverridePath
 
@@ -51,6 +96,9 @@ type Config struct {
 
 	Server Server
 
+	// Proxy contains settings for goproxy server mode
+	Proxy Proxy
+
 	// HealthServer is a server for liveness and readiness probe handlers.
 	// These handlers not added to main server because on graceful shutdown it puts app into false unhealthy status.
 	// Server will not be started if section not provided.
@@ -106,16 +154,138 @@ type Redis struct {
 
 // Github contains github client configuration
 type Github struct {
+	// Host is a glob pattern (path.Match syntax) of module path host served by instance. Default is "github.com".
+	Host string `toml:",omitempty"`
+
+	// BaseURL is an API url. Default is https://api.github.com/.
+	// GitHub Enterprise Server API url looks like https://ghe.corp.example/api/v3/.
+	BaseURL MaskedURL `toml:",omitempty"`
+
+	// UploadURL is an uploads API url. Default is BaseURL.
+	// GitHub Enterprise Server uploads url looks like https://ghe.corp.example/api/uploads/.
+	UploadURL MaskedURL `toml:",omitempty"`
+
 	// AccessToken is optional github access token
 	// It's needed to access private repos or increase rate-limit
 	AccessToken MaskedString
+
+	// AccessTokens are additional access tokens.
+	// All tokens (including AccessToken and Apps tokens) form a pool,
+	// each request is made with token having most remaining rate-limit quota.
+	AccessTokens []MaskedString `toml:",omitempty"`
+
+	// Apps contains GitHub App installations used for authentication.
+	// Installation access tokens are requested and refreshed automatically.
+	Apps []GithubApp `toml:",omitempty"`
+
+	// DefaultBranchFallback enables usage of repository default branch license
+	// when module version tag or commit not found in repository.
+	// By default license is detected only from files at module version.
+	DefaultBranchFallback bool `toml:",omitempty"`
+
+	// MaxRateLimitWait is a maximal duration of waiting for rate-limit reset.
+	// If reset is later module is skipped and next resolver is used.
+	// Default is 0 (wait for reset without limit), negative value disables waiting.
+	// Rate-limited state is reported by readiness endpoint without failing it.
+	MaxRateLimitWait time.Duration `toml:",omitempty"`
+
+	// GraphQL enables fetching of repository files with GraphQL API: files of many repositories are fetched
+	// with single query which consumes less rate-limit. Requires AccessToken, AccessTokens or Apps.
+	GraphQL bool `toml:",omitempty"`
+}
+
+// GithubApp contains GitHub App installation credentials
+type GithubApp struct {
+	// AppID is an identifier of GitHub App
+	AppID int64
+
+	// InstallationID is an identifier of App installation to organization or user
+	InstallationID int64
+
+	// PrivateKeyFile is a path to App private key in PEM format
+	PrivateKeyFile string `toml:",omitempty"`
+
+	// PrivateKey is an App private key in PEM format. PrivateKeyFile has precedence.
+	PrivateKey MaskedString `toml:",omitempty"`
 }
 
 // GoProxy contains goproxy client configuration
 type GoProxy struct {
-	// BaseURL is a goproxy basic url
+	// BaseURL is a goproxy basic url or list of proxies with GOPROXY environment variable syntax:
+	// proxies separated with "," are tried in order if module not found, separated with "|" are tried on any error,
+	// "off" disables further lookup (i.e. "https://goproxy.corp.example|https://proxy.golang.org").
+	// "direct" entries are ignored with a warning, list containing only "direct" is rejected.
 	// Obviously it must not be athens url which will use this app
+	BaseURL MaskedProxyList
+
+	// ResolveInterval is a period of goproxy host addresses re-resolution. Default is 1 minute.
+	// Admission requests from these addresses are rejected because it means misconfiguration.
+	ResolveInterval time.Duration `toml:",omitempty"`
+}
+
+// ModCache contains local go modules cache resolver configuration
+type ModCache struct {
+	// Dir is a modules cache directory (GOMODCACHE). Default is taken from environment like go command does it.
+	// Both extracted modules and downloaded zips (cache/download) are used.
+	Dir string `toml:",omitempty"`
+}
+
+// Git contains generic git resolver configuration.
+// Repository is found by rules or with go-get discovery for allowed hosts, module version tag or commit is fetched with git command.
+type Git struct {
+	// Repos contains rules to find repository without go-get discovery. Rules are checked in order.
+	Repos []GitRepo `toml:",omitempty"`
+
+	// DiscoveryHosts contains module path host glob patterns (path.Match syntax) for which go-get discovery is performed.
+	// Discovery is disabled if empty. Discovered repositories are fetched only over https, ssh or git protocol.
+	DiscoveryHosts []string `toml:",omitempty"`
+
+	// MaxSize limits fetched repository data size in bytes. Default is 100MiB.
+	MaxSize int64 `toml:",omitempty"`
+
+	// Timeout limits repository fetch duration. Default is 1 minute.
+	Timeout time.Duration `toml:",omitempty"`
+
+	// TempDir is a directory for fetched repositories. Default is system temporary directory.
+	TempDir string `toml:",omitempty"`
+}
+
+// GitRepo maps module paths to repository url
+type GitRepo struct {
+	// Match is a regular expression matched against beginning of module path, matched part is a repository root
+	Match string
+
+	// URL is a repository url. Regexp capturing group placeholders (i.e $1, $2) may be used here.
+	URL MaskedURL
+}
+
+// GitLab contains GitLab instance configuration
+type GitLab struct {
+	// BaseURL is an instance url (i.e. https://gitlab.com)
+	BaseURL MaskedURL
+
+	// Hosts contains module path hosts served by instance. Default is BaseURL host.
+	Hosts []string `toml:",omitempty"`
+
+	// Token is optional access token
+	// It's needed to access private projects
+	Token MaskedString
+}
+
+// APIInstance contains code hosting instance (Bitbucket Server, Gitea) configuration
+type APIInstance struct {
+	// BaseURL is an instance url (i.e. https://bitbucket.mycorp.com)
 	BaseURL MaskedURL
+
+	// Hosts contains module path hosts served by instance. Default is BaseURL host.
+	Hosts []string `toml:",omitempty"`
+
+	// Token is optional access token. It has precedence over Username and Password.
+	Token MaskedString
+
+	// Username and Password are optional basic auth credentials
+	Username string `toml:",omitempty"`
+	Password MaskedString
 }
 
 // OverridePath is a single override for module path
@@ -164,6 +334,32 @@ type Validation struct {
 	NotificationType NotificationType
 
 	Webhook *WebhookNotification
+
+	// Denial configures responses for forbidden modules
+	Denial Denial
+
+	// ListConcurrency limits number of simultaneous validations during version lists filtering. Default is 4.
+	ListConcurrency int `toml:",omitempty"`
+
+	// BatchConcurrency limits number of simultaneous validations of batch validation API requests. Default is 8.
+	BatchConcurrency int `toml:",omitempty"`
+
+	// NoticeConcurrency limits number of simultaneously downloaded modules during attribution notices generation. Default is 4.
+	NoticeConcurrency int `toml:",omitempty"`
+}
+
+// Denial configures descriptions of forbidden modules which are shown to users
+type Denial struct {
+	// HelpURL is an optional link to license policy page which will be included into denial responses
+	HelpURL string `toml:",omitempty"`
+
+	// BlacklistedTemplate, DeniedLicenseTemplate and UnknownLicenseTemplate are optional message templates
+	// for corresponding rejection reasons in 'text/template' syntax. Rendered message is shown to developers by 'go' command.
+	// Execution context defined in 'pkg/validation.Denial', default message is available as '.Message'.
+	// HelpURL is not appended to rendered message, use '.HelpURL' in template to show it.
+	BlacklistedTemplate    string `toml:",omitempty"`
+	DeniedLicenseTemplate  string `toml:",omitempty"`
+	UnknownLicenseTemplate string `toml:",omitempty"`
 }
 
 type WebhookNotification struct {
@@ -195,12 +391,91 @@ type RuleSet struct {
 	DeniedLicenses []License
 }
 
+// Proxy contains goproxy server mode settings
+type Proxy struct {
+	// DenyStatus is a response status code for denied modules. Default is 403.
+	// 410 may be used to let go command fall through to next proxy in GOPROXY list.
+	DenyStatus int `toml:",omitempty"`
+}
+
 // Server represents http-server configuration
 type Server struct {
 	// ListenAddr is a listen address (i.e. ':8080')
 	ListenAddr string
+
+	// Mode is a server mode. Ignored for health server. Available modes:
+	// * athens (default) - serves Athens admission hook at /athens/admission
+	// * goproxy - additionally serves goproxy protocol at root path streaming responses from GoProxy.BaseURL
+	// and validating modules before serving .mod and .zip files.
+	Mode ServerMode `toml:",omitempty"`
 	// EnablePprof adds pprof handlers to server at /pprof
 	EnablePprof bool
+
+	// TLS enables serving HTTPS if provided
+	TLS *TLS `toml:",omitempty"`
+
+	// TrustedProxies contains networks (CIDR notation or single IPs) of reverse proxies
+	// which are trusted to set X-Forwarded-For and X-Real-Ip headers.
+	// These headers are ignored for requests from other sources.
+	TrustedProxies []string `toml:",omitempty"`
+
+	// Access is an optional network access control for admission endpoint callers.
+	// Ignored for health server.
+	Access *Access `toml:",omitempty"`
+
+	// Auth is an optional caller authentication for admission endpoint.
+	// Request passes if it's authenticated by any of configured methods.
+	// Ignored for health server.
+	Auth *Auth `toml:",omitempty"`
+}
+
+// TLS represents server TLS configuration.
+// Certificate, key and client CA files are reloaded when changed, so certificates can be rotated without restart.
+type TLS struct {
+	// CertFile is a path to PEM-encoded certificate (chain)
+	CertFile string
+
+	// KeyFile is a path to PEM-encoded private key
+	KeyFile string
+
+	// ClientCAFile is an optional path to PEM-encoded CA bundle for client certificates verification.
+	// Client certificates are verified if presented.
+	ClientCAFile string `toml:",omitempty"`
+
+	// RequireClientCert rejects connections without valid client certificate. Requires ClientCAFile.
+	RequireClientCert bool `toml:",omitempty"`
+
+	// MinVersion is a minimal TLS version. Available values: "1.0", "1.1", "1.2", "1.3". Default is "1.2".
+	MinVersion string `toml:",omitempty"`
+
+	// ReloadInterval is a period of files modification check. Default is 10 seconds.
+	ReloadInterval time.Duration `toml:",omitempty"`
+}
+
+// Access represents callers network access control configuration
+type Access struct {
+	// AllowedCIDRs contains networks allowed to call endpoint. If empty all not denied networks allowed.
+	AllowedCIDRs []string `toml:",omitempty"`
+
+	// DeniedCIDRs contains networks not allowed to call endpoint. It has priority over AllowedCIDRs.
+	DeniedCIDRs []string `toml:",omitempty"`
+}
+
+// Auth represents caller authentication configuration
+type Auth struct {
+	// SharedSecretHeader is a name of header containing shared secret.
+	// Default is 'Authorization' with 'Bearer' scheme.
+	SharedSecretHeader string `toml:",omitempty"`
+
+	// SharedSecrets is a list of accepted shared secrets (bearer tokens)
+	SharedSecrets []MaskedString `toml:",omitempty"`
+
+	// BasicUsers maps user names to passwords for HTTP basic authentication
+	BasicUsers map[string]MaskedString `toml:",omitempty"`
+
+	// ClientCertSubjects is a list of allowed client certificate subjects (distinguished name or common name).
+	// Requires TLS with client certificates verification.
+	ClientCertSubjects []string `toml:",omitempty"`
 }
 
 // Trace represents opentelemetry configuration
@@ -0,0 +1,598 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pelletier/go-toml"
+
+	"github.com/xakep666/licensevalidator/pkg/github"
+	"github.com/xakep666/licensevalidator/pkg/goproxy"
+	"github.com/xakep666/licensevalidator/pkg/spdx"
+)
+
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+)
+
+// LintIssue is a single problem found in config
+type LintIssue struct {
+	// Line and Col point to config key or section related to issue. Both are zero if position is unknown.
+	Line, Col int
+
+	Severity LintSeverity
+	Message  string
+}
+
+func (i *LintIssue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Col, i.Severity, i.Message)
+}
+
+// LintConfig checks config for mistakes which are not caught by decoding or not reported at all on startup:
+// unknown keys, unknown licenses, overlapping and shadowed rules, unanchored regular expressions, incomplete sections.
+// Issues are sorted by position. Error is returned if config can't be decoded at all.
+func LintConfig(data []byte) ([]LintIssue, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("config parse failed: %w", err)
+	}
+
+	legacyPos, legacy := upgradeLegacyGithub(tree)
+
+	var cfg Config
+	if err := tree.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("config decode failed: %w", err)
+	}
+
+	l := &linter{cfg: &cfg, tree: tree}
+
+	if legacy {
+		l.report(legacyPos, LintWarning, "single [Github] table is deprecated, use [[Github]] array of tables")
+	}
+
+	l.checkKeys(tree, reflect.TypeOf(cfg), "")
+	l.checkRuleSet()
+	l.checkPathOverrides()
+	l.checkUnknownLicenseAction()
+	l.checkDenialTemplates()
+	l.checkServers()
+	l.checkResolvers()
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].Line != l.issues[j].Line {
+			return l.issues[i].Line < l.issues[j].Line
+		}
+
+		return l.issues[i].Col < l.issues[j].Col
+	})
+
+	return l.issues, nil
+}
+
+type linter struct {
+	cfg    *Config
+	tree   *toml.Tree
+	issues []LintIssue
+}
+
+func (l *linter) report(pos toml.Position, severity LintSeverity, format string, args ...interface{}) {
+	l.issues = append(l.issues, LintIssue{
+		Line:     pos.Line,
+		Col:      pos.Col,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// keyVariants returns keys matched to field name by toml decoder
+func keyVariants(name string) []string {
+	return []string{
+		name,
+		strings.ToLower(name),
+		strings.ToTitle(name),
+		strings.ToLower(name[:1]) + name[1:],
+	}
+}
+
+// lookupKey finds key used in tree for field name
+func lookupKey(tree *toml.Tree, name string) (string, bool) {
+	if tree == nil {
+		return "", false
+	}
+
+	for _, key := range keyVariants(name) {
+		if tree.HasPath([]string{key}) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// subtree returns table by path of field names. It returns nil if table is not present.
+func subtree(tree *toml.Tree, names ...string) *toml.Tree {
+	for _, name := range names {
+		key, ok := lookupKey(tree, name)
+		if !ok {
+			return nil
+		}
+
+		tree, _ = tree.GetPath([]string{key}).(*toml.Tree)
+	}
+
+	return tree
+}
+
+// tables returns array of tables by path of field names
+func tables(tree *toml.Tree, names ...string) []*toml.Tree {
+	parent := subtree(tree, names[:len(names)-1]...)
+
+	key, ok := lookupKey(parent, names[len(names)-1])
+	if !ok {
+		return nil
+	}
+
+	ret, _ := parent.GetPath([]string{key}).([]*toml.Tree)
+	return ret
+}
+
+// position returns position of key for field name or position of table if key not found
+func position(tree *toml.Tree, name string) toml.Position {
+	if key, ok := lookupKey(tree, name); ok {
+		return tree.GetPositionPath([]string{key})
+	}
+
+	return tablePosition(tree)
+}
+
+func tablePosition(tree *toml.Tree) toml.Position {
+	if tree == nil {
+		return toml.Position{}
+	}
+
+	return tree.Position()
+}
+
+func stringValue(tree *toml.Tree, name string) string {
+	key, ok := lookupKey(tree, name)
+	if !ok {
+		return ""
+	}
+
+	s, _ := tree.GetPath([]string{key}).(string)
+	return s
+}
+
+func boolValue(tree *toml.Tree, name string) bool {
+	key, ok := lookupKey(tree, name)
+	if !ok {
+		return false
+	}
+
+	b, _ := tree.GetPath([]string{key}).(bool)
+	return b
+}
+
+// checkKeys reports keys which are not used by config decoder
+func (l *linter) checkKeys(tree *toml.Tree, typ reflect.Type, path string) {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		// maps may contain arbitrary keys
+		return
+	}
+
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("toml"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		for _, key := range keyVariants(name) {
+			fields[key] = field
+		}
+	}
+
+	for _, key := range tree.Keys() {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+
+		field, ok := fields[key]
+		if !ok {
+			pos := tree.GetPositionPath([]string{key})
+
+			if suggestion := similarField(typ, key); suggestion != "" {
+				l.report(pos, LintError, "unknown key %s, did you mean %s?", keyPath, suggestion)
+			} else {
+				l.report(pos, LintError, "unknown key %s", keyPath)
+			}
+
+			continue
+		}
+
+		switch value := tree.GetPath([]string{key}).(type) {
+		case *toml.Tree:
+			l.checkKeys(value, field.Type, keyPath)
+		case []*toml.Tree:
+			for _, item := range value {
+				l.checkKeys(item, field.Type, keyPath)
+			}
+		}
+	}
+}
+
+func similarField(typ reflect.Type, key string) string {
+	for i := 0; i < typ.NumField(); i++ {
+		if strings.EqualFold(typ.Field(i).Name, key) {
+			return typ.Field(i).Name
+		}
+	}
+
+	return ""
+}
+
+// lintMatcher is a parsed module matcher with its position
+type lintMatcher struct {
+	name       string
+	regexp     *regexp.Regexp
+	constraint string
+	pos        toml.Position
+}
+
+func (l *linter) parseMatchers(section string) []lintMatcher {
+	var ret []lintMatcher
+
+	for _, item := range tables(l.tree, "Validation", "RuleSet", section) {
+		m := lintMatcher{
+			name:       stringValue(item, "Name"),
+			constraint: stringValue(item, "VersionConstraint"),
+			pos:        position(item, "Name"),
+		}
+
+		if m.name == "" {
+			l.report(item.Position(), LintError, "%s entry has empty name", section)
+			continue
+		}
+
+		var err error
+
+		m.regexp, err = regexp.Compile(m.name)
+		if err != nil {
+			l.report(m.pos, LintError, "%s entry has invalid name regexp: %s", section, err)
+			continue
+		}
+
+		l.checkAnchored(m.pos, section, m.name)
+
+		if m.constraint != "" {
+			if _, err := semver.NewConstraint(m.constraint); err != nil {
+				l.report(position(item, "VersionConstraint"), LintError, "%s entry has invalid version constraint: %s", section, err)
+			}
+		}
+
+		ret = append(ret, m)
+	}
+
+	return ret
+}
+
+func (l *linter) checkAnchored(pos toml.Position, section, expr string) {
+	if !strings.HasPrefix(expr, "^") || !strings.HasSuffix(expr, "$") && !strings.HasSuffix(expr, ".*") {
+		l.report(pos, LintWarning, "%s regexp %q is not anchored (^...$) and matches module names containing it", section, expr)
+	}
+}
+
+// literal returns module name if regexp looks like plain module name (unescaped dots are allowed)
+func literal(re *regexp.Regexp) (string, bool) {
+	name := strings.TrimSuffix(strings.TrimPrefix(re.String(), "^"), "$")
+	name = strings.ReplaceAll(name, `\.`, ".")
+
+	if strings.ContainsAny(name, `\+*?()|[]{}^$`) {
+		return "", false
+	}
+
+	return name, true
+}
+
+func (l *linter) checkRuleSet() {
+	whitelist := l.parseMatchers("WhitelistedModules")
+	blacklist := l.parseMatchers("BlacklistedModules")
+
+	for _, b := range blacklist {
+		for _, w := range whitelist {
+			switch {
+			case b.name == w.name:
+				l.report(b.pos, LintWarning,
+					"blacklisted modules %q are also whitelisted (line %d), whitelist takes precedence", b.name, w.pos.Line)
+			case matchesLiteral(w.regexp, b.regexp):
+				l.report(b.pos, LintWarning,
+					"blacklisted module %q is shadowed by whitelist entry %q (line %d)", b.name, w.name, w.pos.Line)
+			case matchesLiteral(b.regexp, w.regexp):
+				l.report(w.pos, LintWarning,
+					"whitelisted module %q is also matched by blacklist entry %q (line %d), whitelist takes precedence",
+					w.name, b.name, b.pos.Line)
+			}
+		}
+	}
+
+	allowed := l.checkLicenses("AllowedLicenses")
+	denied := l.checkLicenses("DeniedLicenses")
+
+	for _, d := range denied {
+		shadowedBy := -1
+		for i, a := range allowed {
+			if a.license.SPDXID != "" && a.license.SPDXID == d.license.SPDXID ||
+				a.license.Name != "" && a.license.Name == d.license.Name {
+				shadowedBy = i
+				break
+			}
+		}
+
+		switch {
+		case shadowedBy >= 0:
+			l.report(d.pos, LintError,
+				"license %s is both allowed (line %d) and denied, AllowedLicenses takes precedence",
+				d.license.display(), allowed[shadowedBy].pos.Line)
+		case len(allowed) > 0:
+			l.report(d.pos, LintWarning,
+				"denied license %s has no effect because modules with licenses not in AllowedLicenses are denied anyway",
+				d.license.display())
+		}
+	}
+}
+
+// matchesLiteral returns true if re matches the only name matched by literalRe
+func matchesLiteral(re, literalRe *regexp.Regexp) bool {
+	name, ok := literal(literalRe)
+	return ok && re.MatchString(name)
+}
+
+type lintLicense struct {
+	license License
+	pos     toml.Position
+}
+
+func (l License) display() string {
+	if l.SPDXID != "" {
+		return l.SPDXID
+	}
+
+	return fmt.Sprintf("%q", l.Name)
+}
+
+func (l *linter) checkLicenses(section string) []lintLicense {
+	var ret []lintLicense
+
+	for _, item := range tables(l.tree, "Validation", "RuleSet", section) {
+		lic := lintLicense{
+			license: License{SPDXID: stringValue(item, "SPDXID"), Name: stringValue(item, "Name")},
+			pos:     item.Position(),
+		}
+
+		switch {
+		case lic.license.SPDXID != "":
+			lic.pos = position(item, "SPDXID")
+			if _, ok := spdx.LicenseByID(lic.license.SPDXID); !ok {
+				l.report(lic.pos, LintError, "%s entry has unknown SPDX license id %s", section, lic.license.SPDXID)
+			}
+		case lic.license.Name != "":
+			lic.pos = position(item, "Name")
+			if _, ok := spdx.LicenseByName(lic.license.Name); !ok {
+				l.report(lic.pos, LintWarning,
+					"%s entry license %q doesn't match any SPDX license name, consider using SPDXID", section, lic.license.Name)
+			}
+		default:
+			l.report(lic.pos, LintError, "%s entry has neither SPDXID nor Name", section)
+			continue
+		}
+
+		ret = append(ret, lic)
+	}
+
+	return ret
+}
+
+func (l *linter) checkPathOverrides() {
+	for _, item := range tables(l.tree, "PathOverrides") {
+		match := stringValue(item, "Match")
+		pos := position(item, "Match")
+
+		if _, err := regexp.Compile(match); err != nil {
+			l.report(pos, LintError, "PathOverrides entry has invalid match regexp: %s", err)
+			continue
+		}
+
+		l.checkAnchored(pos, "PathOverrides", match)
+	}
+}
+
+func (l *linter) checkUnknownLicenseAction() {
+	validationTree := subtree(l.tree, "Validation")
+	pos := position(validationTree, "UnknownLicenseAction")
+
+	switch l.cfg.Validation.UnknownLicenseAction {
+	case UnknownLicenseAllow, UnknownLicenseDeny:
+		// pass
+	case UnknownLicenseWarn:
+		if l.cfg.Validation.NotificationType != NotificationTypeWebhook {
+			l.report(pos, LintError, "unknown license action %q requires NotificationType = %q",
+				UnknownLicenseWarn, NotificationTypeWebhook)
+		} else if l.cfg.Validation.Webhook == nil {
+			l.report(pos, LintError, "unknown license action %q requires [Validation.Webhook] section", UnknownLicenseWarn)
+		}
+	case "":
+		l.report(pos, LintError, "Validation.UnknownLicenseAction is required, expected one of: %s, %s, %s",
+			UnknownLicenseAllow, UnknownLicenseWarn, UnknownLicenseDeny)
+	default:
+		l.report(pos, LintError, "unknown license action %q, expected one of: %s, %s, %s",
+			l.cfg.Validation.UnknownLicenseAction, UnknownLicenseAllow, UnknownLicenseWarn, UnknownLicenseDeny)
+	}
+}
+
+func (l *linter) checkDenialTemplates() {
+	if _, err := denialMessages(l.cfg); err != nil {
+		l.report(tablePosition(subtree(l.tree, "Validation", "Denial")), LintError, "%s", err)
+	}
+}
+
+func (l *linter) checkResolvers() {
+	pos := position(l.tree, "Resolvers")
+	seen := make(map[ResolverType]bool)
+
+	for _, typ := range l.cfg.Resolvers {
+		switch typ {
+		case ResolverGithub, ResolverGoProxy, ResolverModCache:
+			// pass
+		case ResolverGit:
+			if len(l.cfg.Git.Repos) == 0 && len(l.cfg.Git.DiscoveryHosts) == 0 {
+				l.report(pos, LintWarning, "resolver %q has neither Git.Repos nor Git.DiscoveryHosts, no repository will be found", typ)
+			}
+		case ResolverGitLab:
+			if len(l.cfg.GitLab) == 0 {
+				l.report(pos, LintError, "resolver %q requires at least one GitLab section", typ)
+			}
+		case ResolverBitbucket:
+			if len(l.cfg.Bitbucket) == 0 {
+				l.report(pos, LintError, "resolver %q requires at least one Bitbucket section", typ)
+			}
+		case ResolverGitea:
+			if len(l.cfg.Gitea) == 0 {
+				l.report(pos, LintError, "resolver %q requires at least one Gitea section", typ)
+			}
+		default:
+			l.report(pos, LintError, "unknown resolver %q, expected one of: %s, %s, %s, %s, %s, %s, %s",
+				typ, ResolverGithub, ResolverGoProxy, ResolverModCache, ResolverGit, ResolverGitLab, ResolverBitbucket, ResolverGitea)
+			continue
+		}
+
+		if seen[typ] {
+			l.report(pos, LintError, "resolver %q specified more than once", typ)
+		}
+
+		seen[typ] = true
+	}
+
+	for _, item := range tables(l.tree, "Git", "Repos") {
+		if _, err := regexp.Compile(stringValue(item, "Match")); err != nil {
+			l.report(position(item, "Match"), LintError, "Git.Repos entry has invalid match regexp: %s", err)
+		}
+	}
+
+	for _, host := range l.cfg.Git.DiscoveryHosts {
+		if _, err := path.Match(host, ""); err != nil {
+			l.report(position(subtree(l.tree, "Git"), "DiscoveryHosts"), LintError, "Git.DiscoveryHosts has invalid host pattern %q: %s", host, err)
+		}
+	}
+
+	hosts := make(map[string]bool)
+	for _, item := range tables(l.tree, "Github") {
+		host := stringValue(item, "Host")
+		if host == "" {
+			host = github.DefaultHost
+		}
+
+		if _, err := path.Match(host, ""); err != nil {
+			l.report(position(item, "Host"), LintError, "Github entry has invalid host pattern %q: %s", host, err)
+		}
+
+		if hosts[host] {
+			l.report(position(item, "Host"), LintError, "Github host %q specified more than once", host)
+		}
+
+		hosts[host] = true
+
+		_, hasTokens := lookupKey(item, "AccessTokens")
+		if boolValue(item, "GraphQL") && stringValue(item, "AccessToken") == "" && !hasTokens && len(tables(item, "Apps")) == 0 {
+			l.report(position(item, "GraphQL"), LintError, "Github GraphQL API requires AccessToken, AccessTokens or Apps")
+		}
+
+		for _, app := range tables(item, "Apps") {
+			for _, field := range []string{"AppID", "InstallationID"} {
+				if _, ok := lookupKey(app, field); !ok {
+					l.report(tablePosition(app), LintError, "Github.Apps entry has no %s", field)
+				}
+			}
+
+			if stringValue(app, "PrivateKeyFile") == "" && stringValue(app, "PrivateKey") == "" {
+				l.report(tablePosition(app), LintError, "Github.Apps entry has neither PrivateKeyFile nor PrivateKey")
+			}
+		}
+	}
+
+	if goProxy := subtree(l.tree, "GoProxy"); stringValue(goProxy, "BaseURL") != "" {
+		list := stringValue(goProxy, "BaseURL")
+		if _, err := goproxy.ParseProxyList(list); err != nil {
+			l.report(position(goProxy, "BaseURL"), LintError, "GoProxy.BaseURL is invalid: %s", err)
+		} else if goproxy.HasDirect(list) {
+			l.report(position(goProxy, "BaseURL"), LintWarning, "GoProxy.BaseURL \"direct\" entries are ignored, modules are fetched only through proxies")
+		}
+	}
+
+	for _, section := range []string{"GitLab", "Bitbucket", "Gitea"} {
+		for _, item := range tables(l.tree, section) {
+			if stringValue(item, "BaseURL") == "" {
+				l.report(position(item, "BaseURL"), LintError, "%s entry has no BaseURL", section)
+			}
+		}
+	}
+}
+
+func (l *linter) checkServers() {
+	switch l.cfg.Server.Mode {
+	case "", ServerModeAthens, ServerModeGoProxy:
+		// pass
+	default:
+		l.report(position(subtree(l.tree, "Server"), "Mode"), LintError, "unknown server mode %q", l.cfg.Server.Mode)
+	}
+
+	servers := []struct {
+		name   string
+		server *Server
+	}{
+		{name: "Server", server: &l.cfg.Server},
+		{name: "HealthServer", server: l.cfg.HealthServer},
+	}
+
+	for _, item := range servers {
+		name, server := item.name, item.server
+		if server == nil || server.TLS == nil {
+			continue
+		}
+
+		pos := tablePosition(subtree(l.tree, name, "TLS"))
+
+		if server.TLS.RequireClientCert && server.TLS.ClientCAFile == "" {
+			l.report(pos, LintError, "%s.TLS.RequireClientCert requires ClientCAFile", name)
+		}
+
+		if _, err := tlsVersion(server.TLS.MinVersion); err != nil {
+			l.report(pos, LintError, "%s.TLS: %s", name, err)
+		}
+	}
+
+	if l.cfg.Server.Auth != nil && len(l.cfg.Server.Auth.ClientCertSubjects) > 0 &&
+		(l.cfg.Server.TLS == nil || l.cfg.Server.TLS.ClientCAFile == "") {
+		l.report(tablePosition(subtree(l.tree, "Server", "Auth")), LintError,
+			"Server.Auth.ClientCertSubjects requires Server.TLS.ClientCAFile")
+	}
+}
@@ -0,0 +1,310 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/xakep666/licensevalidator/cmd/licensevalidator/app"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintConfig(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name           string
+		Config         string
+		ExpectedIssues []string
+		ExpectError    bool
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			issues, err := app.LintConfig([]byte(tc.Config))
+			if tc.ExpectError {
+				assert.Error(t, err)
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			actual := make([]string, 0, len(issues))
+			for _, issue := range issues {
+				actual = append(actual, issue.String())
+			}
+
+			assert.Equal(t, tc.ExpectedIssues, actual)
+		})
+	}
+
+	f(testCase{
+		Name: "clean",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "deny"
+
+  [[Validation.RuleSet.WhitelistedModules]]
+    Name = "^gitlab.mycorp.com/.*"
+
+  [[Validation.RuleSet.AllowedLicenses]]
+    SPDXID = "MIT"
+
+[Server]
+  ListenAddr = ":8080"
+`,
+		ExpectedIssues: []string{},
+	})
+
+	f(testCase{
+		Name: "unknown keys",
+		Config: `
+Debug = true
+Debugg = true
+
+[Server]
+  listenaddr = ":8080"
+  Listenaddr = ":8080"
+
+[Server.TLS]
+  Cert = "tls.crt"
+`,
+		ExpectedIssues: []string{
+			"0:0: error: Validation.UnknownLicenseAction is required, expected one of: allow, warn, deny",
+			"3:1: error: unknown key Debugg",
+			"7:3: error: unknown key Server.Listenaddr, did you mean ListenAddr?",
+			"10:3: error: unknown key Server.TLS.Cert",
+		},
+	})
+
+	f(testCase{
+		Name: "rule set",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "allow"
+
+  [[Validation.RuleSet.WhitelistedModules]]
+    Name = "github.com/user/repo"
+
+  [[Validation.RuleSet.BlacklistedModules]]
+    Name = "^github\\.com/user/.*$"
+
+  [[Validation.RuleSet.BlacklistedModules]]
+    Name = "^github.com/user/(repo"
+
+  [[Validation.RuleSet.AllowedLicenses]]
+    SPDXID = "MIT"
+
+  [[Validation.RuleSet.AllowedLicenses]]
+    Name = "My License"
+
+  [[Validation.RuleSet.DeniedLicenses]]
+    SPDXID = "MIT"
+
+  [[Validation.RuleSet.DeniedLicenses]]
+    SPDXID = "NOT-EXISTING"
+`,
+		ExpectedIssues: []string{
+			"6:5: warning: WhitelistedModules regexp \"github.com/user/repo\" is not anchored (^...$) and matches module names containing it",
+			"6:5: warning: whitelisted module \"github.com/user/repo\" is also matched by blacklist entry \"^github\\\\.com/user/.*$\" (line 9), whitelist takes precedence",
+			"12:5: error: BlacklistedModules entry has invalid name regexp: error parsing regexp: missing closing ): `^github.com/user/(repo`",
+			"18:5: warning: AllowedLicenses entry license \"My License\" doesn't match any SPDX license name, consider using SPDXID",
+			"21:5: error: license MIT is both allowed (line 15) and denied, AllowedLicenses takes precedence",
+			"24:5: error: DeniedLicenses entry has unknown SPDX license id NOT-EXISTING",
+			"24:5: warning: denied license NOT-EXISTING has no effect because modules with licenses not in AllowedLicenses are denied anyway",
+		},
+	})
+
+	f(testCase{
+		Name: "warn without webhook",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "warn"
+  NotificationType = "webhook"
+`,
+		ExpectedIssues: []string{
+			"3:3: error: unknown license action \"warn\" requires [Validation.Webhook] section",
+		},
+	})
+
+	f(testCase{
+		Name: "path overrides and templates",
+		Config: `
+[[PathOverrides]]
+  Match = "go.uber.org/(.*)$"
+  Replace = "github.com/uber-go/$1"
+
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[Validation.Denial]
+  DeniedLicenseTemplate = "{{ .Unknown }}"
+`,
+		ExpectedIssues: []string{
+			"3:3: warning: PathOverrides regexp \"go.uber.org/(.*)$\" is not anchored (^...$) and matches module names containing it",
+			"9:1: error: denied_license template check failed: denied_license message template execution failed: template: denied_license:1:3: executing \"denied_license\" at <.Unknown>: can't evaluate field Unknown in type *validation.Denial",
+		},
+	})
+
+	f(testCase{
+		Name: "resolvers",
+		Config: `
+Resolvers = ["modcache", "svn", "gitlab", "modcache"]
+
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[[Git.Repos]]
+  Match = "^git.mycorp.com/([^/]+/[^/]+"
+  URL = "ssh://git@git.mycorp.com/$1.git"
+`,
+		ExpectedIssues: []string{
+			"2:1: error: unknown resolver \"svn\", expected one of: github, goproxy, modcache, git, gitlab, bitbucket, gitea",
+			"2:1: error: resolver \"gitlab\" requires at least one GitLab section",
+			"2:1: error: resolver \"modcache\" specified more than once",
+			"8:3: error: Git.Repos entry has invalid match regexp: error parsing regexp: missing closing ): `^git.mycorp.com/([^/]+/[^/]+`",
+		},
+	})
+
+	f(testCase{
+		Name: "github instances",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[[Github]]
+  AccessToken = "token"
+
+[[Github]]
+  Host = "github.com"
+
+[[Github]]
+  Host = "ghe[.corp.example"
+  BaseURL = "https://ghe.corp.example/api/v3/"
+`,
+		ExpectedIssues: []string{
+			"9:3: error: Github host \"github.com\" specified more than once",
+			"12:3: error: Github entry has invalid host pattern \"ghe[.corp.example\": syntax error in pattern",
+		},
+	})
+
+	f(testCase{
+		Name: "github apps",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[[Github]]
+  AccessTokens = ["token1", "token2"]
+
+  [[Github.Apps]]
+    AppID = 1
+    InstallationID = 2
+    PrivateKeyFile = "/etc/licensevalidator/app.pem"
+
+  [[Github.Apps]]
+    AppID = 3
+
+[[Github]]
+  Host = "ghe.corp.example"
+  BaseURL = "https://ghe.corp.example/api/v3/"
+  GraphQL = true
+`,
+		ExpectedIssues: []string{
+			"13:3: error: Github.Apps entry has no InstallationID",
+			"13:3: error: Github.Apps entry has neither PrivateKeyFile nor PrivateKey",
+			"19:3: error: Github GraphQL API requires AccessToken, AccessTokens or Apps",
+		},
+	})
+
+	f(testCase{
+		Name: "legacy github table",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[Github]
+  AccessToken = "abc"
+`,
+		ExpectedIssues: []string{
+			"5:1: warning: single [Github] table is deprecated, use [[Github]] array of tables",
+		},
+	})
+
+	f(testCase{
+		Name: "goproxy list",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[GoProxy]
+  BaseURL = "https://goproxy.corp.example|ftp://proxy.golang.org"
+`,
+		ExpectedIssues: []string{
+			"6:3: error: GoProxy.BaseURL is invalid: proxy url \"ftp://proxy.golang.org\" must be an http(s) url with host",
+		},
+	})
+
+	f(testCase{
+		Name: "goproxy direct",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[GoProxy]
+  BaseURL = "https://goproxy.corp.example,direct"
+`,
+		ExpectedIssues: []string{
+			"6:3: warning: GoProxy.BaseURL \"direct\" entries are ignored, modules are fetched only through proxies",
+		},
+	})
+
+	f(testCase{
+		Name: "goproxy direct only",
+		Config: `
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[GoProxy]
+  BaseURL = "direct"
+`,
+		ExpectedIssues: []string{
+			"6:3: error: GoProxy.BaseURL is invalid: proxy list \"direct\": \"direct\" is not supported, modules are fetched only through proxies",
+		},
+	})
+
+	f(testCase{
+		Name: "git discovery",
+		Config: `
+Resolvers = ["git"]
+
+[Validation]
+  UnknownLicenseAction = "deny"
+
+[Git]
+  DiscoveryHosts = ["go.mycorp.com", "[mycorp.com"]
+`,
+		ExpectedIssues: []string{
+			"8:3: error: Git.DiscoveryHosts has invalid host pattern \"[mycorp.com\": syntax error in pattern",
+		},
+	})
+
+	f(testCase{
+		Name: "git without repositories",
+		Config: `
+Resolvers = ["git"]
+
+[Validation]
+  UnknownLicenseAction = "deny"
+`,
+		ExpectedIssues: []string{
+			"2:1: warning: resolver \"git\" has neither Git.Repos nor Git.DiscoveryHosts, no repository will be found",
+		},
+	})
+
+	f(testCase{
+		Name:        "invalid toml",
+		Config:      "[Server",
+		ExpectError: true,
+	})
+}
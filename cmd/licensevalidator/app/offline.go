@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.uber.org/zap"
+
+	"github.com/xakep666/licensevalidator/internal/preload"
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/health"
+	"github.com/xakep666/licensevalidator/pkg/notice"
+	"github.com/xakep666/licensevalidator/pkg/sbom"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+)
+
+// Offline is an in-process validation stack built from the same config as App but without http servers.
+// It's intended for CLI usage (i.e. in CI pipelines).
+type Offline struct {
+	validator *batch.Validator
+	explainer *validation.Explainer
+	collector *notice.Collector
+	generator *sbom.Generator
+	checker   *sbom.Checker
+}
+
+func NewOffline(cfg Config) (*Offline, error) {
+	logger, loglevel, err := setupLogger(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup logger: %w", err)
+	}
+
+	if !cfg.Debug {
+		// keep output clean, only problems are interesting here
+		loglevel.SetLevel(zap.WarnLevel)
+	}
+
+	preload.LicenseDB()
+
+	stack, err := setupValidation(&cfg, logger, trace.NoopTracer{}, metric.NoopMeter{}, health.NewHealth())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Offline{
+		validator: batchValidator(logger, &cfg, stack),
+		explainer: validation.NewExplainer(logger, validation.ExplainerParams{
+			Validator: stack.validator,
+			HelpURL:   cfg.Validation.Denial.HelpURL,
+			Messages:  stack.denialMessages,
+		}),
+		collector: noticeCollector(logger, &cfg, stack),
+		generator: sbom.NewGenerator(logger, sbom.GeneratorParams{
+			LicenseResolver: stack.licenseResolver,
+			Concurrency:     cfg.Validation.BatchConcurrency,
+		}),
+		checker: sbomChecker(logger, &cfg, stack),
+	}, nil
+}
+
+// Check validates all modules and returns report
+func (o *Offline) Check(ctx context.Context, modules []validation.Module) (*batch.Report, error) {
+	return o.validator.Validate(ctx, modules)
+}
+
+// Explain validates single module and returns all steps made during validation
+func (o *Offline) Explain(ctx context.Context, m validation.Module) *validation.Explanation {
+	return o.explainer.Explain(ctx, m)
+}
+
+// Notices collects attribution information (license texts, NOTICE files and copyrights) of modules
+func (o *Offline) Notices(ctx context.Context, modules []validation.Module) (*notice.Document, error) {
+	return o.collector.Collect(ctx, modules)
+}
+
+// SBOM resolves licenses of modules and returns bill of materials named by name
+func (o *Offline) SBOM(ctx context.Context, name string, modules []validation.Module) (*sbom.BOM, error) {
+	return o.generator.Generate(ctx, name, modules)
+}
+
+// CheckSBOM validates modules listed in SBOM document taking licenses from source and returns report
+func (o *Offline) CheckSBOM(ctx context.Context, declared []sbom.Declared, source sbom.LicenseSource) (*batch.Report, error) {
+	return o.checker.Check(ctx, declared, source)
+}
@@ -15,6 +15,11 @@ var ConfigSample = app.Config{
 	Cache: &app.Cache{
 		Type: app.CacheTypeMemory,
 	},
+	Github: []app.Github{
+		{
+			Host: "github.com",
+		},
+	},
 	GoProxy: app.GoProxy{
 		BaseURL: "https://proxy.golang.org",
 	},
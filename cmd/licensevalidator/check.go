@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/cmd/licensevalidator/app"
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/modlist"
+	"github.com/xakep666/licensevalidator/pkg/report"
+	"github.com/xakep666/licensevalidator/pkg/sbom"
+
+	"github.com/urfave/cli/v2"
+)
+
+// check command exit codes
+const (
+	checkExitDenied = 1
+	checkExitErrors = 2
+	checkExitFailed = 3
+)
+
+var (
+	checkFormatFlag = cli.StringFlag{
+		Name:    "format",
+		Aliases: []string{"f"},
+		Usage:   fmt.Sprintf("Output format (%s)", joinFormats(report.Formats)),
+		Value:   string(report.FormatTable),
+	}
+
+	checkInputFormatFlag = cli.StringFlag{
+		Name:  "input-format",
+		Usage: "Input format (json, gomod, gosum, golist). Detected by content if not set",
+	}
+
+	checkOutputFlag = cli.PathFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Usage:   "Output file. Report is written to stdout if not set",
+	}
+
+	checkSBOMFlag = cli.BoolFlag{
+		Name:  "sbom",
+		Usage: "Input is SPDX (JSON or tag-value) or CycloneDX (JSON) document, go modules are taken from it",
+	}
+
+	checkLicensesFlag = cli.StringFlag{
+		Name: "licenses",
+		Usage: fmt.Sprintf("Source of licenses for --sbom input: %s trusts licenses from document, %s resolves them with configured resolvers",
+			sbom.LicensesDeclared, sbom.LicensesResolve),
+		Value: string(sbom.LicensesDeclared),
+	}
+)
+
+var (
+	checkIn  io.Reader = os.Stdin  // for mocking
+	checkOut io.Writer = os.Stdout // for mocking
+)
+
+func CheckCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "check",
+		Usage:     "Validates module list offline",
+		ArgsUsage: "[go.mod | go.sum | file with \"go list -m -json all\" output | sbom document | -]",
+		Description: "Validates all modules from input using rules from config and prints report. Input is read from stdin if not given.\n" +
+			fmt.Sprintf("Exit code is %d if some modules denied, %d if some modules validation failed and %d on other errors.",
+				checkExitDenied, checkExitErrors, checkExitFailed),
+		Flags: []cli.Flag{
+			&configFileFlag,
+			&checkFormatFlag,
+			&checkInputFormatFlag,
+			&checkOutputFlag,
+			&checkSBOMFlag,
+			&checkLicensesFlag,
+		},
+		Action:       checkAction,
+		OnUsageError: exitOnUsageError(checkExitFailed),
+	}
+}
+
+func checkAction(ctx *cli.Context) error {
+	format := report.Format(ctx.String(checkFormatFlag.Name))
+	if !knownFormat(format) {
+		return cli.Exit(fmt.Sprintf("Unknown output format %s", format), checkExitFailed)
+	}
+
+	source := ctx.Args().First()
+
+	data, err := readCheckInput(source)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Input read failed: %s", err), checkExitFailed)
+	}
+
+	check, err := parseCheckInput(ctx, data)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Input parse failed: %s", err), checkExitFailed)
+	}
+
+	cfg, err := app.ConfigFromFile(ctx.Path(configFileFlag.Name))
+	if err != nil {
+		return cli.Exit(err, checkExitFailed)
+	}
+
+	offline, err := app.NewOffline(cfg)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Failed to init validator: %s", err), checkExitFailed)
+	}
+
+	r, err := check(ctx.Context, offline)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Validation failed: %s", err), checkExitFailed)
+	}
+
+	out := checkOut
+	if output := ctx.Path(checkOutputFlag.Name); output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Output file create failed: %s", err), checkExitFailed)
+		}
+
+		defer f.Close()
+
+		out = f
+	}
+
+	if err := report.Write(out, r, format, source); err != nil {
+		return cli.Exit(fmt.Sprintf("Report write failed: %s", err), checkExitFailed)
+	}
+
+	switch r.Verdict {
+	case batch.StatusDenied:
+		return cli.Exit("", checkExitDenied)
+	case batch.StatusError:
+		return cli.Exit("", checkExitErrors)
+	default:
+		return nil
+	}
+}
+
+// checkFunc validates parsed input
+type checkFunc func(ctx context.Context, offline *app.Offline) (*batch.Report, error)
+
+func parseCheckInput(ctx *cli.Context, data []byte) (checkFunc, error) {
+	if !ctx.Bool(checkSBOMFlag.Name) {
+		modules, err := modlist.Parse(data, modlist.Format(ctx.String(checkInputFormatFlag.Name)))
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, offline *app.Offline) (*batch.Report, error) {
+			return offline.Check(ctx, modules)
+		}, nil
+	}
+
+	licenses := sbom.LicenseSource(ctx.String(checkLicensesFlag.Name))
+	if !knownLicenseSource(licenses) {
+		return nil, fmt.Errorf("unknown license source %s", licenses)
+	}
+
+	declared, err := sbom.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, offline *app.Offline) (*batch.Report, error) {
+		return offline.CheckSBOM(ctx, declared, licenses)
+	}, nil
+}
+
+func readCheckInput(source string) ([]byte, error) {
+	if source == "" || source == "-" {
+		return ioutil.ReadAll(checkIn)
+	}
+
+	return ioutil.ReadFile(source)
+}
+
+func knownFormat(format report.Format) bool {
+	for _, item := range report.Formats {
+		if item == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+func knownLicenseSource(source sbom.LicenseSource) bool {
+	for _, item := range sbom.LicenseSources {
+		if item == source {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinFormats(formats []report.Format) string {
+	items := make([]string, 0, len(formats))
+	for _, format := range formats {
+		items = append(items, string(format))
+	}
+
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+const mitLicense = `MIT License
+
+Copyright (c) 2020 Test Author
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+const (
+	allowedGoMod = "module example.com/app\n\nrequire example.com/mit v1.0.0\n"
+	deniedGoMod  = "module example.com/app\n\nrequire (\n\texample.com/mit v1.0.0\n\texample.com/blacklisted v1.0.0\n)\n"
+)
+
+// prepareConfig writes config using only modules cache resolver over cache with
+// example.com/mit and example.com/blacklisted modules, so commands work without network
+func prepareConfig(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "licensevalidator-cmd")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for _, module := range []string{"mit@v1.0.0", "blacklisted@v1.0.0"} {
+		moduleDir := filepath.Join(dir, "modcache", "example.com", module)
+		require.NoError(t, os.MkdirAll(moduleDir, 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(moduleDir, "LICENSE"), []byte(mitLicense), 0644))
+	}
+
+	configFile := filepath.Join(dir, "config.toml")
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(fmt.Sprintf(`
+Resolvers = ["modcache"]
+
+[ModCache]
+  Dir = %q
+
+[Validation]
+  UnknownLicenseAction = "deny"
+  ConfidenceThreshold = 0.8
+
+  [[Validation.RuleSet.BlacklistedModules]]
+    Name = "^example.com/blacklisted$"
+
+  [[Validation.RuleSet.AllowedLicenses]]
+    SPDXID = "MIT"
+`, filepath.Join(dir, "modcache"))), 0644))
+
+	return configFile
+}
+
+// runApp runs app with args and stdin, returns exit code and stdout
+func runApp(t *testing.T, stdin string, cmdArgs ...string) (int, string) {
+	t.Helper()
+
+	var (
+		stdout, stderr bytes.Buffer
+		code           int
+		exited         bool
+	)
+
+	checkIn = strings.NewReader(stdin)
+	checkOut, explainOut, lintOut, noticesOut, sbomOut = &stdout, &stdout, &stdout, &stdout, &stdout
+	cli.ErrWriter = &stderr
+	// usage errors are handled by command and app both, first exit code is actual
+	cli.OsExiter = func(c int) {
+		if !exited {
+			code, exited = c, true
+		}
+	}
+	args = append([]string{"licensevalidator"}, cmdArgs...)
+
+	defer func() {
+		checkIn = os.Stdin
+		checkOut, explainOut, lintOut, noticesOut, sbomOut = os.Stdout, os.Stdout, os.Stdout, os.Stdout, os.Stdout
+		cli.ErrWriter = os.Stderr
+		cli.OsExiter = os.Exit
+		args = os.Args
+	}()
+
+	main()
+
+	t.Logf("Exit code %d, stderr:\n%s", code, stderr.String())
+
+	return code, stdout.String()
+}
+
+type commandTestCase struct {
+	name   string
+	stdin  string
+	args   []string
+	code   int
+	output []string
+}
+
+func testCommand(t *testing.T, tc commandTestCase) {
+	t.Run(tc.name, func(t *testing.T) {
+		code, stdout := runApp(t, tc.stdin, tc.args...)
+		assert.Equal(t, tc.code, code)
+		for _, item := range tc.output {
+			assert.Contains(t, stdout, item)
+		}
+	})
+}
+
+func TestCheckCommand(t *testing.T) {
+	config := prepareConfig(t)
+
+	f := func(tc commandTestCase) { testCommand(t, tc) }
+
+	f(commandTestCase{
+		name:   "allowed",
+		stdin:  allowedGoMod,
+		args:   []string{"check", "-c", config, "-f", "json"},
+		output: []string{`"example.com/mit"`, `"verdict": "allowed"`},
+	})
+
+	f(commandTestCase{
+		name:   "denied",
+		stdin:  deniedGoMod,
+		args:   []string{"check", "-c", config, "-f", "json"},
+		code:   checkExitDenied,
+		output: []string{`"example.com/blacklisted"`},
+	})
+
+	f(commandTestCase{
+		name:  "unknown format",
+		stdin: allowedGoMod,
+		args:  []string{"check", "-c", config, "-f", "unknown"},
+		code:  checkExitFailed,
+	})
+
+	f(commandTestCase{
+		name:  "invalid input",
+		stdin: "not a module list",
+		args:  []string{"check", "-c", config, "--input-format", "gomod"},
+		code:  checkExitFailed,
+	})
+
+	f(commandTestCase{
+		name:  "missing config",
+		stdin: allowedGoMod,
+		args:  []string{"check", "-c", config + ".missing"},
+		code:  checkExitFailed,
+	})
+
+	f(commandTestCase{
+		name: "invalid flag",
+		args: []string{"check", "--unknown-flag"},
+		code: checkExitFailed,
+	})
+}
+
+func TestExplainCommand(t *testing.T) {
+	config := prepareConfig(t)
+
+	f := func(tc commandTestCase) { testCommand(t, tc) }
+
+	f(commandTestCase{
+		name:   "allowed",
+		args:   []string{"explain", "-c", config, "-f", "json", "example.com/mit@v1.0.0"},
+		output: []string{`"outcome": "allowed"`, `"license": "MIT"`},
+	})
+
+	f(commandTestCase{
+		name:   "denied",
+		args:   []string{"explain", "-c", config, "-f", "json", "example.com/blacklisted@v1.0.0"},
+		code:   checkExitDenied,
+		output: []string{`"outcome": "denied"`},
+	})
+
+	f(commandTestCase{
+		name: "unknown format",
+		args: []string{"explain", "-c", config, "-f", "html", "example.com/mit@v1.0.0"},
+		code: checkExitFailed,
+	})
+
+	f(commandTestCase{
+		name: "module without version",
+		args: []string{"explain", "-c", config, "example.com/mit"},
+		code: checkExitFailed,
+	})
+
+	f(commandTestCase{
+		name: "invalid flag",
+		args: []string{"explain", "--unknown-flag", "example.com/mit@v1.0.0"},
+		code: checkExitFailed,
+	})
+}
+
+func TestConfigLintCommand(t *testing.T) {
+	config := prepareConfig(t)
+
+	broken := filepath.Join(filepath.Dir(config), "broken.toml")
+	require.NoError(t, ioutil.WriteFile(broken, []byte("Resolvers = [\"unknown\"]\n"), 0644))
+
+	unanchored := filepath.Join(filepath.Dir(config), "unanchored.toml")
+	require.NoError(t, ioutil.WriteFile(unanchored, []byte(`
+[Validation]
+  UnknownLicenseAction = "deny"
+
+  [[Validation.RuleSet.BlacklistedModules]]
+    Name = "rsc.io/pdf"
+`), 0644))
+
+	f := func(tc commandTestCase) { testCommand(t, tc) }
+
+	f(commandTestCase{
+		name: "valid config",
+		args: []string{"config", "lint", "-c", config},
+	})
+
+	f(commandTestCase{
+		name:   "errors found",
+		args:   []string{"config", "lint", config, broken},
+		code:   1,
+		output: []string{broken + ":1:"},
+	})
+
+	f(commandTestCase{
+		name:   "warnings only",
+		args:   []string{"config", "lint", unanchored},
+		output: []string{unanchored + ":6:5: warning:"},
+	})
+
+	f(commandTestCase{
+		name:   "warnings in strict mode",
+		args:   []string{"config", "lint", "--strict", unanchored},
+		code:   1,
+		output: []string{unanchored + ":6:5: warning:"},
+	})
+
+	f(commandTestCase{
+		name:   "missing file",
+		args:   []string{"config", "lint", config + ".missing"},
+		code:   1,
+		output: []string{config + ".missing: error:"},
+	})
+
+	f(commandTestCase{
+		name: "invalid flag",
+		args: []string{"config", "lint", "--unknown-flag"},
+		code: 1,
+	})
+}
+
+func TestNoticesCommand(t *testing.T) {
+	config := prepareConfig(t)
+
+	f := func(tc commandTestCase) { testCommand(t, tc) }
+
+	f(commandTestCase{
+		name:   "markdown",
+		stdin:  allowedGoMod,
+		args:   []string{"notices", "-c", config},
+		output: []string{"example.com/mit", "Copyright (c) 2020 Test Author"},
+	})
+
+	f(commandTestCase{
+		name:  "unknown format",
+		stdin: allowedGoMod,
+		args:  []string{"notices", "-c", config, "-f", "pdf"},
+		code:  1,
+	})
+
+	f(commandTestCase{
+		name: "invalid flag",
+		args: []string{"notices", "--unknown-flag"},
+		code: 1,
+	})
+}
+
+func TestSBOMCommand(t *testing.T) {
+	config := prepareConfig(t)
+
+	f := func(tc commandTestCase) { testCommand(t, tc) }
+
+	f(commandTestCase{
+		name:   "spdx json",
+		stdin:  allowedGoMod,
+		args:   []string{"sbom", "-c", config, "--name", "app"},
+		output: []string{`"name": "app"`, `"licenseConcluded": "MIT"`},
+	})
+
+	f(commandTestCase{
+		name:  "unknown format",
+		stdin: allowedGoMod,
+		args:  []string{"sbom", "-c", config, "-f", "xml"},
+		code:  1,
+	})
+
+	f(commandTestCase{
+		name: "invalid flag",
+		args: []string{"sbom", "--unknown-flag"},
+		code: 1,
+	})
+}
+
+func TestCommands_outputFile(t *testing.T) {
+	config := prepareConfig(t)
+
+	f := func(command string, args []string, check func(t *testing.T, content []byte)) {
+		t.Run(command, func(t *testing.T) {
+			output := filepath.Join(filepath.Dir(config), command+".out")
+
+			code, stdout := runApp(t, allowedGoMod, append([]string{command, "-c", config, "-o", output}, args...)...)
+			assert.Equal(t, 0, code)
+			assert.Empty(t, stdout, "output must be written to file only")
+
+			content, err := ioutil.ReadFile(output)
+			require.NoError(t, err)
+			check(t, content)
+		})
+	}
+
+	f("check", []string{"-f", "json"}, func(t *testing.T, content []byte) {
+		assert.True(t, json.Valid(content), "json report expected")
+		assert.Contains(t, string(content), `"example.com/mit"`)
+	})
+
+	f("notices", []string{"-f", "text"}, func(t *testing.T, content []byte) {
+		assert.Contains(t, string(content), "example.com/mit")
+	})
+
+	f("sbom", []string{"-f", "cyclonedx-json"}, func(t *testing.T, content []byte) {
+		assert.True(t, json.Valid(content), "json document expected")
+		assert.Contains(t, string(content), `"CycloneDX"`)
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/cmd/licensevalidator/app"
+	"github.com/xakep666/licensevalidator/pkg/report"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/urfave/cli/v2"
+)
+
+var explainFormatFlag = cli.StringFlag{
+	Name:    "format",
+	Aliases: []string{"f"},
+	Usage:   fmt.Sprintf("Output format (%s, %s)", report.FormatTable, report.FormatJSON),
+	Value:   string(report.FormatTable),
+}
+
+var explainOut io.Writer = os.Stdout // for mocking
+
+func ExplainCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "explain",
+		Usage:     "Shows how validation decision for module was made",
+		ArgsUsage: "module@version",
+		Description: "Validates single module and prints every step made: translations, cache lookups, license resolvers results " +
+			"with detection confidence, evaluated rules and final outcome.\n" +
+			fmt.Sprintf("Exit codes are the same as for check command: %d if module denied, %d if validation failed and %d on other errors.",
+				checkExitDenied, checkExitErrors, checkExitFailed),
+		Flags: []cli.Flag{
+			&configFileFlag,
+			&explainFormatFlag,
+		},
+		Action:       explainAction,
+		OnUsageError: exitOnUsageError(checkExitFailed),
+	}
+}
+
+func explainAction(ctx *cli.Context) error {
+	format := report.Format(ctx.String(explainFormatFlag.Name))
+	if format != report.FormatTable && format != report.FormatJSON {
+		return cli.Exit(fmt.Sprintf("Unknown output format %s", format), checkExitFailed)
+	}
+
+	m, err := parseModuleArg(ctx.Args().First())
+	if err != nil {
+		return cli.Exit(err, checkExitFailed)
+	}
+
+	cfg, err := app.ConfigFromFile(ctx.Path(configFileFlag.Name))
+	if err != nil {
+		return cli.Exit(err, checkExitFailed)
+	}
+
+	offline, err := app.NewOffline(cfg)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Failed to init validator: %s", err), checkExitFailed)
+	}
+
+	explanation := offline.Explain(ctx.Context, m)
+
+	if err := report.WriteExplanation(explainOut, explanation, format); err != nil {
+		return cli.Exit(fmt.Sprintf("Explanation write failed: %s", err), checkExitFailed)
+	}
+
+	switch explanation.Outcome {
+	case validation.OutcomeDenied:
+		return cli.Exit("", checkExitDenied)
+	case validation.OutcomeError:
+		return cli.Exit("", checkExitErrors)
+	default:
+		return nil
+	}
+}
+
+func parseModuleArg(arg string) (validation.Module, error) {
+	idx := strings.LastIndex(arg, "@")
+	if idx <= 0 {
+		return validation.Module{}, fmt.Errorf("module must be passed as module@version, got %q", arg)
+	}
+
+	version, err := semver.NewVersion(arg[idx+1:])
+	if err != nil {
+		return validation.Module{}, fmt.Errorf("invalid module version: %w", err)
+	}
+
+	return validation.Module{Name: arg[:idx], Version: version}, nil
+}
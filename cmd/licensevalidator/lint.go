@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/xakep666/licensevalidator/cmd/licensevalidator/app"
+
+	"github.com/urfave/cli/v2"
+)
+
+var lintStrictFlag = cli.BoolFlag{
+	Name:  "strict",
+	Usage: "Fail on warnings too",
+}
+
+var lintOut io.Writer = os.Stdout // for mocking
+
+func ConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Config file utilities",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "lint",
+				Usage:     "Checks config file for mistakes",
+				ArgsUsage: "[config files...]",
+				Description: "Reports unknown keys, unknown licenses, overlapping and shadowed rules, unanchored regular expressions " +
+					"and incomplete sections in form 'file:line:col: severity: message'.\n" +
+					"Config file from --config flag is checked if no files given. Exit code is 1 if errors found.",
+				Flags: []cli.Flag{
+					&configFileFlag,
+					&lintStrictFlag,
+				},
+				Action:       lintAction,
+				OnUsageError: exitOnUsageError(1),
+			},
+		},
+	}
+}
+
+func lintAction(ctx *cli.Context) error {
+	files := ctx.Args().Slice()
+	if len(files) == 0 {
+		files = []string{ctx.Path(configFileFlag.Name)}
+	}
+
+	failed := false
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			_, _ = fmt.Fprintf(lintOut, "%s: error: %s\n", file, err)
+			failed = true
+			continue
+		}
+
+		issues, err := app.LintConfig(data)
+		if err != nil {
+			_, _ = fmt.Fprintf(lintOut, "%s: error: %s\n", file, err)
+			failed = true
+			continue
+		}
+
+		for _, issue := range issues {
+			_, _ = fmt.Fprintf(lintOut, "%s:%s\n", file, issue.String())
+
+			if issue.Severity == app.LintError || ctx.Bool(lintStrictFlag.Name) {
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		return cli.Exit("", 1)
+	}
+
+	return nil
+}
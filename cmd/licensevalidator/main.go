@@ -38,6 +38,11 @@ func main() {
 		},
 		Commands: []*cli.Command{
 			ConfigSampleCommand(),
+			CheckCommand(),
+			ExplainCommand(),
+			NoticesCommand(),
+			SBOMCommand(),
+			ConfigCommand(),
 		},
 	}
 
@@ -45,6 +50,14 @@ func main() {
 	cli.HandleExitCoder(a.Run(args))
 }
 
+// exitOnUsageError makes command fail with exit code on invalid flags.
+// Otherwise help is printed and process exits with zero code.
+func exitOnUsageError(code int) cli.OnUsageErrorFunc {
+	return func(_ *cli.Context, err error, _ bool) error {
+		return cli.Exit(fmt.Sprintf("Incorrect usage: %s", err), code)
+	}
+}
+
 func action(ctx *cli.Context) error {
 	fmt.Print(`
 ██╗     ██╗ ██████╗███████╗███╗   ██╗███████╗███████╗                 
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/cmd/licensevalidator/app"
+	"github.com/xakep666/licensevalidator/pkg/modlist"
+	"github.com/xakep666/licensevalidator/pkg/notice"
+
+	"github.com/urfave/cli/v2"
+)
+
+var noticesFormatFlag = cli.StringFlag{
+	Name:    "format",
+	Aliases: []string{"f"},
+	Usage:   fmt.Sprintf("Output format (%s)", joinNoticeFormats(notice.Formats)),
+	Value:   string(notice.FormatMarkdown),
+}
+
+var noticesOut io.Writer = os.Stdout // for mocking
+
+func NoticesCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "notices",
+		Usage:     "Generates third-party attribution document",
+		ArgsUsage: "[go.mod | go.sum | file with \"go list -m -json all\" output | -]",
+		Description: "Downloads all modules from input through goproxy and writes their license texts, NOTICE files " +
+			"and copyright lines to a single document. Input is read from stdin if not given.",
+		Flags: []cli.Flag{
+			&configFileFlag,
+			&noticesFormatFlag,
+			&checkInputFormatFlag,
+			&checkOutputFlag,
+		},
+		Action:       noticesAction,
+		OnUsageError: exitOnUsageError(1),
+	}
+}
+
+func noticesAction(ctx *cli.Context) error {
+	format := notice.Format(ctx.String(noticesFormatFlag.Name))
+	if !knownNoticeFormat(format) {
+		return cli.Exit(fmt.Sprintf("Unknown output format %s", format), 1)
+	}
+
+	data, err := readCheckInput(ctx.Args().First())
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Input read failed: %s", err), 1)
+	}
+
+	modules, err := modlist.Parse(data, modlist.Format(ctx.String(checkInputFormatFlag.Name)))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Input parse failed: %s", err), 1)
+	}
+
+	cfg, err := app.ConfigFromFile(ctx.Path(configFileFlag.Name))
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	offline, err := app.NewOffline(cfg)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Failed to init validator: %s", err), 1)
+	}
+
+	doc, err := offline.Notices(ctx.Context, modules)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Notices collection failed: %s", err), 1)
+	}
+
+	out := noticesOut
+	if output := ctx.Path(checkOutputFlag.Name); output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Output file create failed: %s", err), 1)
+		}
+
+		defer f.Close()
+
+		out = f
+	}
+
+	if err := notice.Write(out, doc, format); err != nil {
+		return cli.Exit(fmt.Sprintf("Document write failed: %s", err), 1)
+	}
+
+	for _, entry := range doc.Entries {
+		if entry.Error != "" {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: %s@%s: %s\n", entry.Module, entry.Version, entry.Error)
+		}
+	}
+
+	return nil
+}
+
+func knownNoticeFormat(format notice.Format) bool {
+	for _, item := range notice.Formats {
+		if item == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinNoticeFormats(formats []notice.Format) string {
+	items := make([]string, 0, len(formats))
+	for _, format := range formats {
+		items = append(items, string(format))
+	}
+
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/cmd/licensevalidator/app"
+	"github.com/xakep666/licensevalidator/pkg/modlist"
+	"github.com/xakep666/licensevalidator/pkg/sbom"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	sbomFormatFlag = cli.StringFlag{
+		Name:    "format",
+		Aliases: []string{"f"},
+		Usage:   fmt.Sprintf("Output format (%s)", joinSBOMFormats(sbom.Formats)),
+		Value:   string(sbom.FormatSPDXJSON),
+	}
+
+	sbomNameFlag = cli.StringFlag{
+		Name:  "name",
+		Usage: "Document name. Input file name is used if not set",
+	}
+)
+
+var sbomOut io.Writer = os.Stdout // for mocking
+
+func SBOMCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "sbom",
+		Usage:     "Generates license-annotated software bill of materials",
+		ArgsUsage: "[go.mod | go.sum | file with \"go list -m -json all\" output | -]",
+		Description: "Resolves licenses of all modules from input with configured resolvers and writes SPDX 2.3 (JSON or tag-value) " +
+			"or CycloneDX 1.5 JSON document. Resolver and detection confidence are added as annotations. " +
+			"Input is read from stdin if not given.",
+		Flags: []cli.Flag{
+			&configFileFlag,
+			&sbomFormatFlag,
+			&sbomNameFlag,
+			&checkInputFormatFlag,
+			&checkOutputFlag,
+		},
+		Action:       sbomAction,
+		OnUsageError: exitOnUsageError(1),
+	}
+}
+
+func sbomAction(ctx *cli.Context) error {
+	format := sbom.Format(ctx.String(sbomFormatFlag.Name))
+	if _, ok := sbom.ContentTypes[format]; !ok {
+		return cli.Exit(fmt.Sprintf("Unknown output format %s", format), 1)
+	}
+
+	source := ctx.Args().First()
+
+	data, err := readCheckInput(source)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Input read failed: %s", err), 1)
+	}
+
+	modules, err := modlist.Parse(data, modlist.Format(ctx.String(checkInputFormatFlag.Name)))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Input parse failed: %s", err), 1)
+	}
+
+	name := ctx.String(sbomNameFlag.Name)
+	switch {
+	case name != "":
+		// pass
+	case source == "" || source == "-":
+		name = "modules"
+	default:
+		name = filepath.Base(source)
+	}
+
+	cfg, err := app.ConfigFromFile(ctx.Path(configFileFlag.Name))
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	offline, err := app.NewOffline(cfg)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Failed to init validator: %s", err), 1)
+	}
+
+	bom, err := offline.SBOM(ctx.Context, name, modules)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("SBOM generation failed: %s", err), 1)
+	}
+
+	out := sbomOut
+	if output := ctx.Path(checkOutputFlag.Name); output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Output file create failed: %s", err), 1)
+		}
+
+		defer f.Close()
+
+		out = f
+	}
+
+	if err := sbom.Write(out, bom, format); err != nil {
+		return cli.Exit(fmt.Sprintf("SBOM write failed: %s", err), 1)
+	}
+
+	return nil
+}
+
+func joinSBOMFormats(formats []sbom.Format) string {
+	items := make([]string, 0, len(formats))
+	for _, format := range formats {
+		items = append(items, string(format))
+	}
+
+	return strings.Join(items, ", ")
+}
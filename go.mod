@@ -21,6 +21,7 @@ require (
 	go.opentelemetry.io/otel/exporters/trace/jaeger v0.4.3
 	go.opentelemetry.io/otel/exporters/trace/zipkin v0.4.3
 	go.uber.org/zap v1.15.0
+	golang.org/x/mod v0.3.0
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
 	golang.org/x/sync v0.0.0-20201207232520-09787c993a3a
 	gopkg.in/src-d/go-license-detector.v3 v3.1.0
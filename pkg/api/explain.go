@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ExplainHandler validates single module passed in "module" and "version" query parameters
+// and responds with validation.Explanation describing every step made during validation.
+// Response code is 200 regardless of validation outcome.
+func ExplainHandler(explainer *validation.Explainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+
+		name := query.Get("module")
+		if name == "" {
+			http.Error(w, "module is required", http.StatusBadRequest)
+			return
+		}
+
+		version, err := semver.NewVersion(query.Get("version"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid version: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		explanation := explainer.Explain(r.Context(), validation.Module{Name: name, Version: version})
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(explanation)
+	}
+}
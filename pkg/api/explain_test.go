@@ -0,0 +1,137 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/api"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestExplainHandler(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name               string
+		Request            *http.Request
+		ValidatorMockSetup func(m *validation.ValidatorMock)
+		ExpectedCode       int
+		ExpectedBody       string
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			var validatorMock validation.ValidatorMock
+			if tc.ValidatorMockSetup != nil {
+				tc.ValidatorMockSetup(&validatorMock)
+			}
+
+			defer validatorMock.AssertExpectations(t)
+
+			rec := httptest.NewRecorder()
+
+			api.ExplainHandler(validation.NewExplainer(zaptest.NewLogger(t), validation.ExplainerParams{
+				Validator: &validatorMock,
+				HelpURL:   "https://example.com/policy",
+			}))(rec, tc.Request)
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+			if tc.ExpectedBody != "" {
+				assert.JSONEq(t, tc.ExpectedBody, rec.Body.String())
+			}
+		})
+	}
+
+	module := validation.Module{Name: "github.com/stretchr/testify", Version: semver.MustParse("v1.5.1")}
+
+	f(testCase{
+		Name:    "allowed",
+		Request: httptest.NewRequest(http.MethodGet, "/api/v1/explain?module=github.com/stretchr/testify&version=v1.5.1", nil),
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Run(func(args mock.Arguments) {
+				validation.RecordStep(args.Get(0).(context.Context), validation.Step{
+					Stage:     validation.StageResolve,
+					Component: "github",
+					Message:   "github.com/stretchr/testify: license resolved",
+					License:   "MIT",
+				})
+			}).Return(nil).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{
+			"module": "github.com/stretchr/testify",
+			"version": "v1.5.1",
+			"steps": [{
+				"stage": "resolve",
+				"component": "github",
+				"message": "github.com/stretchr/testify: license resolved",
+				"license": "MIT"
+			}],
+			"outcome": "allowed"
+		}`,
+	})
+
+	f(testCase{
+		Name:    "denied",
+		Request: httptest.NewRequest(http.MethodGet, "/api/v1/explain?module=github.com/stretchr/testify&version=v1.5.1", nil),
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(validation.ErrUnknownLicense).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{
+			"module": "github.com/stretchr/testify",
+			"version": "v1.5.1",
+			"steps": [],
+			"outcome": "denied",
+			"denial": {
+				"module": "github.com/stretchr/testify",
+				"version": "v1.5.1",
+				"rule": "unknown_license_action: deny",
+				"reason": "unknown_license",
+				"message": "license of github.com/stretchr/testify@v1.5.1 can't be determined",
+				"help_url": "https://example.com/policy"
+			}
+		}`,
+	})
+
+	f(testCase{
+		Name:    "validation error",
+		Request: httptest.NewRequest(http.MethodGet, "/api/v1/explain?module=github.com/stretchr/testify&version=v1.5.1", nil),
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(errors.New("test error")).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{
+			"module": "github.com/stretchr/testify",
+			"version": "v1.5.1",
+			"steps": [],
+			"outcome": "error",
+			"error": "test error"
+		}`,
+	})
+
+	f(testCase{
+		Name:         "no module",
+		Request:      httptest.NewRequest(http.MethodGet, "/api/v1/explain?version=v1.5.1", nil),
+		ExpectedCode: http.StatusBadRequest,
+	})
+
+	f(testCase{
+		Name:         "invalid version",
+		Request:      httptest.NewRequest(http.MethodGet, "/api/v1/explain?module=github.com/stretchr/testify&version=latest", nil),
+		ExpectedCode: http.StatusBadRequest,
+	})
+
+	f(testCase{
+		Name:         "wrong method",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/explain", nil),
+		ExpectedCode: http.StatusMethodNotAllowed,
+	})
+}
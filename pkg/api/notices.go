@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/xakep666/licensevalidator/pkg/modlist"
+	"github.com/xakep666/licensevalidator/pkg/notice"
+)
+
+var noticeContentTypes = map[notice.Format]string{
+	notice.FormatMarkdown: "text/markdown; charset=utf-8",
+	notice.FormatHTML:     "text/html; charset=utf-8",
+	notice.FormatText:     "text/plain; charset=utf-8",
+}
+
+// NoticesHandler generates attribution document for set of modules.
+// Body is the same as for ValidateHandler. Document format is set by "output" query parameter
+// (see notice.Format), collected data is returned as JSON if it's "json" or not set.
+func NoticesHandler(collector *notice.Collector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		output := r.URL.Query().Get("output")
+		contentType, ok := noticeContentTypes[notice.Format(output)]
+		if !ok && output != "" && output != "json" {
+			http.Error(w, fmt.Sprintf("unknown output format %s", output), http.StatusBadRequest)
+			return
+		}
+
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("request read failed: %s", err), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		modules, err := modlist.Parse(data, modlist.Format(r.URL.Query().Get("format")))
+		if errors.Is(err, modlist.ErrUnknownFormat) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("module list parse failed: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		doc, err := collector.Collect(r.Context(), modules)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("notices collection failed: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(doc)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		_ = notice.Write(w, doc, notice.Format(output))
+	}
+}
@@ -0,0 +1,86 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/api"
+	"github.com/xakep666/licensevalidator/pkg/goproxy"
+	"github.com/xakep666/licensevalidator/pkg/notice"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+func TestNoticesHandler(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name                string
+		Request             *http.Request
+		ExpectedCode        int
+		ExpectedContentType string
+		ExpectedBody        string
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			api.NoticesHandler(notice.NewCollector(zaptest.NewLogger(t), notice.CollectorParams{
+				Opener: notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+					return nil, goproxy.ErrModuleNotFound
+				}),
+			}))(rec, tc.Request)
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+			if tc.ExpectedContentType != "" {
+				assert.Equal(t, tc.ExpectedContentType, rec.Header().Get("Content-Type"))
+			}
+
+			if tc.ExpectedBody != "" {
+				assert.Equal(t, tc.ExpectedBody, rec.Body.String())
+			}
+		})
+	}
+
+	body := "github.com/stretchr/testify v1.5.1 h1:nOGnQDM7FYENwehXlg/kFVnos3rEvtKTjRvOWSzb6H4=\n"
+
+	f(testCase{
+		Name:                "json",
+		Request:             httptest.NewRequest(http.MethodPost, "/api/v1/notices", strings.NewReader(body)),
+		ExpectedCode:        http.StatusOK,
+		ExpectedContentType: "application/json",
+		ExpectedBody: `{"entries":[{"module":"github.com/stretchr/testify","version":"v1.5.1",` +
+			`"error":"module files open failed: module not found"}]}` + "\n",
+	})
+
+	f(testCase{
+		Name:                "text",
+		Request:             httptest.NewRequest(http.MethodPost, "/api/v1/notices?output=text", strings.NewReader(body)),
+		ExpectedCode:        http.StatusOK,
+		ExpectedContentType: "text/plain; charset=utf-8",
+	})
+
+	f(testCase{
+		Name:         "unknown output",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/notices?output=pdf", strings.NewReader(body)),
+		ExpectedCode: http.StatusBadRequest,
+	})
+
+	f(testCase{
+		Name:         "unknown input format",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/notices?format=yaml", strings.NewReader(body)),
+		ExpectedCode: http.StatusUnsupportedMediaType,
+	})
+
+	f(testCase{
+		Name:         "wrong method",
+		Request:      httptest.NewRequest(http.MethodGet, "/api/v1/notices", nil),
+		ExpectedCode: http.StatusMethodNotAllowed,
+	})
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/xakep666/licensevalidator/pkg/modlist"
+	"github.com/xakep666/licensevalidator/pkg/sbom"
+)
+
+// SBOMHandler generates license-annotated SBOM for set of modules.
+// Body is the same as for ValidateHandler. Document format is set by "output" query parameter
+// (see sbom.Format, default is SPDX JSON), document name is set by "name" query parameter.
+func SBOMHandler(generator *sbom.Generator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+
+		output := sbom.Format(query.Get("output"))
+		if output == "" {
+			output = sbom.FormatSPDXJSON
+		}
+
+		contentType, ok := sbom.ContentTypes[output]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown output format %s", output), http.StatusBadRequest)
+			return
+		}
+
+		name := query.Get("name")
+		if name == "" {
+			name = "modules"
+		}
+
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("request read failed: %s", err), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		modules, err := modlist.Parse(data, modlist.Format(query.Get("format")))
+		if errors.Is(err, modlist.ErrUnknownFormat) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("module list parse failed: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		bom, err := generator.Generate(r.Context(), name, modules)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("sbom generation failed: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		_ = sbom.Write(w, bom, output)
+	}
+}
+
+// ValidateSBOMHandler validates go modules listed in SPDX or CycloneDX document and responds with batch.Report.
+// Licenses are taken from document by default, "licenses" query parameter set to "resolve" makes them resolved
+// with configured resolvers (see sbom.LicenseSource).
+func ValidateSBOMHandler(checker *sbom.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		source := sbom.LicenseSource(r.URL.Query().Get("licenses"))
+		if !knownLicenseSource(source) {
+			http.Error(w, fmt.Sprintf("unknown license source %s", source), http.StatusBadRequest)
+			return
+		}
+
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("request read failed: %s", err), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		declared, err := sbom.Parse(data)
+		if errors.Is(err, sbom.ErrUnknownDocument) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("sbom parse failed: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		report, err := checker.Check(r.Context(), declared, source)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("validation failed: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
+
+func knownLicenseSource(source sbom.LicenseSource) bool {
+	if source == "" {
+		return true
+	}
+
+	for _, item := range sbom.LicenseSources {
+		if item == source {
+			return true
+		}
+	}
+
+	return false
+}
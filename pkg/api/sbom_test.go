@@ -0,0 +1,202 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/api"
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/sbom"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSBOMHandler(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name                string
+		Request             *http.Request
+		ResolverMockSetup   func(m *validation.LicenseResolverMock)
+		ExpectedCode        int
+		ExpectedContentType string
+		Check               func(t *testing.T, body string)
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			var resolverMock validation.LicenseResolverMock
+			if tc.ResolverMockSetup != nil {
+				tc.ResolverMockSetup(&resolverMock)
+			}
+
+			defer resolverMock.AssertExpectations(t)
+
+			rec := httptest.NewRecorder()
+
+			api.SBOMHandler(sbom.NewGenerator(zaptest.NewLogger(t), sbom.GeneratorParams{
+				LicenseResolver: &resolverMock,
+			}))(rec, tc.Request)
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+			if tc.ExpectedContentType != "" {
+				assert.Equal(t, tc.ExpectedContentType, rec.Header().Get("Content-Type"))
+			}
+
+			if tc.Check != nil {
+				tc.Check(t, rec.Body.String())
+			}
+		})
+	}
+
+	body := "github.com/stretchr/testify v1.5.1 h1:nOGnQDM7FYENwehXlg/kFVnos3rEvtKTjRvOWSzb6H4=\n"
+	module := validation.Module{Name: "github.com/stretchr/testify", Version: semver.MustParse("v1.5.1")}
+
+	f(testCase{
+		Name:    "spdx by default",
+		Request: httptest.NewRequest(http.MethodPost, "/api/v1/sbom?name=myproject", strings.NewReader(body)),
+		ResolverMockSetup: func(m *validation.LicenseResolverMock) {
+			m.On("ResolveLicense", mock.Anything, module).Return(validation.License{Name: "MIT License", SPDXID: "MIT"}, nil).Once()
+		},
+		ExpectedCode:        http.StatusOK,
+		ExpectedContentType: "application/spdx+json",
+		Check: func(t *testing.T, body string) {
+			var doc struct {
+				Name     string `json:"name"`
+				Packages []struct {
+					Name             string `json:"name"`
+					LicenseConcluded string `json:"licenseConcluded"`
+				} `json:"packages"`
+			}
+
+			if assert.NoError(t, json.Unmarshal([]byte(body), &doc)) {
+				assert.Equal(t, "myproject", doc.Name)
+				if assert.Len(t, doc.Packages, 1) {
+					assert.Equal(t, "github.com/stretchr/testify", doc.Packages[0].Name)
+					assert.Equal(t, "MIT", doc.Packages[0].LicenseConcluded)
+				}
+			}
+		},
+	})
+
+	f(testCase{
+		Name:    "cyclonedx",
+		Request: httptest.NewRequest(http.MethodPost, "/api/v1/sbom?output=cyclonedx-json", strings.NewReader(body)),
+		ResolverMockSetup: func(m *validation.LicenseResolverMock) {
+			m.On("ResolveLicense", mock.Anything, module).Return(validation.License{}, validation.ErrUnknownLicense).Once()
+		},
+		ExpectedCode:        http.StatusOK,
+		ExpectedContentType: "application/vnd.cyclonedx+json; version=1.5",
+		Check: func(t *testing.T, body string) {
+			assert.Contains(t, body, `"purl": "pkg:golang/github.com/stretchr/testify@v1.5.1"`)
+		},
+	})
+
+	f(testCase{
+		Name:         "unknown output",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/sbom?output=swid", strings.NewReader(body)),
+		ExpectedCode: http.StatusBadRequest,
+	})
+
+	f(testCase{
+		Name:         "wrong method",
+		Request:      httptest.NewRequest(http.MethodGet, "/api/v1/sbom", nil),
+		ExpectedCode: http.StatusMethodNotAllowed,
+	})
+}
+
+func TestValidateSBOMHandler(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name               string
+		Request            *http.Request
+		ValidatorMockSetup func(m *validation.ValidatorMock)
+		ExpectedCode       int
+		Check              func(t *testing.T, report *batch.Report)
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			var validatorMock validation.ValidatorMock
+			if tc.ValidatorMockSetup != nil {
+				tc.ValidatorMockSetup(&validatorMock)
+			}
+
+			defer validatorMock.AssertExpectations(t)
+
+			rec := httptest.NewRecorder()
+
+			api.ValidateSBOMHandler(sbom.NewChecker(zaptest.NewLogger(t), sbom.CheckerParams{
+				Validator: &validatorMock,
+				DeclaredValidator: func(resolver validation.LicenseResolver) validation.Validator {
+					return validation.NewRuleSetValidator(zaptest.NewLogger(t), validation.RuleSetValidatorParams{
+						Translator:      &validation.ChainedTranslator{},
+						LicenseResolver: resolver,
+						RuleSet: validation.RuleSet{
+							DeniedLicenses: []validation.License{{SPDXID: "GPL-3.0"}},
+						},
+					})
+				},
+			}))(rec, tc.Request)
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+			if tc.Check != nil {
+				var report batch.Report
+				if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report)) {
+					tc.Check(t, &report)
+				}
+			}
+		})
+	}
+
+	body := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"components": [{
+			"type": "library",
+			"name": "github.com/stretchr/testify",
+			"purl": "pkg:golang/github.com/stretchr/testify@v1.5.1",
+			"licenses": [{"license": {"id": "GPL-3.0"}}]
+		}]
+	}`
+	module := validation.Module{Name: "github.com/stretchr/testify", Version: semver.MustParse("v1.5.1")}
+
+	f(testCase{
+		Name:         "declared licenses by default",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/validate/sbom", strings.NewReader(body)),
+		ExpectedCode: http.StatusOK,
+		Check: func(t *testing.T, report *batch.Report) {
+			assert.Equal(t, batch.StatusDenied, report.Verdict)
+		},
+	})
+
+	f(testCase{
+		Name:    "resolved licenses",
+		Request: httptest.NewRequest(http.MethodPost, "/api/v1/validate/sbom?licenses=resolve", strings.NewReader(body)),
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(nil).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		Check: func(t *testing.T, report *batch.Report) {
+			assert.Equal(t, batch.StatusAllowed, report.Verdict)
+		},
+	})
+
+	f(testCase{
+		Name:         "unknown license source",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/validate/sbom?licenses=guess", strings.NewReader(body)),
+		ExpectedCode: http.StatusBadRequest,
+	})
+
+	f(testCase{
+		Name:         "not a sbom",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/validate/sbom", strings.NewReader("module test\n")),
+		ExpectedCode: http.StatusUnsupportedMediaType,
+	})
+}
@@ -0,0 +1,56 @@
+// Package api contains handlers of licensevalidator HTTP API intended for CI and tooling
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/modlist"
+)
+
+// MaxRequestSize is a maximum accepted request body size
+const MaxRequestSize = 10 << 20
+
+// ValidateHandler validates set of modules and responds with batch.Report.
+// Body may contain JSON module list, go.mod, go.sum or "go list -m -json all" output.
+// Format is detected by content but can be set explicitly with "format" query parameter (see modlist.Format).
+func ValidateHandler(validator *batch.Validator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("request read failed: %s", err), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		modules, err := modlist.Parse(data, modlist.Format(r.URL.Query().Get("format")))
+		if errors.Is(err, modlist.ErrUnknownFormat) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("module list parse failed: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		report, err := validator.Validate(r.Context(), modules)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("validation failed: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
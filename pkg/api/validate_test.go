@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/api"
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestValidateHandler(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name               string
+		Request            *http.Request
+		ValidatorMockSetup func(m *validation.ValidatorMock)
+		ExpectedCode       int
+		ExpectedBody       string
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			var validatorMock validation.ValidatorMock
+			if tc.ValidatorMockSetup != nil {
+				tc.ValidatorMockSetup(&validatorMock)
+			}
+
+			defer validatorMock.AssertExpectations(t)
+
+			rec := httptest.NewRecorder()
+
+			api.ValidateHandler(batch.NewValidator(zaptest.NewLogger(t), batch.ValidatorParams{
+				Validator: &validatorMock,
+			}))(rec, tc.Request)
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+			if tc.ExpectedBody != "" {
+				assert.JSONEq(t, tc.ExpectedBody, rec.Body.String())
+			}
+		})
+	}
+
+	module := validation.Module{Name: "github.com/stretchr/testify", Version: semver.MustParse("v1.5.1")}
+
+	f(testCase{
+		Name: "go.sum",
+		Request: httptest.NewRequest(http.MethodPost, "/api/v1/validate", strings.NewReader(
+			"github.com/stretchr/testify v1.5.1 h1:nOGnQDM7FYENwehXlg/kFVnos3rEvtKTjRvOWSzb6H4=\n",
+		)),
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(nil).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{
+			"verdict": "allowed",
+			"summary": {"total": 1, "allowed": 1, "denied": 0, "errors": 0},
+			"modules": [{"module": "github.com/stretchr/testify", "version": "v1.5.1", "status": "allowed"}]
+		}`,
+	})
+
+	f(testCase{
+		Name: "explicit format",
+		Request: httptest.NewRequest(http.MethodPost, "/api/v1/validate?format=gomod", strings.NewReader(
+			"require github.com/stretchr/testify v1.5.1\n",
+		)),
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(validation.ErrUnknownLicense).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{
+			"verdict": "denied",
+			"summary": {"total": 1, "allowed": 0, "denied": 1, "errors": 0},
+			"modules": [{
+				"module": "github.com/stretchr/testify",
+				"version": "v1.5.1",
+				"status": "denied",
+				"denial": {
+					"module": "github.com/stretchr/testify",
+					"version": "v1.5.1",
+					"rule": "unknown_license_action: deny",
+					"reason": "unknown_license",
+					"message": "license of github.com/stretchr/testify@v1.5.1 can't be determined"
+				}
+			}]
+		}`,
+	})
+
+	f(testCase{
+		Name:         "unknown format",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/validate", strings.NewReader("some text")),
+		ExpectedCode: http.StatusUnsupportedMediaType,
+	})
+
+	f(testCase{
+		Name:         "malformed list",
+		Request:      httptest.NewRequest(http.MethodPost, "/api/v1/validate", strings.NewReader(`[{"Module": "a", "Version": "bla"}]`)),
+		ExpectedCode: http.StatusBadRequest,
+	})
+
+	f(testCase{
+		Name:         "bad method",
+		Request:      httptest.NewRequest(http.MethodGet, "/api/v1/validate", nil),
+		ExpectedCode: http.StatusMethodNotAllowed,
+	})
+}
@@ -0,0 +1,116 @@
+// Package apifiler contains filesystem for src-d license scanner over repository tree of VCS hosting.
+// Files are read through hosting API without cloning, hosting specific requests are made by Repository.
+package apifiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// ErrNotFound should be wrapped by Repository errors if file or directory doesn't exist
+var ErrNotFound = fmt.Errorf("not found")
+
+// MaxFileSize limits size of read files
+const MaxFileSize = 10 << 20
+
+// Repository provides access to repository tree at fixed revision.
+// Paths are slash-separated and relative to repository root, root is an empty path.
+type Repository interface {
+	// OpenFile opens file for reading. Returned error wraps ErrNotFound if file doesn't exist.
+	OpenFile(ctx context.Context, p string) (io.ReadCloser, error)
+
+	// ListDir lists directory. Returned error wraps ErrNotFound if directory doesn't exist or it's not a directory.
+	ListDir(ctx context.Context, p string) ([]filer.File, error)
+}
+
+// Filer is an implementation of filesystem for src-d license scanner over Repository
+type Filer struct {
+	ctx  context.Context
+	repo Repository
+
+	// prefix is a directory inside repository which is used as filer root
+	prefix string
+}
+
+// New constructs filer over repository root
+func New(ctx context.Context, repo Repository) *Filer {
+	return &Filer{ctx: ctx, repo: repo}
+}
+
+func (af *Filer) fullPath(p string) string {
+	return strings.Trim(path.Join(af.prefix, p), "/")
+}
+
+func (af *Filer) ReadFile(p string) ([]byte, error) {
+	rc, err := af.repo.OpenFile(af.ctx, af.fullPath(p))
+	if errors.Is(err, ErrNotFound) {
+		return nil, &os.PathError{
+			Op:   "open",
+			Path: p,
+			Err:  os.ErrNotExist,
+		}
+	}
+
+	if err != nil {
+		return nil, &os.PathError{
+			Op:   "open",
+			Path: p,
+			Err:  err,
+		}
+	}
+
+	defer rc.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(rc, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("file %s read failed: %w", p, err)
+	}
+
+	if len(content) > MaxFileSize {
+		return nil, fmt.Errorf("file %s exceeds size limit", p)
+	}
+
+	return content, nil
+}
+
+// ReadDir lists directory. Returned error wraps ErrNotFound if directory doesn't exist.
+func (af *Filer) ReadDir(p string) ([]filer.File, error) {
+	files, err := af.repo.ListDir(af.ctx, af.fullPath(p))
+	if err != nil {
+		return nil, &os.PathError{
+			Op:   "readdir",
+			Path: p,
+			Err:  err,
+		}
+	}
+
+	return files, nil
+}
+
+func (af *Filer) Close() {}
+
+func (af *Filer) PathsAreAlwaysSlash() bool { return true }
+
+// Sub returns filer over sub directory
+func (af *Filer) Sub(dir string) *Filer {
+	return &Filer{ctx: af.ctx, repo: af.repo, prefix: af.fullPath(dir)}
+}
+
+// EscapePath escapes each element of slash-separated path
+func EscapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i := range parts {
+		parts[i] = url.PathEscape(parts[i])
+	}
+
+	return strings.Join(parts, "/")
+}
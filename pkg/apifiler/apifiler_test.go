@@ -0,0 +1,94 @@
+package apifiler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/apifiler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// mapRepository serves files from map by path, directories are listed by explicit entries
+type mapRepository struct {
+	files map[string]string
+	dirs  map[string][]filer.File
+}
+
+func (r *mapRepository) OpenFile(_ context.Context, p string) (io.ReadCloser, error) {
+	content, ok := r.files[p]
+	if !ok {
+		return nil, apifiler.ErrNotFound
+	}
+
+	return ioutil.NopCloser(strings.NewReader(content)), nil
+}
+
+func (r *mapRepository) ListDir(_ context.Context, p string) ([]filer.File, error) {
+	entries, ok := r.dirs[p]
+	if !ok {
+		return nil, fmt.Errorf("%w: not a directory", apifiler.ErrNotFound)
+	}
+
+	return entries, nil
+}
+
+func TestFiler(t *testing.T) {
+	t.Parallel()
+
+	repo := &mapRepository{
+		files: map[string]string{
+			"LICENSE":         "root license",
+			"sub/LICENSE":     "sub license",
+			"sub/big/LICENSE": strings.Repeat("a", apifiler.MaxFileSize+1),
+		},
+		dirs: map[string][]filer.File{
+			"":    {{Name: "LICENSE"}, {Name: "sub", IsDir: true}},
+			"sub": {{Name: "LICENSE"}, {Name: "big", IsDir: true}},
+		},
+	}
+
+	root := apifiler.New(context.Background(), repo)
+	assert.True(t, root.PathsAreAlwaysSlash())
+
+	content, err := root.ReadFile("LICENSE")
+	require.NoError(t, err)
+	assert.Equal(t, "root license", string(content))
+
+	entries, err := root.ReadDir("")
+	require.NoError(t, err)
+	assert.Equal(t, repo.dirs[""], entries)
+
+	sub := root.Sub("sub")
+
+	content, err = sub.ReadFile("LICENSE")
+	require.NoError(t, err)
+	assert.Equal(t, "sub license", string(content))
+
+	entries, err = sub.ReadDir("/")
+	require.NoError(t, err)
+	assert.Equal(t, repo.dirs["sub"], entries)
+
+	_, err = sub.ReadFile("README")
+	assert.True(t, os.IsNotExist(err), "unexpected error: %v", err)
+
+	_, err = sub.ReadFile("big/LICENSE")
+	assert.EqualError(t, err, "file big/LICENSE exceeds size limit")
+
+	_, err = sub.ReadDir("LICENSE")
+	assert.True(t, errors.Is(err, apifiler.ErrNotFound), "unexpected error: %v", err)
+}
+
+func TestEscapePath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "dir%20name/file%3F.txt", apifiler.EscapePath("dir name/file?.txt"))
+}
@@ -6,22 +6,20 @@ import (
 	"fmt"
 	"mime"
 	"net/http"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
 )
 
 // AdmissionHandler is a athens admission (validator) web hook handler
 // It calls internal validator to check if module can be used.
-func AdmissionHandler(validator Validator, forbiddenSources ...string) http.HandlerFunc {
+// Denial is returned as JSON if caller accepts it, otherwise short text message returned.
+// Callers source checks are performed by middlewares (see package netacl).
+func AdmissionHandler(validator Validator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 
-		// misconfiguration protection (when target goproxy configured as Athens calling this endpoint)
-		for _, fs := range forbiddenSources {
-			if fs == r.RemoteAddr || fs == r.Host {
-				http.Error(w, "Misconfiguration found, got request from forbidden source (target goproxy)", http.StatusInternalServerError)
-				return
-			}
-		}
-
 		if r.Method != http.MethodPost {
 			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
 			return
@@ -46,7 +44,7 @@ func AdmissionHandler(validator Validator, forbiddenSources ...string) http.Hand
 			return
 		}
 
-		// no version is ok (i.e. called for version listing)
+		// no version is ok (i.e. called for version listing, see ListAdmissionHandler)
 		if request.Version == nil {
 			return
 		}
@@ -57,7 +55,12 @@ func AdmissionHandler(validator Validator, forbiddenSources ...string) http.Hand
 		case errors.Is(err, nil):
 			// pass
 		case errors.As(err, &forbiddenErr):
-			http.Error(w, forbiddenErr.Error(), http.StatusForbidden)
+			denial := forbiddenErr.Denial
+			if denial.Message == "" {
+				denial.Message = forbiddenErr.Error()
+			}
+
+			validation.WriteDenial(w, r, http.StatusForbidden, denial)
 			return
 		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -65,3 +68,61 @@ func AdmissionHandler(validator Validator, forbiddenSources ...string) http.Hand
 		}
 	}
 }
+
+// ListAdmissionHandler is an admission handler variant for version list requests.
+// It responds with versions allowed by validator, so blacklisted versions and versions with denied license are dropped.
+// Concurrency limits number of simultaneous validations (see validation.FilterVersions).
+func ListAdmissionHandler(validator validation.Validator, concurrency int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if contentType != "application/json" {
+			http.Error(w, "unexpected content-type", http.StatusNotAcceptable)
+			return
+		}
+
+		var request ListValidationRequest
+
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("request parse failed: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		if request.Module == "" {
+			http.Error(w, "no module name", http.StatusBadRequest)
+			return
+		}
+
+		versions := make([]*semver.Version, 0, len(request.Versions))
+		for _, item := range request.Versions {
+			version, err := semver.NewVersion(item)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid version %s: %s", item, err), http.StatusBadRequest)
+				return
+			}
+
+			versions = append(versions, version)
+		}
+
+		allowed, err := validation.FilterVersions(r.Context(), validator, request.Module, versions, concurrency)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response := ListValidationResponse{Module: request.Module, Versions: make([]string, 0, len(allowed))}
+		for _, version := range allowed {
+			response.Versions = append(response.Versions, version.Original())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&response)
+	}
+}
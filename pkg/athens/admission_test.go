@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/xakep666/licensevalidator/pkg/athens"
+	"github.com/xakep666/licensevalidator/pkg/validation"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/assert"
@@ -86,6 +87,55 @@ func TestAdmissionHandler(t *testing.T) {
 		ExpectedBody: "module forbidden: module in blacklist",
 	})
 
+	f(testCase{
+		Name:    "forbidden module with denial",
+		Request: makeRequest( /*language=json*/ `{"Module":  "test-mod", "Version":  "v1.0.0"}`),
+		ValidatorMockSetup: func(m *athens.ValidatorMock) {
+			m.On("Validate", mock.Anything, athens.ValidationRequest{
+				Module:  "test-mod",
+				Version: semver.MustParse("v1.0.0"),
+			}).Return(&athens.ErrForbidden{
+				Inner: fmt.Errorf("module in blacklist"),
+				Denial: validation.Denial{
+					Module:  "test-mod",
+					Version: "v1.0.0",
+					Reason:  validation.DenialBlacklisted,
+					Message: "test-mod@v1.0.0 is blacklisted",
+					HelpURL: "https://example.com/policy",
+				},
+			}).Once()
+		},
+		ExpectedCode: http.StatusForbidden,
+		ExpectedBody: "test-mod@v1.0.0 is blacklisted\nSee https://example.com/policy for details",
+	})
+
+	f(testCase{
+		Name: "forbidden module json response",
+		Request: func() *http.Request {
+			req := makeRequest( /*language=json*/ `{"Module":  "test-mod", "Version":  "v1.0.0"}`)
+			req.Header.Set("Accept", "text/plain;q=0.5, application/json")
+			return req
+		}(),
+		ValidatorMockSetup: func(m *athens.ValidatorMock) {
+			m.On("Validate", mock.Anything, athens.ValidationRequest{
+				Module:  "test-mod",
+				Version: semver.MustParse("v1.0.0"),
+			}).Return(&athens.ErrForbidden{
+				Inner: fmt.Errorf("denied license"),
+				Denial: validation.Denial{
+					Module:  "test-mod",
+					Version: "v1.0.0",
+					License: "AGPL-3.0",
+					Rule:    "denied_licenses: AGPL-3.0",
+					Reason:  validation.DenialDeniedLicense,
+					Message: "test-mod@v1.0.0 is licensed under AGPL-3.0 which is not allowed",
+				},
+			}).Once()
+		},
+		ExpectedCode: http.StatusForbidden,
+		ExpectedBody: `{"module":"test-mod","version":"v1.0.0","license":"AGPL-3.0","rule":"denied_licenses: AGPL-3.0","reason":"denied_license","message":"test-mod@v1.0.0 is licensed under AGPL-3.0 which is not allowed"}`,
+	})
+
 	f(testCase{
 		Name:    "internal error",
 		Request: makeRequest( /*language=json*/ `{"Module":  "test-mod", "Version":  "v1.0.0"}`),
@@ -128,17 +178,81 @@ func TestAdmissionHandler(t *testing.T) {
 	})
 }
 
-func TestAdmissionHandler_prevents_misconfiguration(t *testing.T) {
+func TestListAdmissionHandler(t *testing.T) {
 	t.Parallel()
-	var validatorMock athens.ValidatorMock
-	defer validatorMock.AssertExpectations(t)
+	type testCase struct {
+		Name               string
+		Request            *http.Request
+		ExpectedCode       int
+		ExpectedBody       string
+		ValidatorMockSetup func(m *validation.ValidatorMock)
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			var validatorMock validation.ValidatorMock
+			if tc.ValidatorMockSetup != nil {
+				tc.ValidatorMockSetup(&validatorMock)
+			}
 
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	rec := httptest.NewRecorder()
+			defer validatorMock.AssertExpectations(t)
+
+			rec := httptest.NewRecorder()
+
+			athens.ListAdmissionHandler(&validatorMock, 0)(rec, tc.Request)
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+
+			if tc.ExpectedBody != "" {
+				assert.Equal(t, tc.ExpectedBody, strings.TrimRight(rec.Body.String(), "\n"))
+			}
+		})
+	}
 
-	athens.AdmissionHandler(nil, req.Host, req.RemoteAddr)(rec, req)
+	module := func(version string) validation.Module {
+		return validation.Module{Name: "test-mod", Version: semver.MustParse(version)}
+	}
+
+	f(testCase{
+		Name:    "denied versions filtered",
+		Request: makeRequest( /*language=json*/ `{"Module":  "test-mod", "Versions":  ["v1.0.0", "v1.1.0", "v1.2.0"]}`),
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module("v1.0.0")).Return(nil).Once()
+			m.On("Validate", mock.Anything, module("v1.1.0")).Return(validation.ErrUnknownLicense).Once()
+			m.On("Validate", mock.Anything, module("v1.2.0")).Return(nil).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{"Module":"test-mod","Versions":["v1.0.0","v1.2.0"]}`,
+	})
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	f(testCase{
+		Name:         "empty list",
+		Request:      makeRequest( /*language=json*/ `{"Module":  "test-mod"}`),
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{"Module":"test-mod","Versions":[]}`,
+	})
+
+	f(testCase{
+		Name:    "validation error",
+		Request: makeRequest( /*language=json*/ `{"Module":  "test-mod", "Versions":  ["v1.0.0"]}`),
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module("v1.0.0")).Return(fmt.Errorf("test error")).Once()
+		},
+		ExpectedCode: http.StatusInternalServerError,
+	})
+
+	f(testCase{
+		Name:         "bad version",
+		Request:      makeRequest( /*language=json*/ `{"Module":  "test-mod", "Versions":  ["bla"]}`),
+		ExpectedCode: http.StatusBadRequest,
+	})
+
+	f(testCase{
+		Name:         "request without module name",
+		Request:      makeRequest( /*language=json*/ `{}`),
+		ExpectedCode: http.StatusBadRequest,
+		ExpectedBody: "no module name",
+	})
 }
 
 func makeRequest(body string) *http.Request {
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/Masterminds/semver/v3"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
 )
 
 type ValidationRequest struct {
@@ -12,6 +14,18 @@ type ValidationRequest struct {
 	Version *semver.Version
 }
 
+// ListValidationRequest is a request to filter module versions list
+type ListValidationRequest struct {
+	Module   string
+	Versions []string
+}
+
+// ListValidationResponse contains allowed versions from ListValidationRequest in original order
+type ListValidationResponse struct {
+	Module   string
+	Versions []string
+}
+
 type Validator interface {
 	Validate(ctx context.Context, req ValidationRequest) error
 }
@@ -19,6 +33,9 @@ type Validator interface {
 // ErrForbidden should be returned by Validator if module validation failed by rule set
 type ErrForbidden struct {
 	Inner error
+
+	// Denial is a user-facing description of rejection
+	Denial validation.Denial
 }
 
 func (e *ErrForbidden) Error() string { return fmt.Sprintf("module forbidden: %s", e.Inner) }
@@ -10,23 +10,30 @@ import (
 
 type InternalValidator struct {
 	validation.Validator
+
+	// HelpURL is an optional link added to denial description
+	HelpURL string
+
+	// Messages contains optional custom denial messages
+	Messages validation.DenialMessages
 }
 
 func (v *InternalValidator) Validate(ctx context.Context, req ValidationRequest) error {
-	err := v.Validator.Validate(ctx, validation.Module{Name: req.Module, Version: req.Version})
-	var (
-		blacklistErr     *validation.ErrBlacklistedModule
-		deniedLicenseErr *validation.ErrDeniedLicense
-	)
-
-	switch {
-	case errors.Is(err, nil):
+	m := validation.Module{Name: req.Module, Version: req.Version}
+	err := v.Validator.Validate(ctx, m)
+	if errors.Is(err, nil) {
 		return nil
-	case errors.Is(err, validation.ErrUnknownLicense),
-		errors.As(err, &blacklistErr),
-		errors.As(err, &deniedLicenseErr):
-		return &ErrForbidden{Inner: err}
-	default:
+	}
+
+	denial, ok := validation.DenialFromError(m, err)
+	if !ok {
 		return fmt.Errorf("validator failed: %w", err)
 	}
+
+	denial.HelpURL = v.HelpURL
+	if err := v.Messages.Render(&denial); err != nil {
+		return fmt.Errorf("denial message render failed: %w", err)
+	}
+
+	return &ErrForbidden{Inner: err, Denial: denial}
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"text/template"
 
 	"github.com/xakep666/licensevalidator/pkg/athens"
 	"github.com/xakep666/licensevalidator/pkg/validation"
@@ -83,11 +84,50 @@ func (s *InternalValidatorTestSuite) TestForbidden() {
 	err = s.internalValidator.Validate(context.Background(), req)
 	s.True(errors.As(err, &fbErr), "unexpected error", err)
 	s.Equal(fbErr.Unwrap(), deniedLicenseErr)
+	s.Equal(validation.Denial{
+		Module:  "test",
+		Version: "v1.0.0",
+		License: "MIT",
+		Rule:    "allowed_licenses",
+		Reason:  validation.DenialDeniedLicense,
+		Message: "test@v1.0.0 is licensed under MIT which is not allowed",
+		HelpURL: "https://example.com/policy",
+	}, fbErr.Denial)
+}
+
+func (s *InternalValidatorTestSuite) TestForbiddenCustomMessage() {
+	mod := validation.Module{
+		Name:    "test",
+		Version: semver.MustParse("v1.0.0"),
+	}
+
+	s.internalValidator.Messages = validation.DenialMessages{
+		validation.DenialUnknownLicense: template.Must(template.New("").Parse(
+			`{{ .Message }}, contact license-team@example.com or visit {{ .HelpURL }}`,
+		)),
+	}
+
+	s.validatorMock.On("Validate", mock.Anything, mod).Return(validation.ErrUnknownLicense).Once()
+	err := s.internalValidator.Validate(context.Background(), athens.ValidationRequest{
+		Module:  "test",
+		Version: semver.MustParse("v1.0.0"),
+	})
+
+	var fbErr *athens.ErrForbidden
+	if s.True(errors.As(err, &fbErr), "unexpected error", err) {
+		s.Equal(
+			"license of test@v1.0.0 can't be determined, contact license-team@example.com or visit https://example.com/policy",
+			fbErr.Denial.Message,
+		)
+	}
 }
 
 func (s *InternalValidatorTestSuite) SetupTest() {
 	s.validatorMock = new(validation.ValidatorMock)
-	s.internalValidator = &athens.InternalValidator{Validator: s.validatorMock}
+	s.internalValidator = &athens.InternalValidator{
+		Validator: s.validatorMock,
+		HelpURL:   "https://example.com/policy",
+	}
 }
 
 func (s *InternalValidatorTestSuite) TearDownTest() {
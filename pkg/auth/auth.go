@@ -0,0 +1,174 @@
+// Package auth contains caller authentication methods and http middleware enforcing them
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ErrUnauthorized returned by Authenticator if request didn't pass authentication
+var ErrUnauthorized = fmt.Errorf("unauthorized")
+
+// Authenticator checks caller identity
+type Authenticator interface {
+	// Authenticate returns nil if request passes authentication.
+	// It should return error wrapping ErrUnauthorized if caller is not authenticated.
+	Authenticate(r *http.Request) error
+}
+
+// Challenger is an optional interface for Authenticator which provides WWW-Authenticate header value
+type Challenger interface {
+	Challenge() string
+}
+
+// SharedSecret checks that request header contains one of known secrets
+type SharedSecret struct {
+	// Header is a name of header containing secret.
+	// Default is "Authorization", in this case "Bearer" scheme is expected.
+	Header string
+
+	Secrets []string
+}
+
+func (s *SharedSecret) Authenticate(r *http.Request) error {
+	var secret string
+	if s.Header == "" || http.CanonicalHeaderKey(s.Header) == "Authorization" {
+		const prefix = "bearer "
+		value := r.Header.Get("Authorization")
+		if len(value) < len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+			return fmt.Errorf("%w: no bearer token", ErrUnauthorized)
+		}
+		secret = value[len(prefix):]
+	} else {
+		secret = r.Header.Get(s.Header)
+	}
+
+	if secret == "" {
+		return fmt.Errorf("%w: no shared secret", ErrUnauthorized)
+	}
+
+	for _, known := range s.Secrets {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(known)) == 1 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: invalid shared secret", ErrUnauthorized)
+}
+
+func (s *SharedSecret) Challenge() string {
+	if s.Header == "" || http.CanonicalHeaderKey(s.Header) == "Authorization" {
+		return "Bearer"
+	}
+
+	return ""
+}
+
+// Basic performs HTTP basic authentication
+type Basic struct {
+	// Users maps user names to passwords
+	Users map[string]string
+}
+
+func (b *Basic) Authenticate(r *http.Request) error {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return fmt.Errorf("%w: no basic credentials", ErrUnauthorized)
+	}
+
+	known, ok := b.Users[user]
+	if !ok {
+		// compare anyway to not leak user existence through timings
+		known = ""
+	}
+
+	if subtle.ConstantTimeCompare([]byte(password), []byte(known)) != 1 || !ok {
+		return fmt.Errorf("%w: invalid basic credentials for user %q", ErrUnauthorized, user)
+	}
+
+	return nil
+}
+
+func (b *Basic) Challenge() string { return `Basic realm="licensevalidator", charset="UTF-8"` }
+
+// ClientCert checks that caller presented verified TLS client certificate with allowed subject.
+// Server must be configured to verify client certificates.
+type ClientCert struct {
+	// Subjects contains allowed certificate subjects.
+	// Subject matches if it's equal to certificate subject distinguished name (i.e. "CN=athens,O=MyCorp") or common name.
+	Subjects []string
+}
+
+func (c *ClientCert) Authenticate(r *http.Request) error {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return fmt.Errorf("%w: no verified client certificate", ErrUnauthorized)
+	}
+
+	subject := r.TLS.VerifiedChains[0][0].Subject
+	dn := subject.String()
+
+	for _, allowed := range c.Subjects {
+		if allowed == dn || allowed == subject.CommonName {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: client certificate subject %q is not allowed", ErrUnauthorized, dn)
+}
+
+// AnyOf passes request if any of authenticators passes it
+type AnyOf []Authenticator
+
+func (a AnyOf) Authenticate(r *http.Request) error {
+	errs := make([]string, 0, len(a))
+	for _, authenticator := range a {
+		err := authenticator.Authenticate(r)
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, err.Error())
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnauthorized, strings.Join(errs, "; "))
+}
+
+func (a AnyOf) Challenge() string {
+	var challenges []string
+	for _, authenticator := range a {
+		if c, ok := authenticator.(Challenger); ok && c.Challenge() != "" {
+			challenges = append(challenges, c.Challenge())
+		}
+	}
+
+	return strings.Join(challenges, ", ")
+}
+
+// Middleware rejects requests not passed authentication with 401 status code
+func Middleware(log *zap.Logger, authenticator Authenticator) func(next http.Handler) http.Handler {
+	log = log.With(zap.String("component", "auth_middleware"))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := authenticator.Authenticate(r); err != nil {
+				log.Warn("Request authentication failed",
+					zap.Error(err),
+					zap.String("from", r.RemoteAddr),
+					zap.Stringer("url", r.URL),
+				)
+
+				if c, ok := authenticator.(Challenger); ok && c.Challenge() != "" {
+					w.Header().Set("WWW-Authenticate", c.Challenge())
+				}
+
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
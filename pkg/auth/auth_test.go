@@ -0,0 +1,166 @@
+package auth_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAuthenticators(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name          string
+		Authenticator auth.Authenticator
+		Request       func(r *http.Request)
+		ExpectedOk    bool
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.Request != nil {
+				tc.Request(req)
+			}
+
+			err := tc.Authenticator.Authenticate(req)
+			if tc.ExpectedOk {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, auth.ErrUnauthorized), "Expected ErrUnauthorized, got", err)
+			}
+		})
+	}
+
+	f(testCase{
+		Name:          "bearer token ok",
+		Authenticator: &auth.SharedSecret{Secrets: []string{"first", "second"}},
+		Request:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer second") },
+		ExpectedOk:    true,
+	})
+
+	f(testCase{
+		Name:          "bearer token invalid",
+		Authenticator: &auth.SharedSecret{Secrets: []string{"first"}},
+		Request:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer second") },
+	})
+
+	f(testCase{
+		Name:          "bearer token missing",
+		Authenticator: &auth.SharedSecret{Secrets: []string{"first"}},
+	})
+
+	f(testCase{
+		Name:          "custom header ok",
+		Authenticator: &auth.SharedSecret{Header: "X-Shared-Secret", Secrets: []string{"first"}},
+		Request:       func(r *http.Request) { r.Header.Set("X-Shared-Secret", "first") },
+		ExpectedOk:    true,
+	})
+
+	f(testCase{
+		Name:          "basic ok",
+		Authenticator: &auth.Basic{Users: map[string]string{"athens": "pass"}},
+		Request:       func(r *http.Request) { r.SetBasicAuth("athens", "pass") },
+		ExpectedOk:    true,
+	})
+
+	f(testCase{
+		Name:          "basic wrong password",
+		Authenticator: &auth.Basic{Users: map[string]string{"athens": "pass"}},
+		Request:       func(r *http.Request) { r.SetBasicAuth("athens", "wrong") },
+	})
+
+	f(testCase{
+		Name:          "basic unknown user with empty password",
+		Authenticator: &auth.Basic{Users: map[string]string{"athens": "pass"}},
+		Request:       func(r *http.Request) { r.SetBasicAuth("unknown", "") },
+	})
+
+	f(testCase{
+		Name:          "client cert by common name",
+		Authenticator: &auth.ClientCert{Subjects: []string{"athens"}},
+		Request:       withClientCert(pkix.Name{CommonName: "athens", Organization: []string{"MyCorp"}}),
+		ExpectedOk:    true,
+	})
+
+	f(testCase{
+		Name:          "client cert by distinguished name",
+		Authenticator: &auth.ClientCert{Subjects: []string{"CN=athens,O=MyCorp"}},
+		Request:       withClientCert(pkix.Name{CommonName: "athens", Organization: []string{"MyCorp"}}),
+		ExpectedOk:    true,
+	})
+
+	f(testCase{
+		Name:          "client cert not allowed",
+		Authenticator: &auth.ClientCert{Subjects: []string{"athens"}},
+		Request:       withClientCert(pkix.Name{CommonName: "intruder"}),
+	})
+
+	f(testCase{
+		Name:          "client cert without tls",
+		Authenticator: &auth.ClientCert{Subjects: []string{"athens"}},
+	})
+
+	f(testCase{
+		Name: "any of passes",
+		Authenticator: auth.AnyOf{
+			&auth.SharedSecret{Secrets: []string{"first"}},
+			&auth.Basic{Users: map[string]string{"athens": "pass"}},
+		},
+		Request:    func(r *http.Request) { r.SetBasicAuth("athens", "pass") },
+		ExpectedOk: true,
+	})
+
+	f(testCase{
+		Name: "any of fails",
+		Authenticator: auth.AnyOf{
+			&auth.SharedSecret{Secrets: []string{"first"}},
+			&auth.Basic{Users: map[string]string{"athens": "pass"}},
+		},
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+	handler := auth.Middleware(zaptest.NewLogger(t), auth.AnyOf{
+		&auth.SharedSecret{Secrets: []string{"first"}},
+		&auth.Basic{Users: map[string]string{"athens": "pass"}},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Run("authenticated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer first")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("not authenticated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, `Bearer, Basic realm="licensevalidator", charset="UTF-8"`, rec.Header().Get("WWW-Authenticate"))
+	})
+}
+
+func withClientCert(subject pkix.Name) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.TLS = &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: subject}}},
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package batch contains validation of module sets (i.e. all dependencies of project) with summary report.
+package batch
+
+import (
+	"context"
+	"errors"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// DefaultConcurrency is a default number of simultaneous module validations
+const DefaultConcurrency = 8
+
+type Status string
+
+const (
+	StatusAllowed Status = "allowed"
+	StatusDenied  Status = "denied"
+	StatusError   Status = "error"
+)
+
+// Result is a validation result of single module
+type Result struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+	Status  Status `json:"status"`
+
+	// Denial is a description of rejection, filled for denied modules
+	Denial *validation.Denial `json:"denial,omitempty"`
+
+	// Error is a validation error text, filled if module validation failed
+	Error string `json:"error,omitempty"`
+}
+
+type Summary struct {
+	Total   int `json:"total"`
+	Allowed int `json:"allowed"`
+	Denied  int `json:"denied"`
+	Errors  int `json:"errors"`
+}
+
+// Report contains per-module results in input order and overall verdict.
+// Verdict is StatusDenied if any module denied, StatusError if some module validation failed
+// and StatusAllowed if all modules are allowed.
+type Report struct {
+	Verdict Status   `json:"verdict"`
+	Summary Summary  `json:"summary"`
+	Modules []Result `json:"modules"`
+}
+
+type ValidatorParams struct {
+	Validator validation.Validator
+
+	// Resolver optionally resolves licenses of all modules at once before validation (i.e. with batched requests).
+	// If set, modules are validated by validator built with ResolvedValidator.
+	// Resolvers without batching support are called for Concurrency modules simultaneously (see validation.WithResolveConcurrency).
+	Resolver validation.BatchLicenseResolver
+
+	// ResolvedValidator builds validator which takes licenses from provided resolver, required if Resolver is set.
+	// Such validator should apply the same rules as Validator.
+	ResolvedValidator func(resolver validation.LicenseResolver) validation.Validator
+
+	// Concurrency limits number of simultaneous validations. Default is DefaultConcurrency.
+	Concurrency int
+
+	// HelpURL is an optional link added to denial descriptions
+	HelpURL string
+
+	// Messages contains optional custom denial messages
+	Messages validation.DenialMessages
+}
+
+// Validator validates module sets using bounded pool of workers
+type Validator struct {
+	ValidatorParams
+
+	log *zap.Logger
+}
+
+func NewValidator(log *zap.Logger, params ValidatorParams) *Validator {
+	if params.Concurrency <= 0 {
+		params.Concurrency = DefaultConcurrency
+	}
+
+	return &Validator{
+		ValidatorParams: params,
+		log:             log.With(zap.String("component", "batch_validator")),
+	}
+}
+
+// Validate validates all modules and builds report.
+// Error is returned only if context done before all modules validated.
+func (v *Validator) Validate(ctx context.Context, modules []validation.Module) (*Report, error) {
+	validator, failed := v.Validator, map[int]error{}
+	if v.Resolver != nil {
+		var licenses resolvedLicenses
+		licenses, failed = v.resolve(ctx, modules)
+		validator = v.ResolvedValidator(licenses)
+	}
+
+	results := make([]Result, len(modules))
+	jobs := make(chan int)
+	done := make(chan struct{})
+
+	workers := v.Concurrency
+	if workers > len(modules) {
+		workers = len(modules)
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+
+			for idx := range jobs {
+				err, ok := failed[idx]
+				if !ok {
+					err = validator.Validate(ctx, modules[idx])
+				}
+
+				results[idx] = v.result(modules[idx], err)
+			}
+		}()
+	}
+
+loop:
+	for i := range modules {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	close(jobs)
+
+	for i := 0; i < workers; i++ {
+		<-done
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Modules: results}
+	for _, result := range results {
+		report.Summary.Total++
+
+		switch result.Status {
+		case StatusAllowed:
+			report.Summary.Allowed++
+		case StatusDenied:
+			report.Summary.Denied++
+		default:
+			report.Summary.Errors++
+		}
+	}
+
+	switch {
+	case report.Summary.Denied > 0:
+		report.Verdict = StatusDenied
+	case report.Summary.Errors > 0:
+		report.Verdict = StatusError
+	default:
+		report.Verdict = StatusAllowed
+	}
+
+	v.log.Debug("Batch validated",
+		zap.String("verdict", string(report.Verdict)),
+		zap.Int("total", report.Summary.Total),
+		zap.Int("denied", report.Summary.Denied),
+		zap.Int("errors", report.Summary.Errors),
+	)
+
+	return report, nil
+}
+
+// resolve resolves licenses of all modules at once. Resolved licenses and failed resolutions by module index are returned,
+// modules with unknown license are left for validator.
+func (v *Validator) resolve(ctx context.Context, modules []validation.Module) (resolvedLicenses, map[int]error) {
+	licenses, failed := make(resolvedLicenses), make(map[int]error)
+	for i, result := range v.Resolver.ResolveLicenses(validation.WithResolveConcurrency(ctx, v.Concurrency), modules) {
+		switch {
+		case errors.Is(result.Err, nil):
+			licenses[licenseKey(modules[i])] = result.License
+		case errors.Is(result.Err, validation.ErrUnknownLicense):
+			// validator decides what to do with unknown license
+		default:
+			failed[i] = result.Err
+		}
+	}
+
+	v.log.Debug("Licenses resolved", zap.Int("modules", len(modules)), zap.Int("resolved", len(licenses)), zap.Int("failed", len(failed)))
+
+	return licenses, failed
+}
+
+func (v *Validator) result(m validation.Module, err error) Result {
+	result := Result{Module: m.Name, Version: m.Version.Original()}
+
+	if errors.Is(err, nil) {
+		result.Status = StatusAllowed
+		return result
+	}
+
+	denial, ok := validation.DenialFromError(m, err)
+	if !ok {
+		v.log.Warn("Module validation failed", zap.Stringer("module", &m), zap.Error(err))
+		result.Status, result.Error = StatusError, err.Error()
+		return result
+	}
+
+	denial.HelpURL = v.HelpURL
+	if err := v.Messages.Render(&denial); err != nil {
+		v.log.Error("Denial message render failed", zap.Error(err))
+	}
+
+	result.Status, result.Denial = StatusDenied, &denial
+
+	return result
+}
+
+// resolvedLicenses is a license resolver returning licenses resolved in advance
+type resolvedLicenses map[string]validation.License
+
+func licenseKey(m validation.Module) string {
+	return m.Name + "@" + m.Version.Original()
+}
+
+// Name returns resolver name shown in explanations of batch validation, licenses are resolved before it
+func (resolvedLicenses) Name() string { return "batch" }
+
+func (r resolvedLicenses) ResolveLicense(_ context.Context, m validation.Module) (validation.License, error) {
+	license, ok := r[licenseKey(m)]
+	if !ok {
+		return validation.License{}, validation.ErrUnknownLicense
+	}
+
+	return license, nil
+}
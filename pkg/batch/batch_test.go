@@ -0,0 +1,222 @@
+package batch_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	allowed := validation.Module{Name: "github.com/test/allowed", Version: semver.MustParse("v1.0.0")}
+	denied := validation.Module{Name: "github.com/test/denied", Version: semver.MustParse("v1.1.0")}
+	failed := validation.Module{Name: "github.com/test/failed", Version: semver.MustParse("v1.2.0")}
+
+	type testCase struct {
+		Name               string
+		Modules            []validation.Module
+		ValidatorMockSetup func(m *validation.ValidatorMock)
+		ExpectedReport     *batch.Report
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			var validatorMock validation.ValidatorMock
+			if tc.ValidatorMockSetup != nil {
+				tc.ValidatorMockSetup(&validatorMock)
+			}
+
+			defer validatorMock.AssertExpectations(t)
+
+			report, err := batch.NewValidator(zaptest.NewLogger(t), batch.ValidatorParams{
+				Validator:   &validatorMock,
+				Concurrency: 2,
+				HelpURL:     "https://example.com/policy",
+			}).Validate(context.Background(), tc.Modules)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.ExpectedReport, report)
+			}
+		})
+	}
+
+	f(testCase{
+		Name:    "all allowed",
+		Modules: []validation.Module{allowed},
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, allowed).Return(nil).Once()
+		},
+		ExpectedReport: &batch.Report{
+			Verdict: batch.StatusAllowed,
+			Summary: batch.Summary{Total: 1, Allowed: 1},
+			Modules: []batch.Result{
+				{Module: "github.com/test/allowed", Version: "v1.0.0", Status: batch.StatusAllowed},
+			},
+		},
+	})
+
+	f(testCase{
+		Name:    "denied and failed",
+		Modules: []validation.Module{allowed, denied, failed},
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, allowed).Return(nil).Once()
+			m.On("Validate", mock.Anything, denied).Return(validation.ErrUnknownLicense).Once()
+			m.On("Validate", mock.Anything, failed).Return(fmt.Errorf("test error")).Once()
+		},
+		ExpectedReport: &batch.Report{
+			Verdict: batch.StatusDenied,
+			Summary: batch.Summary{Total: 3, Allowed: 1, Denied: 1, Errors: 1},
+			Modules: []batch.Result{
+				{Module: "github.com/test/allowed", Version: "v1.0.0", Status: batch.StatusAllowed},
+				{
+					Module:  "github.com/test/denied",
+					Version: "v1.1.0",
+					Status:  batch.StatusDenied,
+					Denial: &validation.Denial{
+						Module:  "github.com/test/denied",
+						Version: "v1.1.0",
+						Rule:    "unknown_license_action: deny",
+						Reason:  validation.DenialUnknownLicense,
+						Message: "license of github.com/test/denied@v1.1.0 can't be determined",
+						HelpURL: "https://example.com/policy",
+					},
+				},
+				{Module: "github.com/test/failed", Version: "v1.2.0", Status: batch.StatusError, Error: "test error"},
+			},
+		},
+	})
+
+	f(testCase{
+		Name:    "failed",
+		Modules: []validation.Module{failed},
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, failed).Return(fmt.Errorf("test error")).Once()
+		},
+		ExpectedReport: &batch.Report{
+			Verdict: batch.StatusError,
+			Summary: batch.Summary{Total: 1, Errors: 1},
+			Modules: []batch.Result{
+				{Module: "github.com/test/failed", Version: "v1.2.0", Status: batch.StatusError, Error: "test error"},
+			},
+		},
+	})
+
+	f(testCase{
+		Name: "empty",
+		ExpectedReport: &batch.Report{
+			Verdict: batch.StatusAllowed,
+			Modules: []batch.Result{},
+		},
+	})
+}
+
+func TestValidator_Validate_resolver(t *testing.T) {
+	t.Parallel()
+
+	allowed := validation.Module{Name: "github.com/test/allowed", Version: semver.MustParse("v1.0.0")}
+	unknown := validation.Module{Name: "github.com/test/unknown", Version: semver.MustParse("v1.1.0")}
+	failed := validation.Module{Name: "github.com/test/failed", Version: semver.MustParse("v1.2.0")}
+	mit := validation.License{Name: "MIT License", SPDXID: "MIT"}
+
+	var resolverMock validation.BatchLicenseResolverMock
+	defer resolverMock.AssertExpectations(t)
+
+	resolverMock.On("ResolveLicenses", mock.Anything, []validation.Module{allowed, unknown, failed}).Return([]validation.LicenseResult{
+		{License: mit},
+		{Err: validation.ErrUnknownLicense},
+		{Err: fmt.Errorf("test error")},
+	}).Once()
+
+	var validatorMock validation.ValidatorMock
+	defer validatorMock.AssertExpectations(t)
+
+	// resolved licenses are passed to validator, failed modules are not validated
+	var resolved validation.LicenseResolver
+	validatorMock.On("Validate", mock.Anything, allowed).Return(nil).Run(func(args mock.Arguments) {
+		lic, err := resolved.ResolveLicense(context.Background(), allowed)
+		if assert.NoError(t, err) {
+			assert.Equal(t, mit, lic)
+		}
+	}).Once()
+	validatorMock.On("Validate", mock.Anything, unknown).Return(validation.ErrUnknownLicense).Run(func(args mock.Arguments) {
+		_, err := resolved.ResolveLicense(context.Background(), unknown)
+		assert.Equal(t, validation.ErrUnknownLicense, err)
+	}).Once()
+
+	report, err := batch.NewValidator(zaptest.NewLogger(t), batch.ValidatorParams{
+		Resolver: &resolverMock,
+		ResolvedValidator: func(resolver validation.LicenseResolver) validation.Validator {
+			resolved = resolver
+			return &validatorMock
+		},
+		Concurrency: 2,
+	}).Validate(context.Background(), []validation.Module{allowed, unknown, failed})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, batch.StatusDenied, report.Verdict)
+	assert.Equal(t, batch.Summary{Total: 3, Allowed: 1, Denied: 1, Errors: 1}, report.Summary)
+	assert.Equal(t, batch.Result{Module: "github.com/test/failed", Version: "v1.2.0", Status: batch.StatusError, Error: "test error"}, report.Modules[2])
+}
+
+// slowResolver resolves MIT license with delay and tracks maximal number of simultaneous calls
+type slowResolver struct {
+	inFlight, maxInFlight int32
+}
+
+func (r *slowResolver) ResolveLicense(context.Context, validation.Module) (validation.License, error) {
+	current := atomic.AddInt32(&r.inFlight, 1)
+	defer atomic.AddInt32(&r.inFlight, -1)
+
+	for {
+		seen := atomic.LoadInt32(&r.maxInFlight)
+		if current <= seen || atomic.CompareAndSwapInt32(&r.maxInFlight, seen, current) {
+			break
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	return validation.License{Name: "MIT License", SPDXID: "MIT"}, nil
+}
+
+func TestValidator_Validate_resolverConcurrency(t *testing.T) {
+	t.Parallel()
+
+	modules := make([]validation.Module, 8)
+	for i := range modules {
+		modules[i] = validation.Module{Name: fmt.Sprintf("github.com/test/module%d", i), Version: semver.MustParse("v1.0.0")}
+	}
+
+	var slow slowResolver
+
+	var validatorMock validation.ValidatorMock
+	defer validatorMock.AssertExpectations(t)
+
+	validatorMock.On("Validate", mock.Anything, mock.Anything).Return(nil).Times(len(modules))
+
+	report, err := batch.NewValidator(zaptest.NewLogger(t), batch.ValidatorParams{
+		// resolver without batching support behind chain
+		Resolver: &validation.ChainedLicenseResolver{LicenseResolvers: []validation.LicenseResolver{&slow}},
+		ResolvedValidator: func(validation.LicenseResolver) validation.Validator {
+			return &validatorMock
+		},
+		Concurrency: 4,
+	}).Validate(context.Background(), modules)
+	if assert.NoError(t, err) {
+		assert.Equal(t, batch.StatusAllowed, report.Verdict)
+	}
+
+	assert.EqualValues(t, 4, atomic.LoadInt32(&slow.maxInFlight), "resolver should be called concurrently")
+}
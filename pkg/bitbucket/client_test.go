@@ -0,0 +1,264 @@
+package bitbucket_test
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/bitbucket"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const testToken = "secret"
+
+func mitLicense(t *testing.T) []byte {
+	zr, err := zip.OpenReader("../goproxy/testdata/testify-1.5.1.zip")
+	require.NoError(t, err)
+
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != "github.com/stretchr/testify@v1.5.1/LICENSE" {
+			continue
+		}
+
+		rd, err := f.Open()
+		require.NoError(t, err)
+
+		defer rd.Close()
+
+		license, err := ioutil.ReadAll(rd)
+		require.NoError(t, err)
+
+		return license
+	}
+
+	t.Fatal("license not found")
+	return nil
+}
+
+type entry struct {
+	Path struct {
+		Name string `json:"name"`
+	} `json:"path"`
+	Type string `json:"type"`
+}
+
+func file(name string) entry {
+	var e entry
+	e.Path.Name, e.Type = name, "FILE"
+	return e
+}
+
+func directory(name string) entry {
+	var e entry
+	e.Path.Name, e.Type = name, "DIRECTORY"
+	return e
+}
+
+// bitbucketServer emulates Bitbucket Server API with repository "PRJ/repo" containing:
+// * root module tagged v1.0.0 with MIT license
+// * module "pkg/v2" stored in "pkg" directory tagged pkg/v2.1.0 without own license
+// Directory listings are paginated by one entry.
+func bitbucketServer(t *testing.T) *httptest.Server {
+	license := mitLicense(t)
+
+	trees := map[string][]entry{
+		"refs/tags/v1.0.0":         {file("LICENSE"), file("go.mod")},
+		"refs/tags/pkg/v2.1.0":     {file("LICENSE"), directory("pkg")},
+		"refs/tags/pkg/v2.1.0/pkg": {file("go.mod")},
+	}
+
+	const repoPath = "/rest/api/1.0/projects/PRJ/repos/repo"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		at := r.URL.Query().Get("at")
+		switch path := r.URL.Path; {
+		case path == "/rest/api/1.0/projects":
+			json.NewEncoder(w).Encode(map[string]interface{}{"values": []interface{}{}, "isLastPage": true})
+		case path == repoPath:
+			json.NewEncoder(w).Encode(map[string]interface{}{"slug": "repo", "project": map[string]string{"key": "PRJ"}})
+		case strings.HasPrefix(path, repoPath+"/browse"):
+			key := at
+			if dir := strings.Trim(strings.TrimPrefix(path, repoPath+"/browse"), "/"); dir != "" {
+				key += "/" + dir
+			}
+
+			entries, ok := trees[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"children": map[string]interface{}{
+					"values":        entries[start : start+1],
+					"isLastPage":    start+1 == len(entries),
+					"nextPageStart": start + 1,
+				},
+			})
+		case path == repoPath+"/raw/LICENSE":
+			if _, ok := trees[at]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Write(license)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestClient_ResolveLicense(t *testing.T) {
+	t.Parallel()
+
+	srv := bitbucketServer(t)
+
+	client, err := bitbucket.NewClient(zaptest.NewLogger(t), bitbucket.ClientParams{
+		HTTPClient:          srv.Client(),
+		BaseURL:             srv.URL,
+		Hosts:               []string{"bitbucket.mycorp.com"},
+		Token:               testToken,
+		ConfidenceThreshold: 0.8,
+	})
+	require.NoError(t, err)
+
+	type testCase struct {
+		name    string
+		module  string
+		version string
+		license validation.License
+		err     error
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			lic, err := client.ResolveLicense(context.Background(), validation.Module{
+				Name:    tc.module,
+				Version: semver.MustParse(tc.version),
+			})
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.license, lic)
+			}
+		})
+	}
+
+	f(testCase{
+		name:    "root module",
+		module:  "bitbucket.mycorp.com/scm/prj/repo.git",
+		version: "v1.0.0",
+		license: validation.License{Name: "MIT License", SPDXID: "MIT"},
+	})
+	f(testCase{
+		name:    "major version directory with parent license",
+		module:  "bitbucket.mycorp.com/prj/repo/pkg/v2",
+		version: "v2.1.0",
+		license: validation.License{Name: "MIT License", SPDXID: "MIT"},
+	})
+	f(testCase{
+		name:    "unknown tag",
+		module:  "bitbucket.mycorp.com/scm/prj/repo.git",
+		version: "v1.1.0",
+		err:     validation.ErrUnknownLicense,
+	})
+	f(testCase{
+		name:    "unknown repository",
+		module:  "bitbucket.mycorp.com/prj/other",
+		version: "v1.0.0",
+		err:     validation.ErrUnknownLicense,
+	})
+	f(testCase{
+		name:    "other host",
+		module:  "github.com/prj/repo",
+		version: "v1.0.0",
+		err:     validation.ErrUnknownLicense,
+	})
+}
+
+func TestClient_Filer(t *testing.T) {
+	t.Parallel()
+
+	srv := bitbucketServer(t)
+
+	client, err := bitbucket.NewClient(zaptest.NewLogger(t), bitbucket.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Hosts:      []string{"bitbucket.mycorp.com"},
+		Token:      testToken,
+	})
+	require.NoError(t, err)
+
+	fs, err := client.Filer(context.Background(), validation.Module{
+		Name:    "bitbucket.mycorp.com/scm/prj/repo.git",
+		Version: semver.MustParse("v1.0.0"),
+	})
+	require.NoError(t, err)
+
+	files, err := fs.ReadDir("")
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	content, err := fs.ReadFile("LICENSE")
+	require.NoError(t, err)
+	assert.Equal(t, mitLicense(t), content)
+
+	_, err = client.Filer(context.Background(), validation.Module{
+		Name:    "bitbucket.mycorp.com/scm/prj/repo.git",
+		Version: semver.MustParse("v1.1.0"),
+	})
+	assert.True(t, errors.Is(err, bitbucket.ErrModuleNotFound), "unexpected error: %v", err)
+}
+
+func TestClient_Check(t *testing.T) {
+	t.Parallel()
+
+	srv := bitbucketServer(t)
+
+	client, err := bitbucket.NewClient(zaptest.NewLogger(t), bitbucket.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Token:      testToken,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, client.Check(context.Background()))
+
+	client, err = bitbucket.NewClient(zaptest.NewLogger(t), bitbucket.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Token:      "invalid",
+	})
+	require.NoError(t, err)
+	assert.Error(t, client.Check(context.Background()))
+}
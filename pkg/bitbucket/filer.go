@@ -0,0 +1,96 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/xakep666/licensevalidator/pkg/apifiler"
+
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// apiRepository reads repository tree through Bitbucket Server browse and raw API
+type apiRepository struct {
+	client *Client
+
+	// repoPath is an API path of repository (/projects/{key}/repos/{slug})
+	repoPath string
+
+	// at is a reference (refs/tags/...) or commit to read
+	at string
+}
+
+func (r *apiRepository) OpenFile(ctx context.Context, p string) (io.ReadCloser, error) {
+	resp, err := r.client.do(ctx, r.repoPath+"/raw/"+apifiler.EscapePath(p), url.Values{"at": {r.at}})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+type browseResponse struct {
+	// Children is set only for directories
+	Children *struct {
+		Values []struct {
+			Path struct {
+				Name string `json:"name"`
+			} `json:"path"`
+			Type string `json:"type"`
+		} `json:"values"`
+		IsLastPage    bool `json:"isLastPage"`
+		NextPageStart int  `json:"nextPageStart"`
+	} `json:"children"`
+}
+
+func (r *apiRepository) ListDir(ctx context.Context, p string) ([]filer.File, error) {
+	apiPath := r.repoPath + "/browse"
+	if p != "" {
+		apiPath += "/" + apifiler.EscapePath(p)
+	}
+
+	query := url.Values{"at": {r.at}, "limit": {"500"}}
+
+	var result []filer.File
+	for start := 0; ; {
+		query.Set("start", strconv.Itoa(start))
+
+		resp, err := r.client.do(ctx, apiPath, query)
+		if err != nil {
+			return nil, err
+		}
+
+		var page browseResponse
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("listing decode failed: %w", err)
+		}
+
+		if page.Children == nil {
+			return nil, fmt.Errorf("%w: not a directory", errNotFound)
+		}
+
+		for _, entry := range page.Children.Values {
+			switch entry.Type {
+			case "FILE", "DIRECTORY":
+				result = append(result, filer.File{
+					Name:  entry.Path.Name,
+					IsDir: entry.Type == "DIRECTORY",
+				})
+			default:
+				// submodules are not interesting
+			}
+		}
+
+		if page.Children.IsLastPage || page.Children.NextPageStart <= start {
+			return result, nil
+		}
+
+		start = page.Children.NextPageStart
+	}
+}
@@ -34,9 +34,13 @@ func (ml *MemLRU) ResolveLicense(ctx context.Context, m validation.Module) (vali
 	key := ml.licenseLey(m)
 	licI, ok := ml.cache.Get(key)
 	if ok {
-		return licI.(validation.License), nil
+		lic := licI.(validation.License)
+		recordHit(ctx, "memlru_cache", m, &lic)
+		return lic, nil
 	}
 
+	recordMiss(ctx, "memlru_cache", m)
+
 	lic, err := ml.backed.ResolveLicense(ctx, m)
 	if err != nil {
 		return validation.License{}, fmt.Errorf("%w", err)
@@ -45,3 +49,22 @@ func (ml *MemLRU) ResolveLicense(ctx context.Context, m validation.Module) (vali
 	ml.cache.Add(key, lic)
 	return lic, nil
 }
+
+// ResolveLicenses returns cached licenses and resolves missing ones with backed resolver at once
+func (ml *MemLRU) ResolveLicenses(ctx context.Context, modules []validation.Module) []validation.LicenseResult {
+	get := func(m validation.Module) (validation.License, bool, error) {
+		licI, ok := ml.cache.Get(ml.licenseLey(m))
+		if !ok {
+			return validation.License{}, false, nil
+		}
+
+		return licI.(validation.License), true, nil
+	}
+
+	set := func(m validation.Module, lic validation.License) error {
+		ml.cache.Add(ml.licenseLey(m), lic)
+		return nil
+	}
+
+	return resolveMissing(ctx, ml.backed, modules, get, set)
+}
@@ -32,9 +32,12 @@ func (c *MemoryCache) ResolveLicense(ctx context.Context, m validation.Module) (
 	item, ok := c.licenseMap[key]
 	c.licenseMu.RUnlock()
 	if ok {
+		recordHit(ctx, "memory_cache", m, &item)
 		return item, nil
 	}
 
+	recordMiss(ctx, "memory_cache", m)
+
 	lic, err := c.Backed.ResolveLicense(ctx, m)
 	if err != nil {
 		return validation.License{}, fmt.Errorf("%w", err)
@@ -46,3 +49,28 @@ func (c *MemoryCache) ResolveLicense(ctx context.Context, m validation.Module) (
 
 	return lic, nil
 }
+
+// ResolveLicenses returns cached licenses and resolves missing ones with backed resolver at once
+func (c *MemoryCache) ResolveLicenses(ctx context.Context, modules []validation.Module) []validation.LicenseResult {
+	c.licenseMapOnceInit.Do(func() {
+		c.licenseMap = make(map[string]validation.License)
+	})
+
+	get := func(m validation.Module) (validation.License, bool, error) {
+		c.licenseMu.RLock()
+		item, ok := c.licenseMap[c.licenseLey(m)]
+		c.licenseMu.RUnlock()
+
+		return item, ok, nil
+	}
+
+	set := func(m validation.Module, lic validation.License) error {
+		c.licenseMu.Lock()
+		c.licenseMap[c.licenseLey(m)] = lic
+		c.licenseMu.Unlock()
+
+		return nil
+	}
+
+	return resolveMissing(ctx, c.Backed, modules, get, set)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMemoryCache_ResolveLicense(t *testing.T) {
@@ -79,3 +80,43 @@ func TestMemoryCache_ResolveLicense_error_not_cached(t *testing.T) {
 		assert.Equal(t, license, actualLicense)
 	}
 }
+
+func TestMemoryCache_ResolveLicenses(t *testing.T) {
+	t.Parallel()
+	var licenseResolverMock validation.BatchLicenseResolverMock
+	defer licenseResolverMock.AssertExpectations(t)
+
+	cached := validation.Module{
+		Name:    "cached",
+		Version: semver.MustParse("v1.0.0"),
+	}
+	missing := validation.Module{
+		Name:    "missing",
+		Version: semver.MustParse("v1.0.0"),
+	}
+
+	license := validation.License{
+		Name:   "MIT License",
+		SPDXID: "MIT",
+	}
+
+	licenseResolverMock.On("ResolveLicense", mock.Anything, cached).Return(license, nil).Once()
+	licenseResolverMock.On("ResolveLicenses", mock.Anything, []validation.Module{missing}).Return([]validation.LicenseResult{
+		{License: license},
+	}).Once()
+
+	c := cache.MemoryCache{Backed: cache.Direct{
+		LicenseResolver: &licenseResolverMock,
+	}}
+
+	_, err := c.ResolveLicense(context.Background(), cached)
+	require.NoError(t, err)
+
+	// only missing module should be resolved
+	results := c.ResolveLicenses(context.Background(), []validation.Module{cached, missing})
+	assert.Equal(t, []validation.LicenseResult{{License: license}, {License: license}}, results)
+
+	// all modules should be in cache now
+	results = c.ResolveLicenses(context.Background(), []validation.Module{missing, cached})
+	assert.Equal(t, []validation.LicenseResult{{License: license}, {License: license}}, results)
+}
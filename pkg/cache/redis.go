@@ -24,38 +24,59 @@ func (*RedisCache) licenseKey(m validation.Module) string {
 }
 
 func (rc *RedisCache) ResolveLicense(ctx context.Context, m validation.Module) (validation.License, error) {
-	key := rc.licenseKey(m)
-
-	var ret validation.License
-	maybeEmpty := MaybeEmpty{Rcv: &ret}
-
-	err := rc.Client.Do(radix.Cmd(&maybeEmpty, "HGETALL", key))
+	ret, ok, err := rc.get(m)
 	if err != nil {
-		return ret, fmt.Errorf("get license from redis failed: %w", err)
+		return ret, err
 	}
 
-	if !maybeEmpty.Empty {
+	if ok {
+		recordHit(ctx, "redis_cache", m, &ret)
 		return ret, nil
 	}
 
+	recordMiss(ctx, "redis_cache", m)
+
 	ret, err = rc.Backed.ResolveLicense(ctx, m)
 	if err != nil {
 		return ret, fmt.Errorf("%w", err)
 	}
 
+	return ret, rc.set(m, ret)
+}
+
+// ResolveLicenses returns cached licenses and resolves missing ones with backed resolver at once
+func (rc *RedisCache) ResolveLicenses(ctx context.Context, modules []validation.Module) []validation.LicenseResult {
+	return resolveMissing(ctx, rc.Backed, modules, rc.get, rc.set)
+}
+
+func (rc *RedisCache) get(m validation.Module) (validation.License, bool, error) {
+	var ret validation.License
+	maybeEmpty := MaybeEmpty{Rcv: &ret}
+
+	err := rc.Client.Do(radix.Cmd(&maybeEmpty, "HGETALL", rc.licenseKey(m)))
+	if err != nil {
+		return ret, false, fmt.Errorf("get license from redis failed: %w", err)
+	}
+
+	return ret, !maybeEmpty.Empty, nil
+}
+
+func (rc *RedisCache) set(m validation.Module, lic validation.License) error {
+	key := rc.licenseKey(m)
+
 	cmds := []radix.CmdAction{
-		radix.FlatCmd(nil, "HMSET", key, ret),
+		radix.FlatCmd(nil, "HMSET", key, lic),
 	}
 	if rc.TTL > 0 {
 		cmds = append(cmds, radix.FlatCmd(nil, "PEXPIRE", key, int64(rc.TTL/time.Millisecond)))
 	}
 
-	err = rc.Client.Do(radix.Pipeline(cmds...))
+	err := rc.Client.Do(radix.Pipeline(cmds...))
 	if err != nil {
-		return ret, fmt.Errorf("set license in redis failed: %w", err)
+		return fmt.Errorf("set license in redis failed: %w", err)
 	}
 
-	return ret, nil
+	return nil
 }
 
 func (rc *RedisCache) Check(ctx context.Context) error {
@@ -1,6 +1,11 @@
 package cache
 
-import "github.com/xakep666/licensevalidator/pkg/validation"
+import (
+	"context"
+	"fmt"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+)
 
 // Cacher covers all interfaces which calls should be cached
 type Cacher interface {
@@ -10,3 +15,75 @@ type Cacher interface {
 type Direct struct {
 	validation.LicenseResolver
 }
+
+// ResolveLicenses resolves licenses with underlying resolver at once if it supports it
+func (d Direct) ResolveLicenses(ctx context.Context, modules []validation.Module) []validation.LicenseResult {
+	return validation.ResolveLicenses(ctx, d.LicenseResolver, modules)
+}
+
+// resolveMissing resolves licenses of modules which are not found in cache with backed resolver at once.
+// get returns cached license, false returned if module is not cached; set saves resolved license to cache.
+func resolveMissing(
+	ctx context.Context,
+	backed Cacher,
+	modules []validation.Module,
+	get func(m validation.Module) (validation.License, bool, error),
+	set func(m validation.Module, lic validation.License) error,
+) []validation.LicenseResult {
+	var (
+		results = make([]validation.LicenseResult, len(modules))
+		missing []validation.Module
+		indexes []int
+	)
+
+	for i, m := range modules {
+		lic, ok, err := get(m)
+		switch {
+		case err != nil:
+			results[i].Err = err
+		case ok:
+			results[i].License = lic
+		default:
+			missing = append(missing, m)
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(missing) == 0 {
+		return results
+	}
+
+	for i, result := range validation.ResolveLicenses(ctx, backed, missing) {
+		idx := indexes[i]
+		if result.Err != nil {
+			results[idx].Err = fmt.Errorf("%w", result.Err)
+			continue
+		}
+
+		results[idx].License, results[idx].Err = result.License, set(missing[i], result.License)
+	}
+
+	return results
+}
+
+func recordHit(ctx context.Context, component string, m validation.Module, lic *validation.License) {
+	step := validation.Step{
+		Stage:     validation.StageCache,
+		Component: component,
+		Message:   fmt.Sprintf("%s@%s: cache hit", m.Name, m.Version.Original()),
+		License:   lic.SPDXID,
+	}
+	if step.License == "" {
+		step.License = lic.Name
+	}
+
+	validation.RecordStep(ctx, step)
+}
+
+func recordMiss(ctx context.Context, component string, m validation.Module) {
+	validation.RecordStep(ctx, validation.Step{
+		Stage:     validation.StageCache,
+		Component: component,
+		Message:   fmt.Sprintf("%s@%s: cache miss", m.Name, m.Version.Original()),
+	})
+}
@@ -0,0 +1,58 @@
+// Package detect selects module license from go-license-detector matches the same way for all resolvers.
+package detect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xakep666/licensevalidator/pkg/spdx"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/api"
+)
+
+// BestMatch returns the most confident license of matches having confidence not lower than threshold
+// and records detection step on behalf of component. Licenses with equal confidence are ordered by SPDX ID.
+// ErrUnknownLicense returned if no license passes threshold.
+func BestMatch(ctx context.Context, component string, matches map[string]api.Match, threshold float64) (validation.License, error) {
+	var (
+		mostConfidentLicense string
+		maxConfidence        float64
+	)
+
+	for id, match := range matches {
+		confidence := float64(match.Confidence)
+		if confidence < threshold || confidence < maxConfidence {
+			continue
+		}
+
+		if confidence > maxConfidence || mostConfidentLicense == "" || id < mostConfidentLicense {
+			maxConfidence = confidence
+			mostConfidentLicense = id
+		}
+	}
+
+	if mostConfidentLicense == "" {
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageDetect,
+			Component: component,
+			Message:   fmt.Sprintf("no license matches with confidence >= %.2f", threshold),
+		})
+		return validation.License{}, validation.ErrUnknownLicense
+	}
+
+	validation.RecordStep(ctx, validation.Step{
+		Stage:      validation.StageDetect,
+		Component:  component,
+		Message:    fmt.Sprintf("license detected with confidence %.2f", maxConfidence),
+		License:    mostConfidentLicense,
+		Confidence: maxConfidence,
+	})
+
+	licInfo, _ := spdx.LicenseByID(mostConfidentLicense)
+
+	return validation.License{
+		Name:   licInfo.Name,
+		SPDXID: mostConfidentLicense,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package detect_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/detect"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/api"
+)
+
+func TestBestMatch(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		matches     map[string]api.Match
+		expected    validation.License
+		expectedErr error
+		step        validation.Step
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var explanation validation.Explanation
+			license, err := detect.BestMatch(validation.WithExplanation(context.Background(), &explanation), "test", tc.matches, 0.8)
+			assert.Equal(t, tc.expected, license)
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, []validation.Step{tc.step}, explanation.Steps)
+		})
+	}
+
+	f(testCase{
+		name:        "no matches",
+		expectedErr: validation.ErrUnknownLicense,
+		step: validation.Step{
+			Stage:     validation.StageDetect,
+			Component: "test",
+			Message:   "no license matches with confidence >= 0.80",
+		},
+	})
+
+	f(testCase{
+		name: "below threshold",
+		matches: map[string]api.Match{
+			"MIT": {Confidence: 0.79},
+		},
+		expectedErr: validation.ErrUnknownLicense,
+		step: validation.Step{
+			Stage:     validation.StageDetect,
+			Component: "test",
+			Message:   "no license matches with confidence >= 0.80",
+		},
+	})
+
+	f(testCase{
+		name: "most confident",
+		matches: map[string]api.Match{
+			"MIT":        {Confidence: 0.9},
+			"Apache-2.0": {Confidence: 0.95},
+			"GPL-3.0":    {Confidence: 0.5},
+		},
+		expected: validation.License{Name: "Apache License 2.0", SPDXID: "Apache-2.0"},
+		step: validation.Step{
+			Stage:      validation.StageDetect,
+			Component:  "test",
+			Message:    "license detected with confidence 0.95",
+			License:    "Apache-2.0",
+			Confidence: float64(float32(0.95)),
+		},
+	})
+
+	f(testCase{
+		name: "equal confidence",
+		matches: map[string]api.Match{
+			"MIT":          {Confidence: 0.9},
+			"BSD-3-Clause": {Confidence: 0.9},
+		},
+		expected: validation.License{Name: `BSD 3-Clause "New" or "Revised" License`, SPDXID: "BSD-3-Clause"},
+		step: validation.Step{
+			Stage:      validation.StageDetect,
+			Component:  "test",
+			Message:    "license detected with confidence 0.90",
+			License:    "BSD-3-Clause",
+			Confidence: float64(float32(0.9)),
+		},
+	})
+}
@@ -0,0 +1,361 @@
+// Package git contains license resolver working with any git repository.
+// Repository is found by configured rules or go-get discovery, module version tag (or commit) is fetched with git
+// command into temporary directory and license is detected from files of fetched tree.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/detect"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+	"github.com/xakep666/licensevalidator/pkg/vcsref"
+
+	"go.uber.org/zap"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/api"
+)
+
+const (
+	// DefaultMaxSize is a default limit of fetched repository data size
+	DefaultMaxSize = 100 << 20
+
+	// DefaultTimeout is a default limit of repository fetch duration
+	DefaultTimeout = time.Minute
+
+	// sizeCheckInterval is a period of fetched data size checks
+	sizeCheckInterval = 100 * time.Millisecond
+)
+
+var (
+	// ErrRepoNotFound returned if repository for module can't be found
+	ErrRepoNotFound = fmt.Errorf("repository not found")
+
+	// ErrModuleNotFound returned if repository doesn't contain module version (tag, commit or directory)
+	ErrModuleNotFound = fmt.Errorf("module version not found in repository")
+
+	// ErrTooLarge returned if fetched repository data exceeds size limit
+	ErrTooLarge = fmt.Errorf("repository exceeds size limit")
+)
+
+type ClientParams struct {
+	// HTTPClient is used for go-get discovery
+	HTTPClient *http.Client
+
+	// Repos contains rules to find repository without go-get discovery. Rules are checked in order.
+	Repos []RepoRule
+
+	// DiscoveryHosts contains module path host glob patterns (path.Match syntax) for which go-get discovery is allowed.
+	// Discovery is disabled if empty. Discovered repositories may be fetched only over https, ssh or git protocol.
+	DiscoveryHosts []string
+
+	// GitBinary is a path to git executable. Default is "git".
+	GitBinary string
+
+	// TempDir is a directory for fetched repositories. Default is system temporary directory.
+	TempDir string
+
+	// MaxSize limits fetched repository data size in bytes. Default is DefaultMaxSize.
+	MaxSize int64
+
+	// Timeout limits repository fetch duration. Default is DefaultTimeout.
+	Timeout time.Duration
+
+	// ConfidenceThreshold is a lower bound threshold of license matching confidence
+	ConfidenceThreshold float64
+}
+
+type Client struct {
+	ClientParams
+
+	log    *zap.Logger
+	client *http.Client
+}
+
+func NewClient(logger *zap.Logger, params ClientParams) *Client {
+	hc := http.DefaultClient
+	if params.HTTPClient != nil {
+		hc = params.HTTPClient
+	}
+
+	if params.GitBinary == "" {
+		params.GitBinary = "git"
+	}
+
+	if params.MaxSize <= 0 {
+		params.MaxSize = DefaultMaxSize
+	}
+
+	if params.Timeout <= 0 {
+		params.Timeout = DefaultTimeout
+	}
+
+	return &Client{
+		ClientParams: params,
+		log:          logger.With(zap.String("component", "git_client")),
+		client:       hc,
+	}
+}
+
+// Name returns resolver name used as component in explanation steps
+func (*Client) Name() string { return "git" }
+
+// ResolveLicense fetches module version from repository and detects license from its files.
+// If module directory doesn't contain license, parent directories are checked up to repository root.
+// ErrUnknownLicense returned if repository or module version not found or repository is too large.
+func (c *Client) ResolveLicense(ctx context.Context, m validation.Module) (validation.License, error) {
+	root, dir, err := c.open(ctx, m)
+	switch {
+	case errors.Is(err, nil):
+		// pass
+	case errors.Is(err, ErrRepoNotFound), errors.Is(err, ErrModuleNotFound), errors.Is(err, ErrTooLarge):
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: c.Name(),
+			Message:   fmt.Sprintf("%s: %s", m.Name, err),
+		})
+		return validation.License{}, validation.ErrUnknownLicense
+	default:
+		return validation.License{}, err
+	}
+
+	defer root.Close()
+
+	for _, candidate := range vcsref.Parents(dir) {
+		licMatches, err := licensedb.Detect(root.sub(candidate))
+		switch {
+		case errors.Is(err, nil):
+			return c.licenseToReturn(ctx, m, licMatches)
+		case errors.Is(err, licensedb.ErrNoLicenseFound):
+			c.log.Debug("No license found in directory", zap.Stringer("module", &m), zap.String("dir", candidate))
+			continue
+		default:
+			return validation.License{}, fmt.Errorf("licensedb detect failure: %w", err)
+		}
+	}
+
+	return c.licenseToReturn(ctx, m, nil)
+}
+
+// Filer fetches module version from repository and returns filer over module directory.
+// ErrRepoNotFound, ErrModuleNotFound or ErrTooLarge returned if module can't be fetched.
+// Returned filer must be closed after usage to remove fetched data.
+func (c *Client) Filer(ctx context.Context, m validation.Module) (*TreeFiler, error) {
+	root, dir, err := c.open(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	tf := root.sub(dir)
+	tf.closer = root.closer
+
+	return tf, nil
+}
+
+// open fetches module version and returns filer over repository root and module directory inside it
+func (c *Client) open(ctx context.Context, m validation.Module) (*TreeFiler, string, error) {
+	repo, err := c.findRepo(ctx, m.Name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	loc := vcsref.Locate(repo.Root, m.Name)
+	ref := vcsref.FromVersion(loc.TagPrefix, m.Version)
+
+	l := c.log.With(zap.Stringer("module", &m), zap.String("repo", repo.URL), zap.Stringer("ref", ref))
+	l.Debug("Fetching module version")
+
+	tmp, err := ioutil.TempDir(c.TempDir, "licensevalidator-git")
+	if err != nil {
+		return nil, "", fmt.Errorf("temporary directory create failed: %w", err)
+	}
+
+	cleanup := func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			l.Warn("Temporary directory remove failed", zap.Error(err))
+		}
+	}
+
+	g := &gitCmd{binary: c.GitBinary, dir: tmp}
+	if !repo.trusted {
+		g.allowProtocol = allowedProtocols
+	}
+
+	rev, err := c.fetch(ctx, g, repo.URL, ref)
+	if err != nil {
+		cleanup()
+		return nil, "", err
+	}
+
+	root := &TreeFiler{git: g, rev: rev, closer: cleanup}
+
+	for _, dir := range loc.Dirs {
+		if dir == "" {
+			return root, dir, nil
+		}
+
+		typ, err := g.output(ctx, "cat-file", "-t", rev+":"+dir)
+		if err == nil && string(bytes.TrimSpace(typ)) == "tree" {
+			return root, dir, nil
+		}
+	}
+
+	cleanup()
+
+	return nil, "", fmt.Errorf("%w: directory %s", ErrModuleNotFound, strings.Join(loc.Dirs, " or "))
+}
+
+// fetch fetches reference into bare repository and returns revision to read
+func (c *Client) fetch(ctx context.Context, g *gitCmd, url string, ref vcsref.Ref) (string, error) {
+	if err := g.run(ctx, "init", "--bare", "-q"); err != nil {
+		return "", fmt.Errorf("repository init failed: %w", err)
+	}
+
+	if ref.Tag != "" {
+		tagRef := "refs/tags/" + ref.Tag
+
+		err := c.limitedFetch(ctx, g, "fetch", "-q", "--depth=1", "--no-tags", "--", url, "+"+tagRef+":"+tagRef)
+		switch {
+		case errors.Is(err, nil):
+			return tagRef, nil
+		case errors.Is(err, ErrTooLarge):
+			return "", err
+		case strings.Contains(err.Error(), "couldn't find remote ref"):
+			return "", fmt.Errorf("%w: tag %s", ErrModuleNotFound, ref.Tag)
+		default:
+			return "", fmt.Errorf("tag fetch failed: %w", err)
+		}
+	}
+
+	// abbreviated commit hash can't be fetched directly so all branches and tags are fetched
+	err := c.limitedFetch(ctx, g, "fetch", "-q", "--", url, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*")
+	if err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return "", err
+		}
+
+		return "", fmt.Errorf("repository fetch failed: %w", err)
+	}
+
+	rev, err := g.output(ctx, "rev-parse", "-q", "--verify", ref.Commit+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: commit %s", ErrModuleNotFound, ref.Commit)
+	}
+
+	return string(bytes.TrimSpace(rev)), nil
+}
+
+// limitedFetch runs git command terminating it if it takes too long or fetched data size exceeds limit
+func (c *Client) limitedFetch(ctx context.Context, g *gitCmd, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	var exceeded int32
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(sizeCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if dirSize(g.dir) > c.MaxSize {
+					atomic.StoreInt32(&exceeded, 1)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err := g.run(ctx, args...)
+	close(done)
+
+	if atomic.LoadInt32(&exceeded) == 1 || (err == nil && dirSize(g.dir) > c.MaxSize) {
+		return ErrTooLarge
+	}
+
+	return err
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+
+	_ = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size
+}
+
+func (c *Client) licenseToReturn(ctx context.Context, m validation.Module, matches map[string]api.Match) (validation.License, error) {
+	c.log.Debug(
+		"license detector success",
+		zap.Reflect("license_matches", matches),
+		zap.Stringer("module", &m),
+	)
+
+	return detect.BestMatch(ctx, c.Name(), matches, c.ConfidenceThreshold)
+}
+
+// Check ensures that git executable is available
+func (c *Client) Check(ctx context.Context) error {
+	if _, err := (&gitCmd{binary: c.GitBinary}).output(ctx, "--version"); err != nil {
+		return fmt.Errorf("git executable check failed: %w", err)
+	}
+
+	return nil
+}
+
+// gitCmd runs git commands in directory
+type gitCmd struct {
+	binary string
+	dir    string
+
+	// allowProtocol restricts transports used by git (GIT_ALLOW_PROTOCOL), not restricted if empty
+	allowProtocol string
+}
+
+func (g *gitCmd) run(ctx context.Context, args ...string) error {
+	_, err := g.output(ctx, args...)
+	return err
+}
+
+func (g *gitCmd) output(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, g.binary, args...)
+	cmd.Dir = g.dir
+	// never ask for credentials interactively
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if g.dir != "" {
+		cmd.Env = append(cmd.Env, "GIT_DIR="+g.dir)
+	}
+	if g.allowProtocol != "" {
+		cmd.Env = append(cmd.Env, "GIT_ALLOW_PROTOCOL="+g.allowProtocol)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,329 @@
+package git_test
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/git"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// testRepo is a local bare repository with:
+// * root module tagged v1.0.0 with MIT license
+// * module in "sub" directory tagged sub/v1.2.0 without own license
+// * untagged commit which is referred by pseudo-version
+type testRepo struct {
+	bare   string
+	commit string
+}
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+
+	return strings.TrimSpace(string(out))
+}
+
+func mitLicense(t *testing.T) []byte {
+	zr, err := zip.OpenReader("../goproxy/testdata/testify-1.5.1.zip")
+	require.NoError(t, err)
+
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != "github.com/stretchr/testify@v1.5.1/LICENSE" {
+			continue
+		}
+
+		rd, err := f.Open()
+		require.NoError(t, err)
+
+		defer rd.Close()
+
+		license, err := ioutil.ReadAll(rd)
+		require.NoError(t, err)
+
+		return license
+	}
+
+	t.Fatal("license not found")
+	return nil
+}
+
+func prepareRepo(t *testing.T) *testRepo {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir, err := ioutil.TempDir("", "git-test")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	work, bare := filepath.Join(dir, "work"), filepath.Join(dir, "repo.git")
+	require.NoError(t, os.MkdirAll(filepath.Join(work, "sub"), 0755))
+
+	gitRun(t, work, "init", "-q")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(work, "LICENSE"), mitLicense(t), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(work, "go.mod"), []byte("module example.com/repo\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(work, "sub", "go.mod"), []byte("module example.com/repo/sub\n"), 0644))
+	gitRun(t, work, "add", "-A")
+	gitRun(t, work, "commit", "-q", "-m", "initial")
+	gitRun(t, work, "tag", "v1.0.0")
+	gitRun(t, work, "tag", "sub/v1.2.0")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(work, "README"), []byte("readme\n"), 0644))
+	gitRun(t, work, "add", "-A")
+	gitRun(t, work, "commit", "-q", "-m", "readme")
+
+	gitRun(t, dir, "clone", "-q", "--bare", work, bare)
+
+	return &testRepo{bare: bare, commit: gitRun(t, work, "rev-parse", "HEAD")}
+}
+
+func TestClient_ResolveLicense(t *testing.T) {
+	t.Parallel()
+
+	repo := prepareRepo(t)
+
+	client := git.NewClient(zaptest.NewLogger(t), git.ClientParams{
+		Repos: []git.RepoRule{
+			{Match: regexp.MustCompile(`^example.com/repo`), URL: repo.bare},
+		},
+		ConfidenceThreshold: 0.8,
+	})
+
+	mit := validation.License{Name: "MIT License", SPDXID: "MIT"}
+
+	type testCase struct {
+		Name            string
+		Module          string
+		Version         string
+		ExpectedLicense validation.License
+		ExpectedError   error
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			lic, err := client.ResolveLicense(context.Background(), validation.Module{
+				Name:    tc.Module,
+				Version: semver.MustParse(tc.Version),
+			})
+			if tc.ExpectedError != nil {
+				assert.Equal(t, tc.ExpectedError, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.ExpectedLicense, lic)
+			}
+		})
+	}
+
+	f(testCase{Name: "tag", Module: "example.com/repo", Version: "v1.0.0", ExpectedLicense: mit})
+	f(testCase{Name: "subdirectory license from root", Module: "example.com/repo/sub", Version: "v1.2.0", ExpectedLicense: mit})
+	f(testCase{
+		Name:            "pseudo-version",
+		Module:          "example.com/repo",
+		Version:         fmt.Sprintf("v1.0.1-0.20200601120000-%s", repo.commit[:12]),
+		ExpectedLicense: mit,
+	})
+	f(testCase{Name: "unknown tag", Module: "example.com/repo", Version: "v2.0.0+incompatible", ExpectedError: validation.ErrUnknownLicense})
+	f(testCase{Name: "unknown directory", Module: "example.com/repo/other", Version: "v1.0.0", ExpectedError: validation.ErrUnknownLicense})
+	f(testCase{Name: "no repository", Module: "invalid.example", Version: "v1.0.0", ExpectedError: validation.ErrUnknownLicense})
+}
+
+// discoveryServer serves go-get discovery page with given repository url template (%s is replaced with server host)
+// and repositories from dir using git smart http protocol
+func discoveryServer(t *testing.T, dir, repoURL string) (*httptest.Server, *int32) {
+	var discoveries int32
+
+	gitPath, err := exec.LookPath("git")
+	require.NoError(t, err)
+
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + dir, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("go-get") != "1" {
+			backend.ServeHTTP(w, r)
+			return
+		}
+
+		atomic.AddInt32(&discoveries, 1)
+
+		_, _ = fmt.Fprintf(w, `<html><head>
+<meta name="go-import" content="%[1]s/repo mod https://proxy.example.com">
+<meta name="go-import" content="%[1]s/repo git %[2]s">
+</head><body>go get %[1]s/repo</body></html>`, r.Host, html.EscapeString(fmt.Sprintf(repoURL, r.Host)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &discoveries
+}
+
+func TestClient_ResolveLicense_Discovery(t *testing.T) {
+	t.Parallel()
+
+	repo := prepareRepo(t)
+
+	// test server certificate is not trusted by git
+	os.Setenv("GIT_SSL_NO_VERIFY", "1")
+	defer os.Unsetenv("GIT_SSL_NO_VERIFY")
+
+	server, _ := discoveryServer(t, filepath.Dir(repo.bare), "https://%s/repo.git")
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	client := git.NewClient(zaptest.NewLogger(t), git.ClientParams{
+		HTTPClient:          server.Client(),
+		DiscoveryHosts:      []string{"127.0.0.1:*"},
+		ConfidenceThreshold: 0.8,
+	})
+
+	lic, err := client.ResolveLicense(context.Background(), validation.Module{
+		Name:    host + "/repo/sub",
+		Version: semver.MustParse("v1.2.0"),
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+	}
+}
+
+func TestClient_ResolveLicense_DiscoveryUnsafe(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir, err := ioutil.TempDir("", "git-test")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	marker := filepath.Join(dir, "marker")
+
+	type testCase struct {
+		Name           string
+		RepoURL        string
+		DiscoveryHosts []string
+		Discoveries    int32
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			server, discoveries := discoveryServer(t, dir, tc.RepoURL)
+			host := strings.TrimPrefix(server.URL, "https://")
+
+			client := git.NewClient(zaptest.NewLogger(t), git.ClientParams{
+				HTTPClient:     server.Client(),
+				DiscoveryHosts: tc.DiscoveryHosts,
+			})
+
+			var explanation validation.Explanation
+			_, err := client.ResolveLicense(validation.WithExplanation(context.Background(), &explanation), validation.Module{
+				Name:    host + "/repo",
+				Version: semver.MustParse("v1.0.0"),
+			})
+			assert.Equal(t, validation.ErrUnknownLicense, err)
+			assert.Equal(t, tc.Discoveries, atomic.LoadInt32(discoveries))
+			if assert.Len(t, explanation.Steps, 1) {
+				assert.Equal(t, host+"/repo: repository not found", explanation.Steps[0].Message)
+			}
+
+			_, err = os.Stat(marker)
+			assert.True(t, os.IsNotExist(err), "command injected with repository url was executed")
+		})
+	}
+
+	allowed := []string{"127.0.0.1:*"}
+
+	f(testCase{Name: "option", RepoURL: "--upload-pack=touch " + marker, DiscoveryHosts: allowed, Discoveries: 1})
+	f(testCase{Name: "ext transport", RepoURL: "ext::sh -c touch% " + marker, DiscoveryHosts: allowed, Discoveries: 1})
+	f(testCase{Name: "file scheme", RepoURL: "file://" + dir, DiscoveryHosts: allowed, Discoveries: 1})
+	f(testCase{Name: "local path", RepoURL: dir, DiscoveryHosts: allowed, Discoveries: 1})
+	f(testCase{Name: "ssh option host", RepoURL: "ssh://-oProxyCommand=touch%20" + marker + "/repo", DiscoveryHosts: allowed, Discoveries: 1})
+	f(testCase{Name: "host not allowed", RepoURL: "https://%s/repo.git", DiscoveryHosts: []string{"example.com"}})
+	f(testCase{Name: "discovery disabled", RepoURL: "https://%s/repo.git"})
+}
+
+func TestClient_ResolveLicense_SizeLimit(t *testing.T) {
+	t.Parallel()
+
+	repo := prepareRepo(t)
+
+	client := git.NewClient(zaptest.NewLogger(t), git.ClientParams{
+		Repos: []git.RepoRule{
+			{Match: regexp.MustCompile(`^example.com/repo`), URL: repo.bare},
+		},
+		MaxSize: 1,
+	})
+
+	var explanation validation.Explanation
+	_, err := client.ResolveLicense(validation.WithExplanation(context.Background(), &explanation), validation.Module{
+		Name:    "example.com/repo",
+		Version: semver.MustParse("v1.0.0"),
+	})
+	assert.Equal(t, validation.ErrUnknownLicense, err)
+	if assert.Len(t, explanation.Steps, 1) {
+		assert.Equal(t, "example.com/repo: repository exceeds size limit", explanation.Steps[0].Message)
+	}
+}
+
+func TestClient_Filer(t *testing.T) {
+	t.Parallel()
+
+	repo := prepareRepo(t)
+
+	client := git.NewClient(zaptest.NewLogger(t), git.ClientParams{
+		Repos: []git.RepoRule{
+			{Match: regexp.MustCompile(`^example.com/repo`), URL: repo.bare},
+		},
+	})
+
+	fs, err := client.Filer(context.Background(), validation.Module{
+		Name:    "example.com/repo",
+		Version: semver.MustParse("v1.0.0"),
+	})
+	require.NoError(t, err)
+
+	defer fs.Close()
+
+	files, err := fs.ReadDir("")
+	if assert.NoError(t, err) {
+		names := make([]string, 0, len(files))
+		for _, file := range files {
+			names = append(names, fmt.Sprintf("%s:%t", file.Name, file.IsDir))
+		}
+
+		assert.ElementsMatch(t, []string{"LICENSE:false", "go.mod:false", "sub:true"}, names)
+	}
+
+	content, err := fs.ReadFile("sub/go.mod")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "module example.com/repo/sub\n", string(content))
+	}
+
+	_, err = fs.ReadFile("missing")
+	assert.True(t, os.IsNotExist(err))
+}
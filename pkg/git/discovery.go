@@ -0,0 +1,220 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// RepoRule maps module paths to repository url
+type RepoRule struct {
+	// Match is matched against beginning of module path, matched part is a repository root
+	// (i.e. "^git.mycorp.com/[^/]+/[^/]+")
+	Match *regexp.Regexp
+
+	// URL is a repository url template, regexp capturing group placeholders (i.e $1, $2) may be used here
+	// (i.e. "ssh://git@git.mycorp.com/$1.git")
+	URL string
+}
+
+// Repo is a repository containing module
+type Repo struct {
+	// Root is a module path prefix corresponding to repository root
+	Root string
+
+	// URL is a repository url suitable for git fetch
+	URL string
+
+	// trusted is true for repository url taken from configured rules, such url may use any git transport
+	trusted bool
+}
+
+var (
+	metaTagRe      = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttrRe     = regexp.MustCompile(`(?is)(name|content)\s*=\s*("[^"]*"|'[^']*')`)
+	metaBodyStopRe = regexp.MustCompile(`(?i)<body`)
+
+	// scpLikeURLRe matches scp-like ssh url syntax (i.e. "git@github.com:user/repo.git")
+	scpLikeURLRe = regexp.MustCompile(`^(?:[\w.\-]+@)?\w[\w.\-]*:[^:]`)
+)
+
+// allowedProtocols are git transports allowed for repository urls not taken from configured rules
+const allowedProtocols = "https:ssh:git"
+
+// maxDiscoveryResponse limits size of go-get discovery response
+const maxDiscoveryResponse = 1 << 20
+
+// findRepo finds repository by configured rules, ".git" path component or go-get discovery.
+// ErrRepoNotFound returned if repository can't be found.
+func (c *Client) findRepo(ctx context.Context, modulePath string) (Repo, error) {
+	for _, rule := range c.Repos {
+		loc := rule.Match.FindStringSubmatchIndex(modulePath)
+		if loc == nil || loc[0] != 0 {
+			continue
+		}
+
+		root := modulePath[:loc[1]]
+		if root != modulePath && !strings.HasPrefix(modulePath, strings.TrimSuffix(root, "/")+"/") {
+			continue
+		}
+
+		url := rule.Match.ExpandString(nil, rule.URL, modulePath, loc)
+
+		return Repo{Root: strings.TrimSuffix(root, "/"), URL: string(url), trusted: true}, nil
+	}
+
+	// the same as go command does: path element ending with ".git" is a repository root
+	parts := strings.Split(modulePath, "/")
+	for i, part := range parts {
+		if i > 0 && strings.HasSuffix(part, ".git") {
+			root := strings.Join(parts[:i+1], "/")
+			return Repo{Root: root, URL: "https://" + root}, nil
+		}
+	}
+
+	return c.discover(ctx, modulePath)
+}
+
+// discover performs go-get discovery (https://golang.org/cmd/go/#hdr-Remote_import_paths)
+// for module path hosts matching one of DiscoveryHosts patterns.
+func (c *Client) discover(ctx context.Context, modulePath string) (Repo, error) {
+	if !c.discoveryAllowed(modulePath) {
+		c.log.Debug("Go-get discovery not allowed for module host", zap.String("module", modulePath))
+		return Repo{}, ErrRepoNotFound
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+modulePath+"?go-get=1", nil)
+	if err != nil {
+		return Repo{}, fmt.Errorf("discovery request construct failed: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.log.Info("Go-get discovery failed", zap.String("module", modulePath), zap.Error(err))
+		return Repo{}, ErrRepoNotFound
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.log.Info("Go-get discovery returned non-ok status", zap.String("module", modulePath), zap.Int("status", resp.StatusCode))
+		return Repo{}, ErrRepoNotFound
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxDiscoveryResponse))
+	if err != nil {
+		return Repo{}, fmt.Errorf("discovery response read failed: %w", err)
+	}
+
+	for _, imp := range parseGoImports(string(body)) {
+		if imp.vcs != "git" {
+			continue
+		}
+
+		if imp.prefix != modulePath && !strings.HasPrefix(modulePath, imp.prefix+"/") {
+			continue
+		}
+
+		if err := checkRepoURL(imp.repo); err != nil {
+			c.log.Warn("Go-get discovery returned unsafe repository url",
+				zap.String("module", modulePath), zap.String("repo", imp.repo), zap.Error(err))
+			return Repo{}, ErrRepoNotFound
+		}
+
+		return Repo{Root: imp.prefix, URL: imp.repo}, nil
+	}
+
+	return Repo{}, ErrRepoNotFound
+}
+
+func (c *Client) discoveryAllowed(modulePath string) bool {
+	host := strings.ToLower(strings.SplitN(modulePath, "/", 2)[0])
+	for _, pattern := range c.DiscoveryHosts {
+		if matched, _ := path.Match(strings.ToLower(pattern), host); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRepoURL ensures that repository url from untrusted source uses one of allowed protocols
+// and can't be interpreted as git command option
+func checkRepoURL(repoURL string) error {
+	if strings.HasPrefix(repoURL, "-") {
+		return fmt.Errorf("url %q looks like an option", repoURL)
+	}
+
+	if !strings.Contains(repoURL, "://") {
+		if scpLikeURLRe.MatchString(repoURL) {
+			return nil
+		}
+
+		return fmt.Errorf("url %q has unsupported syntax", repoURL)
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return fmt.Errorf("url %q parse failed: %w", repoURL, err)
+	}
+
+	switch u.Scheme {
+	case "https", "ssh", "git":
+		// pass
+	default:
+		return fmt.Errorf("url %q has not allowed scheme %q", repoURL, u.Scheme)
+	}
+
+	if u.Host == "" || strings.HasPrefix(u.Host, "-") {
+		return fmt.Errorf("url %q has invalid host", repoURL)
+	}
+
+	return nil
+}
+
+type goImport struct {
+	prefix, vcs, repo string
+}
+
+// parseGoImports extracts go-import meta tags from html document head
+func parseGoImports(doc string) []goImport {
+	if loc := metaBodyStopRe.FindStringIndex(doc); loc != nil {
+		doc = doc[:loc[0]]
+	}
+
+	var ret []goImport
+	for _, tag := range metaTagRe.FindAllString(doc, -1) {
+		var name, content string
+		for _, attr := range metaAttrRe.FindAllStringSubmatch(tag, -1) {
+			value := html.UnescapeString(attr[2][1 : len(attr[2])-1])
+			switch strings.ToLower(attr[1]) {
+			case "name":
+				name = value
+			case "content":
+				content = value
+			}
+		}
+
+		if name != "go-import" {
+			continue
+		}
+
+		fields := strings.Fields(content)
+		if len(fields) != 3 {
+			continue
+		}
+
+		ret = append(ret, goImport{prefix: fields[0], vcs: fields[1], repo: fields[2]})
+	}
+
+	return ret
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// TreeFiler is an implementation of filesystem for src-d license scanner over git tree.
+// Files are read with "git ls-tree" and "git cat-file" without checkout.
+type TreeFiler struct {
+	git *gitCmd
+
+	// rev is a tree-ish (commit, tag) to read
+	rev string
+
+	// prefix is a directory inside tree which is used as filer root
+	prefix string
+
+	closer func()
+}
+
+func (tf *TreeFiler) fullPath(p string) string {
+	return strings.Trim(path.Join(tf.prefix, p), "/")
+}
+
+func (tf *TreeFiler) ReadFile(p string) ([]byte, error) {
+	out, err := tf.git.output(context.Background(), "cat-file", "blob", tf.rev+":"+tf.fullPath(p))
+	if err != nil {
+		return nil, &os.PathError{
+			Op:   "open",
+			Path: p,
+			Err:  os.ErrNotExist,
+		}
+	}
+
+	return out, nil
+}
+
+func (tf *TreeFiler) ReadDir(p string) ([]filer.File, error) {
+	dir := tf.fullPath(p)
+
+	args := []string{"ls-tree", "-z", tf.rev}
+	if dir != "" {
+		args = append(args, "--", dir+"/")
+	}
+
+	out, err := tf.git.output(context.Background(), args...)
+	if err != nil {
+		return nil, &os.PathError{
+			Op:   "readdir",
+			Path: p,
+			Err:  err,
+		}
+	}
+
+	var result []filer.File
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		// <mode> SP <type> SP <object> TAB <file>
+		idx := bytes.IndexByte(entry, '\t')
+		if idx < 0 {
+			continue
+		}
+
+		fields := strings.Fields(string(entry[:idx]))
+		if len(fields) != 3 {
+			continue
+		}
+
+		switch fields[1] {
+		case "blob", "tree":
+			result = append(result, filer.File{
+				Name:  path.Base(string(entry[idx+1:])),
+				IsDir: fields[1] == "tree",
+			})
+		default:
+			// submodules are not interesting
+		}
+	}
+
+	if len(result) == 0 && dir != "" {
+		return nil, &os.PathError{
+			Op:   "readdir",
+			Path: p,
+			Err:  os.ErrNotExist,
+		}
+	}
+
+	return result, nil
+}
+
+// Close removes fetched repository data
+func (tf *TreeFiler) Close() {
+	if tf.closer != nil {
+		tf.closer()
+	}
+}
+
+func (tf *TreeFiler) PathsAreAlwaysSlash() bool { return true }
+
+// sub returns filer over sub directory sharing fetched data with parent
+func (tf *TreeFiler) sub(dir string) *TreeFiler {
+	return &TreeFiler{git: tf.git, rev: tf.rev, prefix: tf.fullPath(dir)}
+}
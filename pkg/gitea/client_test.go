@@ -0,0 +1,239 @@
+package gitea_test
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/gitea"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const testToken = "secret"
+
+func mitLicense(t *testing.T) []byte {
+	zr, err := zip.OpenReader("../goproxy/testdata/testify-1.5.1.zip")
+	require.NoError(t, err)
+
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != "github.com/stretchr/testify@v1.5.1/LICENSE" {
+			continue
+		}
+
+		rd, err := f.Open()
+		require.NoError(t, err)
+
+		defer rd.Close()
+
+		license, err := ioutil.ReadAll(rd)
+		require.NoError(t, err)
+
+		return license
+	}
+
+	t.Fatal("license not found")
+	return nil
+}
+
+type entry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// giteaServer emulates Gitea API with repository "owner/repo" containing:
+// * root module tagged v1.0.0 with MIT license
+// * module "pkg/v2" stored in "pkg" directory tagged pkg/v2.1.0 without own license
+func giteaServer(t *testing.T) *httptest.Server {
+	license := mitLicense(t)
+
+	trees := map[string][]entry{
+		"v1.0.0":         {{Name: "LICENSE", Type: "file"}, {Name: "go.mod", Type: "file"}},
+		"pkg/v2.1.0":     {{Name: "LICENSE", Type: "file"}, {Name: "pkg", Type: "dir"}},
+		"pkg/v2.1.0/pkg": {{Name: "go.mod", Type: "file"}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		ref := r.URL.Query().Get("ref")
+		switch path := r.URL.Path; {
+		case path == "/api/v1/user":
+			json.NewEncoder(w).Encode(map[string]string{"login": "test"})
+		case path == "/api/v1/repos/owner/repo":
+			json.NewEncoder(w).Encode(map[string]string{"full_name": "owner/repo"})
+		case strings.HasPrefix(path, "/api/v1/repos/owner/repo/contents"):
+			key := ref
+			if dir := strings.Trim(strings.TrimPrefix(path, "/api/v1/repos/owner/repo/contents"), "/"); dir != "" {
+				key += "/" + dir
+			}
+
+			entries, ok := trees[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			json.NewEncoder(w).Encode(entries)
+		case path == "/api/v1/repos/owner/repo/raw/LICENSE":
+			if _, ok := trees[ref]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Write(license)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestClient_ResolveLicense(t *testing.T) {
+	t.Parallel()
+
+	srv := giteaServer(t)
+
+	client, err := gitea.NewClient(zaptest.NewLogger(t), gitea.ClientParams{
+		HTTPClient:          srv.Client(),
+		BaseURL:             srv.URL,
+		Hosts:               []string{"gitea.mycorp.com"},
+		Token:               testToken,
+		ConfidenceThreshold: 0.8,
+	})
+	require.NoError(t, err)
+
+	type testCase struct {
+		name    string
+		module  string
+		version string
+		license validation.License
+		err     error
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			lic, err := client.ResolveLicense(context.Background(), validation.Module{
+				Name:    tc.module,
+				Version: semver.MustParse(tc.version),
+			})
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.license, lic)
+			}
+		})
+	}
+
+	f(testCase{
+		name:    "root module",
+		module:  "gitea.mycorp.com/owner/repo",
+		version: "v1.0.0",
+		license: validation.License{Name: "MIT License", SPDXID: "MIT"},
+	})
+	f(testCase{
+		name:    "major version directory with parent license",
+		module:  "gitea.mycorp.com/owner/repo/pkg/v2",
+		version: "v2.1.0",
+		license: validation.License{Name: "MIT License", SPDXID: "MIT"},
+	})
+	f(testCase{
+		name:    "unknown tag",
+		module:  "gitea.mycorp.com/owner/repo",
+		version: "v1.1.0",
+		err:     validation.ErrUnknownLicense,
+	})
+	f(testCase{
+		name:    "unknown repository",
+		module:  "gitea.mycorp.com/owner/other",
+		version: "v1.0.0",
+		err:     validation.ErrUnknownLicense,
+	})
+	f(testCase{
+		name:    "other host",
+		module:  "github.com/owner/repo",
+		version: "v1.0.0",
+		err:     validation.ErrUnknownLicense,
+	})
+}
+
+func TestClient_Filer(t *testing.T) {
+	t.Parallel()
+
+	srv := giteaServer(t)
+
+	client, err := gitea.NewClient(zaptest.NewLogger(t), gitea.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Hosts:      []string{"gitea.mycorp.com"},
+		Token:      testToken,
+	})
+	require.NoError(t, err)
+
+	fs, err := client.Filer(context.Background(), validation.Module{
+		Name:    "gitea.mycorp.com/owner/repo",
+		Version: semver.MustParse("v1.0.0"),
+	})
+	require.NoError(t, err)
+
+	files, err := fs.ReadDir("")
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	content, err := fs.ReadFile("LICENSE")
+	require.NoError(t, err)
+	assert.Equal(t, mitLicense(t), content)
+
+	_, err = client.Filer(context.Background(), validation.Module{
+		Name:    "gitea.mycorp.com/owner/repo",
+		Version: semver.MustParse("v1.1.0"),
+	})
+	assert.True(t, errors.Is(err, gitea.ErrModuleNotFound), "unexpected error: %v", err)
+}
+
+func TestClient_Check(t *testing.T) {
+	t.Parallel()
+
+	srv := giteaServer(t)
+
+	client, err := gitea.NewClient(zaptest.NewLogger(t), gitea.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Token:      testToken,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, client.Check(context.Background()))
+
+	client, err = gitea.NewClient(zaptest.NewLogger(t), gitea.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Token:      "invalid",
+	})
+	require.NoError(t, err)
+	assert.Error(t, client.Check(context.Background()))
+}
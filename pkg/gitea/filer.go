@@ -0,0 +1,73 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/xakep666/licensevalidator/pkg/apifiler"
+
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// apiRepository reads repository tree through Gitea contents and raw API
+type apiRepository struct {
+	client *Client
+
+	// repoPath is an API path of repository (/repos/{owner}/{repo})
+	repoPath string
+
+	// ref is a branch, tag or commit to read
+	ref string
+}
+
+func (r *apiRepository) OpenFile(ctx context.Context, p string) (io.ReadCloser, error) {
+	resp, err := r.client.do(ctx, r.repoPath+"/raw/"+apifiler.EscapePath(p), url.Values{"ref": {r.ref}})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+type contentsEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func (r *apiRepository) ListDir(ctx context.Context, p string) ([]filer.File, error) {
+	apiPath := r.repoPath + "/contents"
+	if p != "" {
+		apiPath += "/" + apifiler.EscapePath(p)
+	}
+
+	resp, err := r.client.do(ctx, apiPath, url.Values{"ref": {r.ref}})
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	// contents of file is an object, not a list
+	var entries []contentsEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("%w: not a directory: %s", errNotFound, err)
+	}
+
+	var result []filer.File
+	for _, entry := range entries {
+		switch entry.Type {
+		case "file", "dir":
+			result = append(result, filer.File{
+				Name:  entry.Name,
+				IsDir: entry.Type == "dir",
+			})
+		default:
+			// symlinks and submodules are not interesting
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,239 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+	"github.com/xakep666/licensevalidator/pkg/vcsref"
+
+	"github.com/google/go-github/v18/github"
+	"go.uber.org/zap"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/api"
+)
+
+// maxFetchRounds limits number of repository files fetch rounds of ResolveLicenses
+const maxFetchRounds = 8
+
+// target is a github module prepared for resolution
+type target struct {
+	module      validation.Module
+	owner, repo string
+	ref         vcsref.Ref
+
+	// dirs are candidates of module directory inside repository
+	dirs []string
+}
+
+// ResolveLicenses detects licenses of modules the same way as ResolveLicense does. Results have the same order as modules.
+// Without GraphQL license of module in repository root is taken from github license API at module version reference
+// and repository files are checked only if github can't tell it.
+// Repository files are fetched in rounds: each round all modules are processed with files fetched so far
+// and files required by them are fetched at once (with single GraphQL query if enabled).
+func (c *Client) ResolveLicenses(ctx context.Context, modules []validation.Module) []validation.LicenseResult {
+	var (
+		results = make([]validation.LicenseResult, len(modules))
+		targets = make([]target, len(modules))
+		pending []int
+	)
+
+	for i, m := range modules {
+		t, ok := c.target(m)
+		if !ok {
+			c.log.Debug("not a github module", zap.Stringer("module", &m))
+			results[i].Err = validation.ErrUnknownLicense
+			continue
+		}
+
+		if !c.GraphQL && len(t.dirs) == 1 && t.dirs[0] == "" {
+			lic, ok, err := c.resolveAtRef(ctx, t)
+			if ok || err != nil {
+				results[i].License, results[i].Err = lic, err
+				continue
+			}
+		}
+
+		targets[i] = t
+		pending = append(pending, i)
+	}
+
+	var (
+		store    = make(map[objectKey]*object)
+		licenses = make(map[repoKey]*github.License)
+	)
+	for round := 0; len(pending) > 0; round++ {
+		var (
+			next   []int
+			misses = make(map[objectKey]bool)
+		)
+
+		for _, i := range pending {
+			targetMisses := make(map[objectKey]bool)
+			matches, err := c.detect(targets[i], store, targetMisses)
+			if len(targetMisses) == 0 {
+				results[i].License, results[i].Err = c.finish(ctx, targets[i], matches, err, licenses)
+				continue
+			}
+
+			for key := range targetMisses {
+				misses[key] = true
+			}
+
+			next = append(next, i)
+		}
+
+		if len(next) == 0 {
+			break
+		}
+
+		if round == maxFetchRounds {
+			for _, i := range next {
+				results[i].Err = fmt.Errorf("repository files fetch rounds limit (%d) exceeded", maxFetchRounds)
+			}
+
+			break
+		}
+
+		keys := make([]objectKey, 0, len(misses))
+		for key := range misses {
+			keys = append(keys, key)
+		}
+
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		c.log.Debug("Fetching repository files", zap.Int("round", round), zap.Int("modules", len(next)), zap.Int("files", len(keys)))
+
+		var objects map[objectKey]*object
+		if c.GraphQL {
+			var repoLicenses map[repoKey]*github.License
+			objects, repoLicenses = c.fetchGraphQL(ctx, keys)
+			for repo, license := range repoLicenses {
+				licenses[repo] = license
+			}
+		} else {
+			objects = c.fetchContents(ctx, keys)
+		}
+
+		for key, obj := range objects {
+			store[key] = obj
+		}
+
+		pending = next
+	}
+
+	return results
+}
+
+// target parses module path, false returned if module is not served by client
+func (c *Client) target(m validation.Module) (target, bool) {
+	matches := githubRe.FindStringSubmatch(m.Name)
+	if len(matches) == 0 || !c.servesHost(matches[2]) {
+		return target{}, false
+	}
+
+	loc := vcsref.Locate(matches[1], m.Name)
+
+	return target{
+		module: m,
+		owner:  matches[3],
+		repo:   matches[4],
+		ref:    vcsref.FromVersion(loc.TagPrefix, m.Version),
+		dirs:   loc.Dirs,
+	}, true
+}
+
+// detect detects license from fetched repository files at module reference.
+// First existing directory from candidates is a module directory, license is looked up there and in its parents.
+// Files required for detection which are not fetched yet are added to misses, result must be discarded in this case.
+func (c *Client) detect(t target, store map[objectKey]*object, misses map[objectKey]bool) (map[string]api.Match, error) {
+	root := &storeFiler{store: store, misses: misses, root: objectKey{owner: t.owner, repo: t.repo, ref: t.ref.String()}}
+
+	// ensure that reference and module directory exist to distinguish it from missing license
+	dir, found := "", false
+	for _, candidate := range t.dirs {
+		obj, ok := root.lookup(candidate)
+		switch {
+		case !ok:
+			// all candidates are fetched at once
+			continue
+		case obj.err != nil:
+			return nil, obj.err
+		case obj.exists && obj.isDir:
+			dir, found = candidate, true
+		default:
+			continue
+		}
+
+		break
+	}
+
+	switch {
+	case len(misses) > 0:
+		return nil, errNotFetched
+	case !found:
+		return nil, errRefNotFound
+	}
+
+	for _, candidate := range vcsref.Parents(dir) {
+		licMatches, err := licensedb.Detect(root.sub(candidate))
+		switch {
+		case len(misses) > 0:
+			// detection is incomplete, collect files of parents too to reduce number of rounds
+			continue
+		case errors.Is(err, nil):
+			return licMatches, nil
+		case errors.Is(err, licensedb.ErrNoLicenseFound):
+			c.log.Debug("No license found in directory", zap.Stringer("module", &t.module), zap.String("dir", candidate))
+			continue
+		default:
+			return nil, fmt.Errorf("license detector failed: %w", err)
+		}
+	}
+
+	return nil, licensedb.ErrNoLicenseFound
+}
+
+// finish converts detection result to license.
+// Default branch licenses of repositories fetched with GraphQL (licenseInfo) are used for default branch fallback.
+func (c *Client) finish(
+	ctx context.Context,
+	t target,
+	matches map[string]api.Match,
+	err error,
+	licenses map[repoKey]*github.License,
+) (validation.License, error) {
+	m := t.module
+
+	switch {
+	case errors.Is(err, nil), errors.Is(err, licensedb.ErrNoLicenseFound):
+		return c.licenseToReturn(ctx, m, matches)
+	case errors.Is(err, errRefNotFound) && c.DefaultBranchFallback && usableLicense(licenses[repoKey{owner: t.owner, repo: t.repo}]):
+		license := licenses[repoKey{owner: t.owner, repo: t.repo}]
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: c.Name(),
+			Message:   fmt.Sprintf("%s: %s (ref %s), using default branch license", m.Name, errRefNotFound, t.ref),
+			License:   license.GetSPDXID(),
+		})
+		return validation.License{Name: license.GetName(), SPDXID: license.GetSPDXID()}, nil
+	case errors.Is(err, errRefNotFound) && c.DefaultBranchFallback:
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: c.Name(),
+			Message:   fmt.Sprintf("%s: %s (ref %s), using default branch license", m.Name, errRefNotFound, t.ref),
+		})
+		return c.resolveDefaultBranch(ctx, c.log.With(zap.Stringer("module", &m)), m, t.owner, t.repo)
+	case errors.Is(err, errRefNotFound):
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: c.Name(),
+			Message:   fmt.Sprintf("%s: %s (ref %s)", m.Name, errRefNotFound, t.ref),
+		})
+		return validation.License{}, validation.ErrUnknownLicense
+	default:
+		return validation.License{}, err
+	}
+}
@@ -4,122 +4,286 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"path"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/xakep666/licensevalidator/pkg/spdx"
+	"github.com/xakep666/licensevalidator/pkg/detect"
 	"github.com/xakep666/licensevalidator/pkg/validation"
 
 	"github.com/google/go-github/v18/github"
 	"go.uber.org/zap"
 	"gopkg.in/src-d/go-license-detector.v3/licensedb"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/api"
 )
 
-var githubRe = regexp.MustCompile(`^github\.com/([^/]+)/([^/]+)$`)
+// githubRe matches module path: host/owner/repo with optional module sub directory and major version suffix
+var githubRe = regexp.MustCompile(`^(([^/]+)/([^/]+)/([^/]+))(/.+)?$`)
+
+// DefaultHost is a default module path host served by client
+const DefaultHost = "github.com"
 
 type ClientParams struct {
 	Client *github.Client
 
-	// FallbackConfidenceThreshold is a confidence threshold for go-license-detector fallback
-	// This fallback used when github returns "other" as license name
+	// Host is a module path host glob pattern (path.Match syntax) served by client. Default is DefaultHost.
+	// GitHub Enterprise Server client should have its own host here (i.e. "ghe.corp.example").
+	Host string
+
+	// FallbackConfidenceThreshold is a confidence threshold for go-license-detector.
+	// Detector is used for files at module version and when github returns "other" as license name.
 	FallbackConfidenceThreshold float64
+
+	// DefaultBranchFallback enables license lookup on repository default branch (Repositories.License)
+	// when module version tag or commit not found in repository.
+	DefaultBranchFallback bool
+
+	// MaxRateLimitWait is a maximal duration of waiting for rate-limit reset.
+	// If reset is later ErrRateLimited returned, so next resolver in chain may be used.
+	// Zero means waiting for reset without limit, negative value disables waiting.
+	MaxRateLimitWait time.Duration
+
+	// GraphQL enables fetching of repository files with GraphQL API.
+	// Files of many repositories are fetched with single query, so batch resolution consumes less rate-limit.
+	// GraphQL API requires authentication.
+	GraphQL bool
 }
 
+// ErrRateLimited returned if rate-limit reset is later than MaxRateLimitWait.
+// It wraps validation.ErrUnknownLicense so resolvers chain continues with next resolver.
+var ErrRateLimited = fmt.Errorf("%w: github rate limit reached", validation.ErrUnknownLicense)
+
+// errRefNotFound returned if repository doesn't contain module version reference or module directory
+var errRefNotFound = fmt.Errorf("module version not found")
+
 type Client struct {
 	ClientParams
 
 	log *zap.Logger
+
+	mu        sync.Mutex
+	rateReset time.Time
 }
 
 func NewClient(logger *zap.Logger, clientParams ClientParams) *Client {
+	if clientParams.Host == "" {
+		clientParams.Host = DefaultHost
+	}
+
 	return &Client{
 		ClientParams: clientParams,
-		log:          logger.With(zap.String("component", "github-client")),
+		log:          logger.With(zap.String("component", "github-client"), zap.String("host", clientParams.Host)),
 	}
 }
 
+// Name returns resolver name used as component in explanation steps (i.e. rate limit ones)
+func (*Client) Name() string { return "github" }
+
+// ResolveLicense detects license from files at module version tag (or pseudo-version commit).
+// Modules in repository sub directories and with major version suffix are supported the same way as go command does it:
+// license is looked up in module directory and then in parent directories up to repository root.
+// If reference not found and DefaultBranchFallback enabled license of repository default branch is returned.
+// It's a single module case of ResolveLicenses.
 func (c *Client) ResolveLicense(ctx context.Context, m validation.Module) (validation.License, error) {
-	l := c.log.With(zap.Stringer("module", &m))
-	matches := githubRe.FindStringSubmatch(m.Name)
-	if len(matches) == 0 {
-		l.Debug("not a github module")
-		return validation.License{}, validation.ErrUnknownLicense
+	result := c.ResolveLicenses(ctx, []validation.Module{m})[0]
+	return result.License, result.Err
+}
+
+func (c *Client) servesHost(host string) bool {
+	matched, _ := path.Match(strings.ToLower(c.Host), strings.ToLower(host))
+	return matched
+}
+
+// resolveDefaultBranch uses github license detection on repository default branch
+func (c *Client) resolveDefaultBranch(ctx context.Context, l *zap.Logger, m validation.Module, owner, repo string) (validation.License, error) {
+	var rl *github.RepositoryLicense
+	err := c.withRateLimit(ctx, l, func() (err error) {
+		rl, _, err = c.Client.Repositories.License(ctx, owner, repo)
+		return err
+	})
+	if err != nil {
+		return validation.License{}, fmt.Errorf("github failed: %w", err)
+	}
+
+	// If the license type is "other" then we try to use go-license-detector
+	// to determine the license, which seems to be accurate in these cases.
+	if rl.GetLicense().GetKey() == "other" {
+		l.Info("github didn't detected license, trying go-license-detector")
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageDetect,
+			Component: c.Name(),
+			Message:   fmt.Sprintf("%s: github reported license \"other\", running license detector", m.Name),
+		})
+		return c.detectFallback(ctx, m, rl)
 	}
 
-Retry:
-	rl, _, err := c.Client.Repositories.License(ctx, matches[1], matches[2])
-	var rateLimitErr *github.RateLimitError
+	return validation.License{
+		Name:   rl.GetLicense().GetName(),
+		SPDXID: rl.GetLicense().GetSPDXID(),
+	}, nil
+}
+
+// resolveAtRef takes license of module in repository root from github license API at module version reference.
+// False returned if github can't tell license, so repository files should be checked.
+func (c *Client) resolveAtRef(ctx context.Context, t target) (validation.License, bool, error) {
+	m := t.module
+	l := c.log.With(zap.Stringer("module", &m))
+
+	u := fmt.Sprintf("repos/%v/%v/license?ref=%v", t.owner, t.repo, url.QueryEscape(t.ref.String()))
+
+	var rl github.RepositoryLicense
+	err := c.withRateLimit(ctx, l, func() error {
+		req, err := c.Client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return fmt.Errorf("request construct failed: %w", err)
+		}
+
+		rl = github.RepositoryLicense{}
+		_, err = c.Client.Do(ctx, req, &rl)
+		return err
+	})
 	switch {
-	case errors.Is(err, nil):
-		// pass
-	case errors.As(err, &rateLimitErr):
-		dur := time.Until(rateLimitErr.Rate.Reset.Time)
+	case isNotFound(err):
+		l.Debug("github license not found at ref, checking repository files", zap.Stringer("ref", t.ref))
+		return validation.License{}, false, nil
+	case err != nil:
+		return validation.License{}, false, fmt.Errorf("github failed: %w", err)
+	}
+
+	if rl.GetLicense().GetKey() == "other" {
+		// the same as for default branch: go-license-detector is accurate in these cases
+		matches, err := licensedb.Detect(&filerImpl{License: &rl})
+		if err != nil || len(matches) == 0 {
+			l.Debug("license detector failed on github license file, checking repository files", zap.Error(err))
+			return validation.License{}, false, nil
+		}
+
+		lic, err := c.licenseToReturn(ctx, m, matches)
+		if err != nil {
+			return validation.License{}, false, nil
+		}
+
+		return lic, true, nil
+	}
+
+	if !usableLicense(rl.GetLicense()) {
+		return validation.License{}, false, nil
+	}
+
+	validation.RecordStep(ctx, validation.Step{
+		Stage:     validation.StageDetect,
+		Component: c.Name(),
+		Message:   fmt.Sprintf("%s: license of %s detected by github at ref %s", m.Name, rl.GetPath(), t.ref),
+		License:   rl.GetLicense().GetSPDXID(),
+	})
+
+	return validation.License{
+		Name:   rl.GetLicense().GetName(),
+		SPDXID: rl.GetLicense().GetSPDXID(),
+	}, true, nil
+}
+
+// usableLicense reports if license detected by github identifies license
+func usableLicense(license *github.License) bool {
+	switch license.GetSPDXID() {
+	case "", "NOASSERTION":
+		return false
+	default:
+		return license.GetKey() != "other"
+	}
+}
+
+// withRateLimit calls fn and repeats call after rate limit reset if it was reached.
+// ErrRateLimited returned if MaxRateLimitWait is set and reset is later.
+func (c *Client) withRateLimit(ctx context.Context, l *zap.Logger, fn func() error) error {
+	for {
+		err := fn()
+
+		var rateLimitErr *github.RateLimitError
+		if !errors.As(err, &rateLimitErr) {
+			return err
+		}
+
+		reset := rateLimitErr.Rate.Reset.Time
+		c.setRateReset(reset)
+
+		dur := time.Until(reset)
+		if c.MaxRateLimitWait != 0 && dur > c.MaxRateLimitWait {
+			l.Info("rate limit reached, reset is too far, skip", zap.Duration("reset_in", dur))
+			validation.RecordStep(ctx, validation.Step{
+				Stage:     validation.StageResolve,
+				Component: c.Name(),
+				Message:   fmt.Sprintf("rate limit reached, reset at %s, skipping", reset.Format(time.RFC3339)),
+			})
+			return fmt.Errorf("%w (reset at %s)", ErrRateLimited, reset.Format(time.RFC3339))
+		}
+
 		l.Info("rate limit reached, wait", zap.Duration("wait", dur))
 		timer := time.NewTimer(dur)
-		defer timer.Stop()
 
 		select {
 		case <-ctx.Done():
 			// Context cancelled or ended so return early
-			return validation.License{}, ctx.Err()
+			timer.Stop()
+			return ctx.Err()
 
 		case <-timer.C:
 			// Rate limit should be up, retry
-			goto Retry
 		}
+	}
+}
 
-	default:
-		return validation.License{}, fmt.Errorf("github failed: %w", err)
+func (c *Client) setRateReset(reset time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if reset.After(c.rateReset) {
+		c.rateReset = reset
 	}
+}
 
-	// If the license type is "other" then we try to use go-license-detector
-	// to determine the license, which seems to be accurate in these cases.
-	if rl.GetLicense().GetKey() == "other" {
-		l.Info("github didn't detected license, trying go-license-detector")
-		return c.detectFallback(m, rl)
+// CheckRateLimit returns error if rate limit was reached and not reset yet.
+// It's intended to be used as health observer: client is operational but skips modules.
+func (c *Client) CheckRateLimit(context.Context) error {
+	c.mu.Lock()
+	reset := c.rateReset
+	c.mu.Unlock()
+
+	if time.Now().Before(reset) {
+		return fmt.Errorf("rate limit reached, reset at %s", reset.Format(time.RFC3339))
 	}
 
-	return validation.License{
-		Name:   rl.GetLicense().GetName(),
-		SPDXID: rl.GetLicense().GetSPDXID(),
-	}, nil
+	return nil
+}
+
+// isNotFound reports if error is a github "not found" response
+func isNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
 }
 
 // detectFallback uses go-license-detector as a fallback.
-func (c *Client) detectFallback(m validation.Module, rl *github.RepositoryLicense) (validation.License, error) {
+func (c *Client) detectFallback(ctx context.Context, m validation.Module, rl *github.RepositoryLicense) (validation.License, error) {
 	ms, err := licensedb.Detect(&filerImpl{License: rl})
 	if err != nil {
 		return validation.License{}, fmt.Errorf("license detector failed: %w", err)
 	}
 
+	return c.licenseToReturn(ctx, m, ms)
+}
+
+func (c *Client) licenseToReturn(ctx context.Context, m validation.Module, ms map[string]api.Match) (validation.License, error) {
 	c.log.Debug(
 		"license detector success",
 		zap.Reflect("license_matches", ms),
 		zap.Stringer("module", &m),
 	)
 
-	var (
-		highestConfidence    float64
-		mostConfidentLicense string
-	)
-	for id, match := range ms {
-		confidence := float64(match.Confidence)
-		if confidence >= c.FallbackConfidenceThreshold && confidence > highestConfidence {
-			highestConfidence = confidence
-			mostConfidentLicense = id
-		}
-	}
-
-	if mostConfidentLicense == "" {
-		return validation.License{}, validation.ErrUnknownLicense
-	}
-
-	licInfo, _ := spdx.LicenseByID(mostConfidentLicense)
-
-	return validation.License{
-		Name:   licInfo.Name,
-		SPDXID: mostConfidentLicense,
-	}, nil
+	return detect.BestMatch(ctx, c.Name(), ms, c.FallbackConfidenceThreshold)
 }
 
 func (c *Client) Check(ctx context.Context) error {
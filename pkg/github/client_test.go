@@ -3,9 +3,12 @@ package github_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -88,7 +91,8 @@ func TestClient_ResolveLicense(t *testing.T) {
 		})
 
 		lic, err := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
-			Client: ghClient,
+			Client:                ghClient,
+			DefaultBranchFallback: true,
 		}).ResolveLicense(context.Background(), validation.Module{
 			Name:    "github.com/test/mit",
 			Version: semver.MustParse("v1.0.0"),
@@ -124,7 +128,8 @@ func TestClient_ResolveLicense(t *testing.T) {
 		}())
 
 		client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
-			Client: ghClient,
+			Client:                ghClient,
+			DefaultBranchFallback: true,
 		})
 		module := validation.Module{
 			Name:    "github.com/test/rate-limit-mit",
@@ -153,6 +158,7 @@ func TestClient_ResolveLicense(t *testing.T) {
 		lic, err := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
 			Client:                      ghClient,
 			FallbackConfidenceThreshold: 0.8,
+			DefaultBranchFallback:       true,
 		}).ResolveLicense(context.Background(), validation.Module{
 			Name:    "github.com/test/other-mit-file",
 			Version: semver.MustParse("v1.0.0"),
@@ -166,6 +172,206 @@ func TestClient_ResolveLicense(t *testing.T) {
 		}
 	})
 
+	t.Run("resolve license at version", func(t *testing.T) {
+		// v1.0.0 and commit abcdef123456 are MIT licensed, v2.0.0 has no license, default branch is not used
+		refs := map[string]bool{"v1.0.0": true, "abcdef123456": true, "v2.0.0": false}
+
+		mockedServerMux.HandleFunc("/repos/test/versioned/", func(w http.ResponseWriter, r *http.Request) {
+			licensed, ok := refs[r.URL.Query().Get("ref")]
+			switch {
+			case !ok:
+				serveJSON(w, http.StatusNotFound, map[string]string{"message": "No commit found for the ref"})
+			case r.URL.Path == "/repos/test/versioned/contents/" && licensed:
+				serveJSON(w, http.StatusOK, []gh.RepositoryContent{
+					{Name: gh.String("LICENSE"), Type: gh.String("file")},
+					{Name: gh.String("go.mod"), Type: gh.String("file")},
+				})
+			case r.URL.Path == "/repos/test/versioned/contents/":
+				serveJSON(w, http.StatusOK, []gh.RepositoryContent{{Name: gh.String("go.mod"), Type: gh.String("file")}})
+			case r.URL.Path == "/repos/test/versioned/contents/LICENSE" && licensed:
+				serveJSON(w, http.StatusOK, json.RawMessage(mitJSON))
+			default:
+				serveJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+			}
+		})
+
+		client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+			Client:                      ghClient,
+			FallbackConfidenceThreshold: 0.8,
+		})
+
+		type testCase struct {
+			version string
+			license validation.License
+			err     error
+		}
+
+		f := func(tc testCase) {
+			t.Run(tc.version, func(t *testing.T) {
+				lic, err := client.ResolveLicense(context.Background(), validation.Module{
+					Name:    "github.com/test/versioned",
+					Version: semver.MustParse(tc.version),
+				})
+				if tc.err != nil {
+					assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+					return
+				}
+
+				if assert.NoError(t, err) {
+					assert.Equal(t, tc.license, lic)
+				}
+			})
+		}
+
+		f(testCase{version: "v1.0.0", license: validation.License{Name: "MIT License", SPDXID: "MIT"}})
+		f(testCase{version: "v0.0.0-20200601120000-abcdef123456", license: validation.License{Name: "MIT License", SPDXID: "MIT"}})
+		f(testCase{version: "v2.0.0", err: validation.ErrUnknownLicense})
+		f(testCase{version: "v3.0.0", err: validation.ErrUnknownLicense})
+	})
+
+	t.Run("resolve license with license api at version", func(t *testing.T) {
+		// github detects license at v1.0.0 only, v2.0.0 license file is not recognized by github
+		var contentsCalls int32
+
+		mockedServerMux.HandleFunc("/repos/test/license-api/", func(w http.ResponseWriter, r *http.Request) {
+			ref := r.URL.Query().Get("ref")
+			switch {
+			case r.URL.Path == "/repos/test/license-api/license" && ref == "v1.0.0":
+				serveJSON(w, http.StatusOK, json.RawMessage(mitJSON))
+			case r.URL.Path == "/repos/test/license-api/license":
+				serveJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+			case r.URL.Path == "/repos/test/license-api/contents/" && ref == "v2.0.0":
+				atomic.AddInt32(&contentsCalls, 1)
+				serveJSON(w, http.StatusOK, []gh.RepositoryContent{{Name: gh.String("COPYING"), Type: gh.String("file")}})
+			case r.URL.Path == "/repos/test/license-api/contents/COPYING" && ref == "v2.0.0":
+				atomic.AddInt32(&contentsCalls, 1)
+				serveJSON(w, http.StatusOK, json.RawMessage(mitJSON))
+			default:
+				serveJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+			}
+		})
+
+		client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+			Client:                      ghClient,
+			FallbackConfidenceThreshold: 0.8,
+		})
+
+		lic, err := client.ResolveLicense(context.Background(), validation.Module{
+			Name:    "github.com/test/license-api",
+			Version: semver.MustParse("v1.0.0"),
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+		}
+
+		assert.EqualValues(t, 0, atomic.LoadInt32(&contentsCalls), "repository files should not be checked")
+
+		lic, err = client.ResolveLicense(context.Background(), validation.Module{
+			Name:    "github.com/test/license-api",
+			Version: semver.MustParse("v2.0.0"),
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+		}
+
+		assert.EqualValues(t, 2, atomic.LoadInt32(&contentsCalls))
+	})
+
+	t.Run("resolve license of nested module", func(t *testing.T) {
+		// ref -> directory -> files; only root and "sub/v3" directories contain license
+		trees := map[string]map[string][]string{
+			"sub/v1.1.0": {"": {"LICENSE", "sub"}, "sub": {"go.mod"}},
+			"v2.0.0":     {"": {"LICENSE", "go.mod"}},
+			"sub/v3.0.0": {"": {"sub"}, "sub": {"v3"}, "sub/v3": {"LICENSE", "go.mod"}},
+		}
+
+		mockedServerMux.HandleFunc("/repos/test/multi/", func(w http.ResponseWriter, r *http.Request) {
+			tree := trees[r.URL.Query().Get("ref")]
+			p := strings.Trim(strings.TrimPrefix(r.URL.Path, "/repos/test/multi/contents"), "/")
+
+			if files, ok := tree[p]; ok {
+				var entries []gh.RepositoryContent
+				for _, name := range files {
+					typ := "file"
+					if _, isDir := tree[strings.TrimPrefix(p+"/"+name, "/")]; isDir {
+						typ = "dir"
+					}
+
+					entries = append(entries, gh.RepositoryContent{Name: gh.String(name), Type: gh.String(typ)})
+				}
+
+				serveJSON(w, http.StatusOK, entries)
+				return
+			}
+
+			if path.Base(p) == "LICENSE" {
+				for _, name := range tree[strings.Trim(path.Dir(p), ".")] {
+					if name == "LICENSE" {
+						serveJSON(w, http.StatusOK, json.RawMessage(mitJSON))
+						return
+					}
+				}
+			}
+
+			serveJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		})
+
+		client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+			Client:                      ghClient,
+			FallbackConfidenceThreshold: 0.8,
+		})
+
+		type testCase struct {
+			module  string
+			version string
+			err     error
+		}
+
+		f := func(tc testCase) {
+			t.Run(tc.module+"@"+tc.version, func(t *testing.T) {
+				lic, err := client.ResolveLicense(context.Background(), validation.Module{
+					Name:    tc.module,
+					Version: semver.MustParse(tc.version),
+				})
+				if tc.err != nil {
+					assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+					return
+				}
+
+				if assert.NoError(t, err) {
+					assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+				}
+			})
+		}
+
+		f(testCase{module: "github.com/test/multi/sub", version: "v1.1.0"})
+		f(testCase{module: "github.com/test/multi/v2", version: "v2.0.0"})
+		f(testCase{module: "github.com/test/multi/sub/v3", version: "v3.0.0"})
+		f(testCase{module: "github.com/test/multi/other", version: "v1.1.0", err: validation.ErrUnknownLicense})
+	})
+
+	t.Run("enterprise host", func(t *testing.T) {
+		client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+			Client:                      ghClient,
+			Host:                        "*.corp.example",
+			FallbackConfidenceThreshold: 0.8,
+		})
+
+		lic, err := client.ResolveLicense(context.Background(), validation.Module{
+			Name:    "ghe.corp.example/test/versioned",
+			Version: semver.MustParse("v1.0.0"),
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+		}
+
+		_, err = client.ResolveLicense(context.Background(), validation.Module{
+			Name:    "github.com/test/versioned",
+			Version: semver.MustParse("v1.0.0"),
+		})
+		assert.True(t, errors.Is(err, validation.ErrUnknownLicense), "unexpected error: %v", err)
+	})
+
 	t.Run("health check", func(t *testing.T) {
 		mockedServerMux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
 			serveJSON(w, http.StatusOK, gh.RateLimits{
@@ -189,3 +395,138 @@ func serveJSON(w http.ResponseWriter, code int, object interface{}) {
 
 	_ = json.NewEncoder(w).Encode(object)
 }
+
+func TestClient_ResolveLicense_tokenPool(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+
+		// first token is exhausted
+		if r.Header.Get("Authorization") == "Bearer exhausted" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			serveJSON(w, http.StatusForbidden, map[string]string{
+				"message":           "API rate limit exceeded for user ID 1.",
+				"documentation_url": "https://developer.github.com/v3/#rate-limiting",
+			})
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		switch r.URL.Path {
+		case "/repos/test/pool/contents/":
+			serveJSON(w, http.StatusOK, []gh.RepositoryContent{{Name: gh.String("LICENSE"), Type: gh.String("file")}})
+		case "/repos/test/pool/contents/LICENSE":
+			serveJSON(w, http.StatusOK, json.RawMessage(mitJSON))
+		default:
+			serveJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	pool, err := github.NewTokenPool(github.StaticPoolTokens("exhausted", "fresh"), nil)
+	require.NoError(t, err)
+
+	ghClient, err := gh.NewEnterpriseClient(srv.URL, srv.URL, &http.Client{Transport: pool.Transport(srv.Client().Transport)})
+	require.NoError(t, err)
+
+	start := time.Now()
+	lic, err := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+		Client:                      ghClient,
+		FallbackConfidenceThreshold: 0.8,
+	}).ResolveLicense(context.Background(), validation.Module{
+		Name:    "github.com/test/pool",
+		Version: semver.MustParse("v1.0.0"),
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+	}
+
+	assert.True(t, time.Since(start) < time.Second, "rate limit reset should not be awaited")
+}
+
+func TestClient_ResolveLicense_rateLimitSkip(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		serveJSON(w, http.StatusForbidden, map[string]string{"message": "API rate limit exceeded for xxx.xxx.xxx.xxx."})
+	}))
+	t.Cleanup(srv.Close)
+
+	ghClient, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	f := func(name string, maxWait time.Duration) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+				Client:           ghClient,
+				MaxRateLimitWait: maxWait,
+			})
+
+			assert.NoError(t, client.CheckRateLimit(context.Background()))
+
+			start := time.Now()
+			_, err := client.ResolveLicense(context.Background(), validation.Module{
+				Name:    "github.com/test/limited",
+				Version: semver.MustParse("v1.0.0"),
+			})
+
+			assert.True(t, errors.Is(err, github.ErrRateLimited), "unexpected error: %v", err)
+			assert.True(t, errors.Is(err, validation.ErrUnknownLicense), "resolvers chain must continue")
+			assert.True(t, time.Since(start) < time.Second, "rate limit reset should not be awaited")
+			assert.Error(t, client.CheckRateLimit(context.Background()))
+		})
+	}
+
+	f("reset later than limit", time.Second)
+	f("waiting disabled", -1)
+}
+
+func TestClient_ResolveLicense_rateLimitWait(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("X-RateLimit-Limit", "60")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10))
+			serveJSON(w, http.StatusForbidden, map[string]string{"message": "API rate limit exceeded for xxx.xxx.xxx.xxx."})
+			return
+		}
+
+		if r.URL.Path == "/repos/test/waited/license" {
+			serveJSON(w, http.StatusOK, json.RawMessage(mitJSON))
+			return
+		}
+
+		serveJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}))
+	t.Cleanup(srv.Close)
+
+	ghClient, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+		Client:           ghClient,
+		MaxRateLimitWait: 5 * time.Second,
+	})
+
+	lic, err := client.ResolveLicense(context.Background(), validation.Module{
+		Name:    "github.com/test/waited",
+		Version: semver.MustParse("v1.0.0"),
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+	}
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls), "rate limited call should be retried after reset")
+	assert.NoError(t, client.CheckRateLimit(context.Background()), "rate limit is already reset")
+}
@@ -1,8 +1,12 @@
 package github
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"os"
+	"path"
+	"strings"
 
 	"github.com/google/go-github/v18/github"
 	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
@@ -34,3 +38,153 @@ func (f *filerImpl) ReadDir(dir string) ([]filer.File, error) {
 func (f *filerImpl) Close() {}
 
 func (f *filerImpl) PathsAreAlwaysSlash() bool { return true }
+
+// errNotFetched returned by storeFiler for objects which are not fetched yet
+var errNotFetched = fmt.Errorf("object not fetched yet")
+
+// repoKey identifies repository
+type repoKey struct {
+	owner, repo string
+}
+
+// objectKey identifies file or directory of repository at reference
+type objectKey struct {
+	owner, repo string
+
+	// ref is a tag, branch or commit
+	ref string
+
+	// path is a slash-separated path inside repository, empty for root
+	path string
+}
+
+func (k objectKey) String() string {
+	return k.owner + "/" + k.repo + "@" + k.ref + ":" + k.path
+}
+
+// object is a fetched file or directory of repository
+type object struct {
+	// err is a fetch error
+	err error
+
+	exists bool
+	isDir  bool
+
+	entries []filer.File
+	content []byte
+}
+
+// storeFiler implements filer.Filer over fetched repository objects.
+// Objects which are not fetched yet are collected to misses and reported with errNotFetched.
+type storeFiler struct {
+	store  map[objectKey]*object
+	misses map[objectKey]bool
+
+	// root is a key of directory which is used as filer root
+	root objectKey
+}
+
+func (f *storeFiler) key(p string) objectKey {
+	key := f.root
+	key.path = strings.Trim(path.Join(f.root.path, p), "/")
+	return key
+}
+
+// lookup returns fetched object, not fetched object is recorded as miss
+func (f *storeFiler) lookup(p string) (*object, bool) {
+	key := f.key(p)
+	obj, ok := f.store[key]
+	if !ok {
+		f.misses[key] = true
+	}
+
+	return obj, ok
+}
+
+// sub returns filer over sub directory
+func (f *storeFiler) sub(dir string) *storeFiler {
+	return &storeFiler{store: f.store, misses: f.misses, root: f.key(dir)}
+}
+
+func (f *storeFiler) ReadFile(name string) ([]byte, error) {
+	obj, ok := f.lookup(name)
+	switch {
+	case !ok:
+		return nil, &os.PathError{Op: "open", Path: name, Err: errNotFetched}
+	case obj.err != nil:
+		return nil, &os.PathError{Op: "open", Path: name, Err: obj.err}
+	case !obj.exists || obj.isDir:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	default:
+		return obj.content, nil
+	}
+}
+
+// ReadDir lists directory. Returned error wraps errRefNotFound if reference or directory doesn't exist.
+func (f *storeFiler) ReadDir(dir string) ([]filer.File, error) {
+	obj, ok := f.lookup(dir)
+	switch {
+	case !ok:
+		return nil, &os.PathError{Op: "readdir", Path: dir, Err: errNotFetched}
+	case obj.err != nil:
+		return nil, &os.PathError{Op: "readdir", Path: dir, Err: obj.err}
+	case !obj.exists || !obj.isDir:
+		return nil, &os.PathError{Op: "readdir", Path: dir, Err: errRefNotFound}
+	default:
+		return obj.entries, nil
+	}
+}
+
+func (f *storeFiler) Close() {}
+
+func (f *storeFiler) PathsAreAlwaysSlash() bool { return true }
+
+// fetchContents fetches repository objects one by one using contents API
+func (c *Client) fetchContents(ctx context.Context, keys []objectKey) map[objectKey]*object {
+	ret := make(map[objectKey]*object, len(keys))
+	for _, key := range keys {
+		ret[key] = c.fetchContent(ctx, key)
+	}
+
+	return ret
+}
+
+func (c *Client) fetchContent(ctx context.Context, key objectKey) *object {
+	var (
+		file    *github.RepositoryContent
+		entries []*github.RepositoryContent
+	)
+	err := c.withRateLimit(ctx, c.log, func() (err error) {
+		file, entries, _, err = c.Client.Repositories.GetContents(ctx, key.owner, key.repo, key.path,
+			&github.RepositoryContentGetOptions{Ref: key.ref})
+		return err
+	})
+	switch {
+	case isNotFound(err):
+		return &object{}
+	case err != nil:
+		return &object{err: fmt.Errorf("github contents failed: %w", err)}
+	case file != nil:
+		content, err := file.GetContent()
+		if err != nil {
+			return &object{err: fmt.Errorf("file %s decode failed: %w", key.path, err)}
+		}
+
+		return &object{exists: true, content: []byte(content)}
+	}
+
+	obj := &object{exists: true, isDir: true}
+	for _, entry := range entries {
+		switch entry.GetType() {
+		case "file", "dir":
+			obj.entries = append(obj.entries, filer.File{
+				Name:  entry.GetName(),
+				IsDir: entry.GetType() == "dir",
+			})
+		default:
+			// symlinks and submodules are not interesting
+		}
+	}
+
+	return obj
+}
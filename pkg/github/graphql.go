@@ -0,0 +1,285 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v18/github"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// graphQLBatchSize limits number of objects requested with single GraphQL query
+const graphQLBatchSize = 100
+
+// graphQLObjectFragment selects directory entries or file text of git object
+const graphQLObjectFragment = `
+fragment object on GitObject {
+  __typename
+  ... on Tree { entries { name type } }
+  ... on Blob { text isBinary }
+}
+`
+
+type graphQLObject struct {
+	Typename string `json:"__typename"`
+	Entries  []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"entries"`
+	Text     *string `json:"text"`
+	IsBinary bool    `json:"isBinary"`
+}
+
+type graphQLLicense struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	SPDXID string `json:"spdxId"`
+}
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+
+	// Path contains aliases of failed repository and object
+	Path []interface{} `json:"path"`
+}
+
+// aliases returns repository and object (empty for whole repository) aliases of failed field
+func (e *graphQLError) aliases() (repo, obj string) {
+	if len(e.Path) > 0 {
+		repo, _ = e.Path[0].(string)
+	}
+
+	if len(e.Path) > 1 {
+		obj, _ = e.Path[1].(string)
+	}
+
+	return repo, obj
+}
+
+type graphQLResponse struct {
+	// Data maps repository alias to objects by aliases and "licenseInfo", not found repository is null
+	Data   map[string]map[string]json.RawMessage `json:"data"`
+	Errors []graphQLError                        `json:"errors"`
+}
+
+// graphQLURL returns GraphQL API url: https://api.github.com/graphql for github.com
+// and https://ghe.corp.example/api/graphql for GitHub Enterprise Server (REST API at /api/v3/).
+func (c *Client) graphQLURL() string {
+	u := *c.Client.BaseURL
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/v3") + "/graphql"
+	return u.String()
+}
+
+// fetchGraphQL fetches repository objects of many repositories at once using GraphQL API.
+// Licenses detected by github on default branches (licenseInfo) of queried repositories are also returned.
+func (c *Client) fetchGraphQL(ctx context.Context, keys []objectKey) (map[objectKey]*object, map[repoKey]*github.License) {
+	ret := make(map[objectKey]*object, len(keys))
+	licenses := make(map[repoKey]*github.License)
+	for start := 0; start < len(keys); start += graphQLBatchSize {
+		end := start + graphQLBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		chunk := keys[start:end]
+		objects, chunkLicenses, err := c.queryObjects(ctx, chunk)
+		for repo, license := range chunkLicenses {
+			licenses[repo] = license
+		}
+
+		for i, key := range chunk {
+			if err != nil {
+				ret[key] = &object{err: err}
+				continue
+			}
+
+			ret[key] = objects[i]
+		}
+	}
+
+	return ret, licenses
+}
+
+// queryObjects fetches objects and repository licenses with single GraphQL query.
+// Returned objects have the same order as keys.
+func (c *Client) queryObjects(ctx context.Context, keys []objectKey) ([]*object, map[repoKey]*github.License, error) {
+	type repoAliases struct {
+		key     repoKey
+		alias   string
+		objects map[int]string
+	}
+
+	var (
+		query   strings.Builder
+		repos   []*repoAliases
+		byRepo  = make(map[repoKey]*repoAliases)
+		aliases = make([]*repoAliases, len(keys))
+	)
+
+	for i, key := range keys {
+		rk := repoKey{owner: key.owner, repo: key.repo}
+		repo, ok := byRepo[rk]
+		if !ok {
+			repo = &repoAliases{key: rk, alias: "r" + strconv.Itoa(len(repos)), objects: make(map[int]string)}
+			byRepo[rk] = repo
+			repos = append(repos, repo)
+		}
+
+		repo.objects[i] = "o" + strconv.Itoa(i)
+		aliases[i] = repo
+	}
+
+	query.WriteString("query {\n")
+	for _, repo := range repos {
+		fmt.Fprintf(&query, "  %s: repository(owner: %s, name: %s) {\n", repo.alias, graphQLString(repo.key.owner), graphQLString(repo.key.repo))
+		query.WriteString("    licenseInfo { key name spdxId }\n")
+		for i, key := range keys {
+			if aliases[i] == repo {
+				fmt.Fprintf(&query, "    %s: object(expression: %s) { ...object }\n", repo.objects[i], graphQLString(key.ref+":"+key.path))
+			}
+		}
+		query.WriteString("  }\n")
+	}
+	query.WriteString("}\n")
+	query.WriteString(graphQLObjectFragment)
+
+	var resp graphQLResponse
+	err := c.withRateLimit(ctx, c.log, func() error {
+		req, err := c.Client.NewRequest(http.MethodPost, c.graphQLURL(), map[string]string{"query": query.String()})
+		if err != nil {
+			return fmt.Errorf("request construct failed: %w", err)
+		}
+
+		resp = graphQLResponse{}
+		ghResp, err := c.Client.Do(ctx, req, &resp)
+		if err != nil {
+			return err
+		}
+
+		// rate limit is reported with successful status, it's handled the same way as REST API one
+		for _, item := range resp.Errors {
+			if item.Type == "RATE_LIMITED" {
+				rate := ghResp.Rate
+				if rate.Reset.IsZero() {
+					rate.Reset = github.Timestamp{Time: time.Now().Add(time.Minute)}
+				}
+
+				return &github.RateLimitError{Rate: rate, Response: ghResp.Response, Message: item.Message}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("github graphql failed: %w", err)
+	}
+
+	// errors of repositories and objects are attached to them, other objects of query are still usable
+	failed := make(map[string]map[string]error)
+	for _, item := range resp.Errors {
+		repo, obj := item.aliases()
+		switch {
+		case item.Type == "NOT_FOUND":
+			// missing repository is reported as error, it's handled as not existing object
+			continue
+		case repo == "":
+			return nil, nil, fmt.Errorf("github graphql query failed: %s: %s", item.Type, item.Message)
+		}
+
+		if failed[repo] == nil {
+			failed[repo] = make(map[string]error)
+		}
+
+		failed[repo][obj] = fmt.Errorf("github graphql query failed: %s: %s", item.Type, item.Message)
+	}
+
+	licenses := make(map[repoKey]*github.License)
+	for _, repo := range repos {
+		raw, ok := resp.Data[repo.alias]["licenseInfo"]
+		if !ok {
+			continue
+		}
+
+		var license *graphQLLicense
+		if err := json.Unmarshal(raw, &license); err != nil {
+			return nil, nil, fmt.Errorf("github graphql license decode failed: %w", err)
+		}
+
+		if license != nil {
+			licenses[repo.key] = &github.License{
+				Key:    github.String(license.Key),
+				Name:   github.String(license.Name),
+				SPDXID: github.String(license.SPDXID),
+			}
+		}
+	}
+
+	ret := make([]*object, len(keys))
+	for i := range keys {
+		repo := aliases[i]
+
+		if err, ok := failed[repo.alias][repo.objects[i]]; ok {
+			ret[i] = &object{err: err}
+			continue
+		}
+
+		if err, ok := failed[repo.alias][""]; ok {
+			ret[i] = &object{err: err}
+			continue
+		}
+
+		var obj *graphQLObject
+		if raw, ok := resp.Data[repo.alias][repo.objects[i]]; ok {
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return nil, nil, fmt.Errorf("github graphql object decode failed: %w", err)
+			}
+		}
+
+		ret[i] = graphQLToObject(obj)
+	}
+
+	return ret, licenses, nil
+}
+
+func graphQLToObject(obj *graphQLObject) *object {
+	switch {
+	case obj == nil:
+		return &object{}
+	case obj.Typename == "Tree":
+		ret := &object{exists: true, isDir: true}
+		for _, entry := range obj.Entries {
+			switch entry.Type {
+			case "blob", "tree":
+				ret.entries = append(ret.entries, filer.File{
+					Name:  entry.Name,
+					IsDir: entry.Type == "tree",
+				})
+			default:
+				// submodules are not interesting
+			}
+		}
+
+		return ret
+	case obj.Typename == "Blob":
+		ret := &object{exists: true}
+		if obj.Text != nil && !obj.IsBinary {
+			ret.content = []byte(*obj.Text)
+		}
+
+		return ret
+	default:
+		return &object{}
+	}
+}
+
+// graphQLString quotes s as GraphQL string literal (JSON string syntax is compatible)
+func graphQLString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
@@ -0,0 +1,326 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/github"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	gh "github.com/google/go-github/v18/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	graphQLRepoRe   = regexp.MustCompile(`^\s*(r\d+): repository\(owner: ("[^"]*"), name: ("[^"]*")\) \{$`)
+	graphQLObjectRe = regexp.MustCompile(`^\s*(o\d+): object\(expression: ("[^"]*")\)`)
+)
+
+func tree(entries ...string) map[string]interface{} {
+	var ret []map[string]string
+	for _, entry := range entries {
+		typ := "blob"
+		if strings.HasSuffix(entry, "/") {
+			typ, entry = "tree", strings.TrimSuffix(entry, "/")
+		}
+
+		ret = append(ret, map[string]string{"name": entry, "type": typ})
+	}
+
+	return map[string]interface{}{"__typename": "Tree", "entries": ret}
+}
+
+func blob(text string) map[string]interface{} {
+	return map[string]interface{}{"__typename": "Blob", "text": text, "isBinary": false}
+}
+
+// graphQLFailure is served as error of given type for object, under "" key as error of whole repository
+type graphQLFailure string
+
+// graphQLServer emulates GitHub GraphQL API for repository objects queries built by client
+func graphQLServer(t *testing.T, repos map[string]map[string]interface{}, requests *int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		atomic.AddInt32(requests, 1)
+
+		var req struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var (
+			data      = make(map[string]interface{})
+			errs      []map[string]interface{}
+			objs      map[string]interface{}
+			result    map[string]interface{}
+			repoAlias string
+		)
+
+		for _, line := range strings.Split(req.Query, "\n") {
+			if m := graphQLRepoRe.FindStringSubmatch(line); m != nil {
+				owner, _ := strconv.Unquote(m[2])
+				name, _ := strconv.Unquote(m[3])
+
+				var ok bool
+				objs, ok = repos[owner+"/"+name]
+				if !ok {
+					data[m[1]], result = nil, nil
+					errs = append(errs, map[string]interface{}{"type": "NOT_FOUND", "message": "Could not resolve to a Repository"})
+					continue
+				}
+
+				if failure, ok := objs[""].(graphQLFailure); ok {
+					data[m[1]], result = nil, nil
+					errs = append(errs, map[string]interface{}{"type": failure, "message": "Repository access failed", "path": []string{m[1]}})
+					continue
+				}
+
+				result, repoAlias = make(map[string]interface{}), m[1]
+				data[m[1]] = result
+				continue
+			}
+
+			if strings.TrimSpace(line) == "licenseInfo { key name spdxId }" && result != nil {
+				result["licenseInfo"] = objs["licenseInfo"]
+				continue
+			}
+
+			if m := graphQLObjectRe.FindStringSubmatch(line); m != nil && result != nil {
+				expression, _ := strconv.Unquote(m[2])
+				if failure, ok := objs[expression].(graphQLFailure); ok {
+					result[m[1]] = nil
+					errs = append(errs, map[string]interface{}{"type": failure, "message": "Object access failed", "path": []string{repoAlias, m[1]}})
+					continue
+				}
+
+				result[m[1]] = objs[expression]
+			}
+		}
+
+		serveJSON(w, http.StatusOK, map[string]interface{}{"data": data, "errors": errs})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestClient_ResolveLicenses_graphQL(t *testing.T) {
+	t.Parallel()
+
+	var content gh.RepositoryContent
+	require.NoError(t, json.Unmarshal([]byte(mitJSON), &content))
+
+	license, err := content.GetContent()
+	require.NoError(t, err)
+
+	var requests int32
+	srv := graphQLServer(t, map[string]map[string]interface{}{
+		"test/graphql": {
+			"v1.0.0:":            tree("LICENSE", "go.mod", "sub/"),
+			"v1.0.0:LICENSE":     blob(license),
+			"sub/v1.2.0:":        tree("LICENSE", "sub/"),
+			"sub/v1.2.0:sub":     tree("go.mod"),
+			"sub/v1.2.0:LICENSE": blob(license),
+		},
+		"test/no-license": {
+			"v1.0.0:": tree("go.mod"),
+		},
+	}, &requests)
+
+	ghClient, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+		Client:                      ghClient,
+		FallbackConfidenceThreshold: 0.8,
+		GraphQL:                     true,
+	})
+
+	results := client.ResolveLicenses(context.Background(), []validation.Module{
+		{Name: "github.com/test/graphql", Version: semver.MustParse("v1.0.0")},
+		{Name: "github.com/test/graphql/sub", Version: semver.MustParse("v1.2.0")},
+		{Name: "github.com/test/graphql", Version: semver.MustParse("v1.1.0")},
+		{Name: "github.com/test/missing", Version: semver.MustParse("v1.0.0")},
+		{Name: "github.com/test/no-license", Version: semver.MustParse("v1.0.0")},
+		{Name: "gitlab.com/test/graphql", Version: semver.MustParse("v1.0.0")},
+	})
+	require.Len(t, results, 6)
+
+	mit := validation.License{Name: "MIT License", SPDXID: "MIT"}
+	if assert.NoError(t, results[0].Err) {
+		assert.Equal(t, mit, results[0].License)
+	}
+
+	if assert.NoError(t, results[1].Err) {
+		assert.Equal(t, mit, results[1].License)
+	}
+
+	for _, result := range results[2:] {
+		assert.True(t, errors.Is(result.Err, validation.ErrUnknownLicense), "unexpected error: %v", result.Err)
+	}
+
+	// module directories, license files and parent directories are fetched for all modules at once
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+}
+
+func TestClient_ResolveLicenses_graphQLDefaultBranch(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	srv := graphQLServer(t, map[string]map[string]interface{}{
+		"test/graphql": {
+			"licenseInfo": map[string]string{"key": "mit", "name": "MIT License", "spdxId": "MIT"},
+		},
+	}, &requests)
+
+	ghClient, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+		Client:                      ghClient,
+		FallbackConfidenceThreshold: 0.8,
+		DefaultBranchFallback:       true,
+		GraphQL:                     true,
+	})
+
+	var explanation validation.Explanation
+	results := client.ResolveLicenses(validation.WithExplanation(context.Background(), &explanation), []validation.Module{
+		{Name: "github.com/test/graphql", Version: semver.MustParse("v1.0.0")},
+	})
+	require.Len(t, results, 1)
+
+	// default branch license is taken from licenseInfo without additional requests
+	if assert.NoError(t, results[0].Err) {
+		assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, results[0].License)
+	}
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+	if assert.Len(t, explanation.Steps, 1) {
+		assert.Equal(t, "github.com/test/graphql: module version not found (ref v1.0.0), using default branch license", explanation.Steps[0].Message)
+	}
+}
+
+func TestClient_ResolveLicenses_graphQLPartialErrors(t *testing.T) {
+	t.Parallel()
+
+	var content gh.RepositoryContent
+	require.NoError(t, json.Unmarshal([]byte(mitJSON), &content))
+
+	license, err := content.GetContent()
+	require.NoError(t, err)
+
+	var requests int32
+	srv := graphQLServer(t, map[string]map[string]interface{}{
+		"test/graphql": {
+			"v1.0.0:":        tree("LICENSE", "go.mod"),
+			"v1.0.0:LICENSE": blob(license),
+			"v1.1.0:":        graphQLFailure("FORBIDDEN"),
+		},
+		"test/forbidden": {
+			"": graphQLFailure("FORBIDDEN"),
+		},
+	}, &requests)
+
+	ghClient, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+		Client:                      ghClient,
+		FallbackConfidenceThreshold: 0.8,
+		GraphQL:                     true,
+	})
+
+	results := client.ResolveLicenses(context.Background(), []validation.Module{
+		{Name: "github.com/test/graphql", Version: semver.MustParse("v1.0.0")},
+		{Name: "github.com/test/graphql", Version: semver.MustParse("v1.1.0")},
+		{Name: "github.com/test/forbidden", Version: semver.MustParse("v1.0.0")},
+	})
+	require.Len(t, results, 3)
+
+	// errors are reported with successful status and affect only failed objects
+	if assert.NoError(t, results[0].Err) {
+		assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, results[0].License)
+	}
+
+	for _, result := range results[1:] {
+		if assert.Error(t, result.Err) {
+			assert.False(t, errors.Is(result.Err, validation.ErrUnknownLicense), "unexpected error: %v", result.Err)
+			assert.Contains(t, result.Err.Error(), "FORBIDDEN")
+		}
+	}
+}
+
+func TestClient_ResolveLicenses_graphQLRateLimit(t *testing.T) {
+	t.Parallel()
+
+	f := func(name string, reset time.Duration, maxWait time.Duration, check func(t *testing.T, err error, calls int32)) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// rate limit is reported with successful status
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.Header().Set("X-RateLimit-Limit", "5000")
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(reset).Unix(), 10))
+					serveJSON(w, http.StatusOK, map[string]interface{}{
+						"data":   nil,
+						"errors": []map[string]string{{"type": "RATE_LIMITED", "message": "API rate limit exceeded"}},
+					})
+					return
+				}
+
+				serveJSON(w, http.StatusOK, map[string]interface{}{
+					"data":   map[string]interface{}{"r0": nil},
+					"errors": []map[string]string{{"type": "NOT_FOUND", "message": "Could not resolve to a Repository"}},
+				})
+			}))
+			t.Cleanup(srv.Close)
+
+			ghClient, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+			require.NoError(t, err)
+
+			client := github.NewClient(zaptest.NewLogger(t), github.ClientParams{
+				Client:           ghClient,
+				MaxRateLimitWait: maxWait,
+				GraphQL:          true,
+			})
+
+			results := client.ResolveLicenses(context.Background(), []validation.Module{
+				{Name: "github.com/test/limited", Version: semver.MustParse("v1.0.0")},
+			})
+			require.Len(t, results, 1)
+
+			check(t, results[0].Err, atomic.LoadInt32(&calls))
+		})
+	}
+
+	f("skip", time.Hour, time.Second, func(t *testing.T, err error, calls int32) {
+		assert.True(t, errors.Is(err, github.ErrRateLimited), "unexpected error: %v", err)
+		assert.EqualValues(t, 1, calls)
+	})
+
+	f("wait", time.Second, 5*time.Second, func(t *testing.T, err error, calls int32) {
+		assert.False(t, errors.Is(err, github.ErrRateLimited), "unexpected error: %v", err)
+		assert.True(t, errors.Is(err, validation.ErrUnknownLicense), "unexpected error: %v", err)
+		assert.EqualValues(t, 2, calls, "rate limited query should be retried after reset")
+	})
+}
@@ -0,0 +1,425 @@
+package github
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/metric"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// DefaultBaseURL is a github.com API url
+	DefaultBaseURL = "https://api.github.com/"
+
+	// appJWTLifetime is a lifetime of GitHub App JWT, GitHub accepts at most 10 minutes
+	appJWTLifetime = 9 * time.Minute
+
+	// appJWTClockSkew protects from clock drift between app and GitHub
+	appJWTClockSkew = time.Minute
+)
+
+// ParsePrivateKey parses PEM-encoded RSA private key (PKCS#1 or PKCS#8) like GitHub App private key
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("private key parse failed: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+
+	return key, nil
+}
+
+type AppTokenSourceParams struct {
+	HTTPClient *http.Client
+
+	// BaseURL is an API url. Default is DefaultBaseURL.
+	BaseURL string
+
+	AppID          int64
+	InstallationID int64
+
+	// PrivateKey is an App private key used to sign JWT (see ParsePrivateKey)
+	PrivateKey *rsa.PrivateKey
+}
+
+// appTokenSource issues GitHub App installation access tokens
+type appTokenSource struct {
+	AppTokenSourceParams
+
+	client *http.Client
+}
+
+// NewAppTokenSource returns token source issuing GitHub App installation access tokens.
+// Token is reused until it expires, new token is requested automatically.
+func NewAppTokenSource(params AppTokenSourceParams) oauth2.TokenSource {
+	hc := http.DefaultClient
+	if params.HTTPClient != nil {
+		hc = params.HTTPClient
+	}
+
+	if params.BaseURL == "" {
+		params.BaseURL = DefaultBaseURL
+	}
+
+	return oauth2.ReuseTokenSource(nil, &appTokenSource{AppTokenSourceParams: params, client: hc})
+}
+
+// jwt returns RS256-signed JWT authenticating App
+func (s *appTokenSource) jwt(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]int64{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.AppID,
+	})
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("jwt sign failed: %w", err)
+	}
+
+	return signingInput + "." + enc.EncodeToString(signature), nil
+}
+
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	u := strings.TrimSuffix(s.BaseURL, "/") + "/app/installations/" + strconv.FormatInt(s.InstallationID, 10) + "/access_tokens"
+
+	req, err := http.NewRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request construct failed: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.machine-man-preview+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("installation token request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("installation token request returned non-created status: %d: %s",
+			resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("installation token decode failed: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.Token,
+		TokenType:   "token",
+		Expiry:      token.ExpiresAt,
+	}, nil
+}
+
+// PoolToken is a token source with name used in metrics
+type PoolToken struct {
+	Name   string
+	Source oauth2.TokenSource
+}
+
+type poolToken struct {
+	PoolToken
+
+	// quota is unknown until first response
+	known     bool
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// quotaKnown reports if token quota was received and not reset yet
+func (t *poolToken) quotaKnown(now time.Time) bool {
+	return t.known && !now.After(t.reset)
+}
+
+// effectiveRemaining returns expected remaining requests count, unknown quota is treated as unlimited
+func (t *poolToken) effectiveRemaining(now time.Time) int {
+	if !t.quotaKnown(now) {
+		return math.MaxInt32
+	}
+
+	return t.remaining
+}
+
+// TokenPool authorizes requests with token having most remaining rate-limit quota
+type TokenPool struct {
+	mu     sync.Mutex
+	tokens []*poolToken
+}
+
+// NewTokenPool creates token pool. If meter provided remaining quota of each token is exported as metric.
+func NewTokenPool(tokens []PoolToken, meter metric.Meter) (*TokenPool, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens provided")
+	}
+
+	pool := &TokenPool{}
+	for _, token := range tokens {
+		pool.tokens = append(pool.tokens, &poolToken{PoolToken: token})
+	}
+
+	if meter == nil {
+		meter = metric.NoopMeter{}
+	}
+
+	_, err := meter.RegisterInt64Observer("github_token_remaining_quota", func(result metric.Int64ObserverResult) {
+		for _, q := range pool.Quotas() {
+			if q.Known {
+				result.Observe(int64(q.Remaining), key.String("token", q.Name))
+			}
+		}
+	}, metric.WithDescription("Remaining GitHub API requests quota per token"))
+	if err != nil {
+		return nil, fmt.Errorf("quota metric register failed: %w", err)
+	}
+
+	return pool, nil
+}
+
+// Quota is a rate-limit quota state of token
+type Quota struct {
+	Name string
+
+	// Known is false until first response received with token and after quota reset
+	Known     bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Quotas returns current quota states of tokens
+func (p *TokenPool) Quotas() []Quota {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	ret := make([]Quota, 0, len(p.tokens))
+	for _, t := range p.tokens {
+		q := Quota{Name: t.Name, Known: t.quotaKnown(now)}
+		if q.Known {
+			q.Limit, q.Remaining, q.Reset = t.limit, t.remaining, t.reset
+		}
+
+		ret = append(ret, q)
+	}
+
+	return ret
+}
+
+// Available reports if there is a token with remaining or unknown quota
+func (p *TokenPool) Available() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range p.tokens {
+		if t.effectiveRemaining(now) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pick chooses token with most remaining quota and reserves one request of it
+func (p *TokenPool) pick() *poolToken {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	best := p.tokens[0]
+	for _, t := range p.tokens[1:] {
+		if t.effectiveRemaining(now) > best.effectiveRemaining(now) {
+			best = t
+		}
+	}
+
+	if best.quotaKnown(now) && best.remaining > 0 {
+		best.remaining--
+	}
+
+	return best
+}
+
+// update updates token quota from response rate-limit headers
+func (p *TokenPool) update(t *poolToken, resp *http.Response) {
+	limit, err1 := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	remaining, err2 := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	reset, err3 := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t.known, t.limit, t.remaining, t.reset = true, limit, remaining, time.Unix(reset, 0)
+}
+
+// Transport returns http.RoundTripper authorizing requests with pool tokens. Default base is http.DefaultTransport.
+func (p *TokenPool) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &poolTransport{pool: p, base: base}
+}
+
+type poolTransport struct {
+	pool *TokenPool
+	base http.RoundTripper
+}
+
+// RoundTrip authorizes request with token having most remaining quota.
+// Rate-limited request is repeated with another token if pool has one.
+// Response rate-limit headers are replaced with pool best quota, so github client doesn't refuse requests
+// while pool has tokens with remaining quota.
+func (t *poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		pt := t.pool.pick()
+
+		token, err := pt.Source.Token()
+		if err != nil {
+			return nil, fmt.Errorf("token %s obtain failed: %w", pt.Name, err)
+		}
+
+		authorized := req.Clone(req.Context())
+		if attempt > 0 && req.Body != nil {
+			if authorized.Body, err = req.GetBody(); err != nil {
+				return nil, fmt.Errorf("request body reset failed: %w", err)
+			}
+		}
+
+		token.SetAuthHeader(authorized)
+
+		resp, err := t.base.RoundTrip(authorized)
+		if err != nil {
+			return nil, err
+		}
+
+		t.pool.update(pt, resp)
+
+		retryable := req.Body == nil || req.GetBody != nil
+		if rateLimited(resp) && retryable && t.pool.Available() {
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<10))
+			resp.Body.Close()
+			continue
+		}
+
+		if !rateLimited(resp) {
+			t.pool.annotate(resp)
+		}
+
+		return resp, nil
+	}
+}
+
+// rateLimited reports if response is a rate-limit error
+func rateLimited(resp *http.Response) bool {
+	return (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) &&
+		resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// annotate replaces response remaining quota with best quota of pool.
+// Token with unknown or reset quota is expected to have full limit.
+func (p *TokenPool) annotate(resp *http.Response) {
+	limit, err1 := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	remaining, err2 := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err1 != nil || err2 != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	best := remaining
+	for _, t := range p.tokens {
+		if t.quotaKnown(now) {
+			best = maxInt(best, t.remaining)
+		} else {
+			best = maxInt(best, limit)
+		}
+	}
+
+	resp.Header.Set("X-RateLimit-Remaining", strconv.Itoa(best))
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// StaticPoolTokens converts access tokens to pool tokens. Tokens are named by index to not expose them in metrics.
+func StaticPoolTokens(tokens ...string) []PoolToken {
+	ret := make([]PoolToken, 0, len(tokens))
+	for i, token := range tokens {
+		ret = append(ret, PoolToken{
+			Name:   "token-" + strconv.Itoa(i),
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+		})
+	}
+
+	return ret
+}
+
+// AppPoolToken converts App token source to pool token
+func AppPoolToken(appID, installationID int64, source oauth2.TokenSource) PoolToken {
+	return PoolToken{
+		Name:   fmt.Sprintf("app-%d-%d", appID, installationID),
+		Source: source,
+	}
+}
@@ -0,0 +1,175 @@
+package github_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/github"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenPool(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		remaining = map[string]int{"Bearer a": 10, "Bearer b": 100}
+		used      []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		auth := r.Header.Get("Authorization")
+		used = append(used, auth)
+		remaining[auth]--
+
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining[auth]))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	}))
+	t.Cleanup(srv.Close)
+
+	pool, err := github.NewTokenPool(github.StaticPoolTokens("a", "b"), nil)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: pool.Transport(srv.Client().Transport)}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// first token is used until its quota is known, then token with most remaining quota is used
+	assert.Equal(t, []string{"Bearer a", "Bearer b", "Bearer b"}, used)
+
+	quotas := pool.Quotas()
+	require.Len(t, quotas, 2)
+	assert.Equal(t, "token-0", quotas[0].Name)
+	assert.True(t, quotas[0].Known)
+	assert.Equal(t, 9, quotas[0].Remaining)
+	assert.Equal(t, 5000, quotas[0].Limit)
+	assert.Equal(t, "token-1", quotas[1].Name)
+	assert.Equal(t, 98, quotas[1].Remaining)
+}
+
+func TestAppTokenSource(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	parsedKey, err := github.ParsePrivateKey(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+	require.NoError(t, err)
+
+	var calls int32
+
+	newServer := func(lifetime time.Duration) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			// verify JWT
+			parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+			if !assert.Len(t, parts, 3) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+			require.NoError(t, err)
+
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if !assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature)) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+			require.NoError(t, err)
+
+			var claims struct {
+				Iss int64 `json:"iss"`
+				Iat int64 `json:"iat"`
+				Exp int64 `json:"exp"`
+			}
+			require.NoError(t, json.Unmarshal(claimsJSON, &claims))
+			assert.EqualValues(t, 7, claims.Iss)
+			assert.True(t, claims.Exp-claims.Iat <= int64((10*time.Minute).Seconds()), "jwt lifetime is too long")
+
+			n := atomic.AddInt32(&calls, 1)
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"token":      "installation-token-" + strconv.Itoa(int(n)),
+				"expires_at": time.Now().Add(lifetime),
+			})
+		}))
+		t.Cleanup(srv.Close)
+
+		return srv
+	}
+
+	t.Run("reuse", func(t *testing.T) {
+		srv := newServer(time.Hour)
+
+		ts := github.NewAppTokenSource(github.AppTokenSourceParams{
+			HTTPClient:     srv.Client(),
+			BaseURL:        srv.URL,
+			AppID:          7,
+			InstallationID: 42,
+			PrivateKey:     parsedKey,
+		})
+
+		first, err := ts.Token()
+		require.NoError(t, err)
+
+		second, err := ts.Token()
+		require.NoError(t, err)
+
+		assert.Equal(t, first.AccessToken, second.AccessToken)
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		// token expiring in a few seconds is treated as expired
+		srv := newServer(5 * time.Second)
+
+		ts := github.NewAppTokenSource(github.AppTokenSourceParams{
+			HTTPClient:     srv.Client(),
+			BaseURL:        srv.URL,
+			AppID:          7,
+			InstallationID: 42,
+			PrivateKey:     parsedKey,
+		})
+
+		first, err := ts.Token()
+		require.NoError(t, err)
+
+		second, err := ts.Token()
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first.AccessToken, second.AccessToken)
+	})
+}
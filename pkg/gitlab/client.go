@@ -0,0 +1,296 @@
+// Package gitlab contains license resolver for modules hosted on GitLab (gitlab.com or self-hosted instances).
+// Project is found by module path (nested groups are supported), license is detected from repository files
+// at module version tag (or commit) read through GitLab REST API.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/pkg/apifiler"
+	"github.com/xakep666/licensevalidator/pkg/detect"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+	"github.com/xakep666/licensevalidator/pkg/vcsref"
+
+	"go.uber.org/zap"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/api"
+)
+
+var (
+	// ErrProjectNotFound returned if module doesn't belong to any project of instance
+	ErrProjectNotFound = fmt.Errorf("project not found")
+
+	// ErrModuleNotFound returned if project doesn't contain module version (tag, commit or directory)
+	ErrModuleNotFound = fmt.Errorf("module version not found in project")
+
+	// errNotFound returned by API calls on 404 status
+	errNotFound = apifiler.ErrNotFound
+)
+
+type ClientParams struct {
+	HTTPClient *http.Client
+
+	// BaseURL is a GitLab instance url (i.e. https://gitlab.com)
+	BaseURL string
+
+	// Hosts contains module path hosts served by instance. Default is BaseURL host.
+	Hosts []string
+
+	// Token is an optional personal, project or group access token.
+	// It's needed to access private projects.
+	Token string
+
+	// ConfidenceThreshold is a lower bound threshold of license matching confidence
+	ConfidenceThreshold float64
+}
+
+type Client struct {
+	ClientParams
+
+	log     *zap.Logger
+	client  *http.Client
+	apiBase string
+}
+
+func NewClient(logger *zap.Logger, params ClientParams) (*Client, error) {
+	u, err := url.Parse(params.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("base url parse failed: %w", err)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("base url %q has no host", params.BaseURL)
+	}
+
+	if len(params.Hosts) == 0 {
+		params.Hosts = []string{u.Hostname()}
+	}
+
+	hc := http.DefaultClient
+	if params.HTTPClient != nil {
+		hc = params.HTTPClient
+	}
+
+	return &Client{
+		ClientParams: params,
+		log:          logger.With(zap.String("component", "gitlab_client"), zap.String("host", u.Host)),
+		client:       hc,
+		apiBase:      strings.TrimSuffix(u.String(), "/") + "/api/v4",
+	}, nil
+}
+
+// Name returns resolver name used as component in explanation steps
+func (*Client) Name() string { return "gitlab" }
+
+// ResolveLicense detects module license from project files at module version.
+// If module directory doesn't contain license, parent directories are checked up to repository root.
+// ErrUnknownLicense returned if module is not hosted on instance or version not found.
+func (c *Client) ResolveLicense(ctx context.Context, m validation.Module) (validation.License, error) {
+	root, dir, err := c.open(ctx, m)
+	switch {
+	case errors.Is(err, nil):
+		// pass
+	case errors.Is(err, ErrProjectNotFound), errors.Is(err, ErrModuleNotFound):
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: c.Name(),
+			Message:   fmt.Sprintf("%s: %s", m.Name, err),
+		})
+		return validation.License{}, validation.ErrUnknownLicense
+	default:
+		return validation.License{}, err
+	}
+
+	for _, candidate := range vcsref.Parents(dir) {
+		licMatches, err := licensedb.Detect(root.Sub(candidate))
+		switch {
+		case errors.Is(err, nil):
+			return c.licenseToReturn(ctx, m, licMatches)
+		case errors.Is(err, licensedb.ErrNoLicenseFound):
+			c.log.Debug("No license found in directory", zap.Stringer("module", &m), zap.String("dir", candidate))
+			continue
+		default:
+			return validation.License{}, fmt.Errorf("licensedb detect failure: %w", err)
+		}
+	}
+
+	return c.licenseToReturn(ctx, m, nil)
+}
+
+// Filer returns filer over module directory at module version.
+// ErrProjectNotFound or ErrModuleNotFound returned if module can't be found.
+func (c *Client) Filer(ctx context.Context, m validation.Module) (*apifiler.Filer, error) {
+	root, dir, err := c.open(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return root.Sub(dir), nil
+}
+
+type project struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+// open finds module project and directory and returns filer over repository root at module version
+func (c *Client) open(ctx context.Context, m validation.Module) (*apifiler.Filer, string, error) {
+	p, root, err := c.findProject(ctx, m.Name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	loc := vcsref.Locate(root, m.Name)
+	ref := vcsref.FromVersion(loc.TagPrefix, m.Version)
+
+	c.log.Debug("Found project",
+		zap.Stringer("module", &m),
+		zap.String("project", p.PathWithNamespace),
+		zap.Stringer("ref", ref),
+	)
+
+	fs := apifiler.New(ctx, &apiRepository{client: c, project: p.ID, ref: ref.String()})
+
+	for _, dir := range loc.Dirs {
+		_, err := fs.Sub(dir).ReadDir("")
+		switch {
+		case err == nil:
+			return fs, dir, nil
+		case errors.Is(err, errNotFound):
+			continue
+		default:
+			return nil, "", err
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w: ref %s, directory %s", ErrModuleNotFound, ref, strings.Join(loc.Dirs, " or "))
+}
+
+// findProject finds project containing module. Module path prefixes are checked from longest one
+// because project may be in nested group (i.e. gitlab.com/group/subgroup/project/pkg).
+func (c *Client) findProject(ctx context.Context, modulePath string) (project, string, error) {
+	parts := strings.Split(modulePath, "/")
+	if len(parts) < 3 || !c.servesHost(parts[0]) {
+		return project{}, "", ErrProjectNotFound
+	}
+
+	for i := len(parts); i >= 3; i-- {
+		projectPath := strings.TrimSuffix(strings.Join(parts[1:i], "/"), ".git")
+
+		var p project
+		err := c.get(ctx, "/projects/"+url.PathEscape(projectPath), nil, &p)
+		switch {
+		case errors.Is(err, nil):
+			return p, strings.Join(parts[:i], "/"), nil
+		case errors.Is(err, errNotFound):
+			continue
+		default:
+			return project{}, "", fmt.Errorf("project %s request failed: %w", projectPath, err)
+		}
+	}
+
+	return project{}, "", ErrProjectNotFound
+}
+
+func (c *Client) servesHost(host string) bool {
+	for _, item := range c.Hosts {
+		if strings.EqualFold(item, host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// get performs API request and decodes JSON response to out. errNotFound returned on 404 status.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	resp, err := c.do(ctx, path, query)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("response decode failed: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.apiBase + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request construct failed: %w", err)
+	}
+
+	if c.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("make http request failed: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errNotFound
+	case resp.StatusCode != http.StatusOK:
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("server returned non-ok status: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return resp, nil
+}
+
+func (c *Client) licenseToReturn(ctx context.Context, m validation.Module, matches map[string]api.Match) (validation.License, error) {
+	c.log.Debug(
+		"license detector success",
+		zap.Reflect("license_matches", matches),
+		zap.Stringer("module", &m),
+	)
+
+	return detect.BestMatch(ctx, c.Name(), matches, c.ConfidenceThreshold)
+}
+
+// Check ensures that instance API is available and token (if provided) is valid
+func (c *Client) Check(ctx context.Context) error {
+	path := "/projects"
+	query := url.Values{"per_page": {"1"}, "simple": {"true"}}
+	if c.Token != "" {
+		// this endpoint requires authentication
+		path, query = "/version", nil
+	}
+
+	resp, err := c.do(ctx, path, query)
+	if err != nil {
+		return fmt.Errorf("gitlab api check failed: %w", err)
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+// nextPage returns next page number from pagination headers, zero means no more pages
+func nextPage(resp *http.Response) int {
+	page, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	return page
+}
@@ -0,0 +1,242 @@
+package gitlab_test
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/gitlab"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const testToken = "secret"
+
+func mitLicense(t *testing.T) []byte {
+	zr, err := zip.OpenReader("../goproxy/testdata/testify-1.5.1.zip")
+	require.NoError(t, err)
+
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != "github.com/stretchr/testify@v1.5.1/LICENSE" {
+			continue
+		}
+
+		rd, err := f.Open()
+		require.NoError(t, err)
+
+		defer rd.Close()
+
+		license, err := ioutil.ReadAll(rd)
+		require.NoError(t, err)
+
+		return license
+	}
+
+	t.Fatal("license not found")
+	return nil
+}
+
+type entry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// gitlabServer emulates GitLab API with project "group/sub/project" (id 42) containing:
+// * root module tagged v1.0.0 with MIT license
+// * module "pkg/v2" stored in "pkg" directory tagged pkg/v2.1.0 without own license
+func gitlabServer(t *testing.T) *httptest.Server {
+	license := mitLicense(t)
+
+	trees := map[string][]entry{
+		"v1.0.0":         {{Name: "LICENSE", Type: "blob"}, {Name: "go.mod", Type: "blob"}},
+		"pkg/v2.1.0":     {{Name: "LICENSE", Type: "blob"}, {Name: "pkg", Type: "tree"}},
+		"pkg/v2.1.0/pkg": {{Name: "go.mod", Type: "blob"}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		query := r.URL.Query()
+		switch path := r.URL.EscapedPath(); path {
+		case "/api/v4/version":
+			json.NewEncoder(w).Encode(map[string]string{"version": "13.0.0"})
+		case "/api/v4/projects/group%2Fsub%2Fproject":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "path_with_namespace": "group/sub/project"})
+		case "/api/v4/projects/42/repository/tree":
+			key := query.Get("ref")
+			if dir := query.Get("path"); dir != "" {
+				key += "/" + dir
+			}
+
+			entries, ok := trees[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			if entries == nil {
+				entries = []entry{}
+			}
+
+			json.NewEncoder(w).Encode(entries)
+		case "/api/v4/projects/42/repository/files/LICENSE/raw":
+			if _, ok := trees[query.Get("ref")]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Write(license)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestClient_ResolveLicense(t *testing.T) {
+	t.Parallel()
+
+	srv := gitlabServer(t)
+
+	client, err := gitlab.NewClient(zaptest.NewLogger(t), gitlab.ClientParams{
+		HTTPClient:          srv.Client(),
+		BaseURL:             srv.URL,
+		Hosts:               []string{"gitlab.mycorp.com"},
+		Token:               testToken,
+		ConfidenceThreshold: 0.8,
+	})
+	require.NoError(t, err)
+
+	type testCase struct {
+		name    string
+		module  string
+		version string
+		license validation.License
+		err     error
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			lic, err := client.ResolveLicense(context.Background(), validation.Module{
+				Name:    tc.module,
+				Version: semver.MustParse(tc.version),
+			})
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.license, lic)
+			}
+		})
+	}
+
+	f(testCase{
+		name:    "root module",
+		module:  "gitlab.mycorp.com/group/sub/project",
+		version: "v1.0.0",
+		license: validation.License{Name: "MIT License", SPDXID: "MIT"},
+	})
+	f(testCase{
+		name:    "major version directory with parent license",
+		module:  "gitlab.mycorp.com/group/sub/project/pkg/v2",
+		version: "v2.1.0",
+		license: validation.License{Name: "MIT License", SPDXID: "MIT"},
+	})
+	f(testCase{
+		name:    "unknown tag",
+		module:  "gitlab.mycorp.com/group/sub/project",
+		version: "v1.1.0",
+		err:     validation.ErrUnknownLicense,
+	})
+	f(testCase{
+		name:    "unknown project",
+		module:  "gitlab.mycorp.com/group/other",
+		version: "v1.0.0",
+		err:     validation.ErrUnknownLicense,
+	})
+	f(testCase{
+		name:    "other host",
+		module:  "github.com/group/sub/project",
+		version: "v1.0.0",
+		err:     validation.ErrUnknownLicense,
+	})
+}
+
+func TestClient_Filer(t *testing.T) {
+	t.Parallel()
+
+	srv := gitlabServer(t)
+
+	client, err := gitlab.NewClient(zaptest.NewLogger(t), gitlab.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Hosts:      []string{"gitlab.mycorp.com"},
+		Token:      testToken,
+	})
+	require.NoError(t, err)
+
+	fs, err := client.Filer(context.Background(), validation.Module{
+		Name:    "gitlab.mycorp.com/group/sub/project",
+		Version: semver.MustParse("v1.0.0"),
+	})
+	require.NoError(t, err)
+
+	files, err := fs.ReadDir("")
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	content, err := fs.ReadFile("LICENSE")
+	require.NoError(t, err)
+	assert.Equal(t, mitLicense(t), content)
+
+	_, err = client.Filer(context.Background(), validation.Module{
+		Name:    "gitlab.mycorp.com/group/sub/project",
+		Version: semver.MustParse("v1.1.0"),
+	})
+	assert.True(t, errors.Is(err, gitlab.ErrModuleNotFound), "unexpected error: %v", err)
+}
+
+func TestClient_Check(t *testing.T) {
+	t.Parallel()
+
+	srv := gitlabServer(t)
+
+	client, err := gitlab.NewClient(zaptest.NewLogger(t), gitlab.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Token:      testToken,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, client.Check(context.Background()))
+
+	client, err = gitlab.NewClient(zaptest.NewLogger(t), gitlab.ClientParams{
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		Token:      "invalid",
+	})
+	require.NoError(t, err)
+	assert.Error(t, client.Check(context.Background()))
+}
@@ -0,0 +1,88 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// apiRepository reads project repository tree through GitLab repository API
+type apiRepository struct {
+	client *Client
+
+	project int
+
+	// ref is a branch, tag or commit to read
+	ref string
+}
+
+func (r *apiRepository) projectPath() string {
+	return "/projects/" + strconv.Itoa(r.project)
+}
+
+func (r *apiRepository) OpenFile(ctx context.Context, p string) (io.ReadCloser, error) {
+	resp, err := r.client.do(ctx,
+		r.projectPath()+"/repository/files/"+url.PathEscape(p)+"/raw",
+		url.Values{"ref": {r.ref}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+type treeEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func (r *apiRepository) ListDir(ctx context.Context, p string) ([]filer.File, error) {
+	query := url.Values{"ref": {r.ref}, "per_page": {"100"}}
+	if p != "" {
+		query.Set("path", p)
+	}
+
+	var result []filer.File
+	for page := 1; page > 0; {
+		query.Set("page", strconv.Itoa(page))
+
+		resp, err := r.client.do(ctx, r.projectPath()+"/repository/tree", query)
+		if err != nil {
+			return nil, err
+		}
+
+		var entries []treeEntry
+		err = json.NewDecoder(resp.Body).Decode(&entries)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("listing decode failed: %w", err)
+		}
+
+		for _, entry := range entries {
+			switch entry.Type {
+			case "blob", "tree":
+				result = append(result, filer.File{
+					Name:  entry.Name,
+					IsDir: entry.Type == "tree",
+				})
+			default:
+				// submodules are not interesting
+			}
+		}
+
+		page = nextPage(resp)
+	}
+
+	// GitLab returns empty list for non-existing directories
+	if len(result) == 0 && p != "" {
+		return nil, errNotFound
+	}
+
+	return result, nil
+}
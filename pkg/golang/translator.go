@@ -12,6 +12,9 @@ var re = regexp.MustCompile(`^(go\.googlesource\.com|golang\.org/x)/([^/]+)$`)
 
 type Translator struct{}
 
+// Name returns component name of golang.org/x translator in explanation steps
+func (Translator) Name() string { return "golang_translator" }
+
 func (t Translator) Translate(ctx context.Context, m validation.Module) (translated validation.Module, err error) {
 	ms := re.FindStringSubmatch(m.Name)
 	if ms == nil {
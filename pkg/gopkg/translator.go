@@ -15,6 +15,9 @@ var re = regexp.MustCompile(`(?i)^gopkg\.in/(?:([a-zA-Z0-9][-a-zA-Z0-9]+)/)?([a-
 
 type Translator struct{}
 
+// Name returns component name of gopkg.in translator in explanation steps
+func (Translator) Name() string { return "gopkg_translator" }
+
 func (t Translator) Translate(ctx context.Context, m validation.Module) (translated validation.Module, err error) {
 	ms := re.FindStringSubmatch(m.Name)
 	if ms == nil {
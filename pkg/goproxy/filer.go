@@ -49,6 +49,13 @@ type ZipFiler struct {
 
 	onceInitTree sync.Once
 	tree         *zipNode
+
+	closer func() error
+}
+
+// NewZipFiler returns filer over module zip archive. closer is called on Close, it may be nil.
+func NewZipFiler(r *zip.Reader, m validation.Module, closer func() error) *ZipFiler {
+	return &ZipFiler{Reader: r, Module: m, closer: closer}
 }
 
 func (zf *ZipFiler) ReadFile(path string) ([]byte, error) {
@@ -141,7 +148,12 @@ func (zf *ZipFiler) ReadDir(path string) ([]filer.File, error) {
 	return result, nil
 }
 
-func (zf *ZipFiler) Close() {}
+// Close releases resources used by filer if it was opened by Client.ZipFiler
+func (zf *ZipFiler) Close() {
+	if zf.closer != nil {
+		_ = zf.closer()
+	}
+}
 
 func (zf *ZipFiler) PathsAreAlwaysSlash() bool { return true }
 
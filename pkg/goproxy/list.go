@@ -0,0 +1,118 @@
+package goproxy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrProxyOff returned if "off" reached in proxy list
+var ErrProxyOff = fmt.Errorf("module lookup disabled by GOPROXY=off")
+
+// ErrDirectNotSupported returned if proxy list contains only "direct" entries
+var ErrDirectNotSupported = fmt.Errorf(`"direct" is not supported, modules are fetched only through proxies`)
+
+// Proxy is an entry of GOPROXY-like proxy list
+type Proxy struct {
+	// URL is a proxy base url without trailing slash
+	URL string
+
+	// FallThroughOnError is true if proxy is followed by "|": next proxy is tried on any error.
+	// Otherwise (proxy followed by ",") next proxy is tried only if module not found (404 or 410 status).
+	FallThroughOnError bool
+
+	// Off is true for "off" entry which disables further lookup
+	Off bool
+}
+
+// ParseProxyList parses list of proxies with GOPROXY environment variable syntax:
+// proxies separated with "," are tried in order if module not found, separated with "|" are tried on any error,
+// "off" disables further lookup. URL without scheme is treated as https url.
+// "direct" entries are skipped (see HasDirect) because modules are fetched from version control systems by other resolvers,
+// ErrDirectNotSupported returned if there is nothing except them.
+func ParseProxyList(list string) ([]Proxy, error) {
+	var (
+		proxies []Proxy
+		direct  bool
+	)
+
+	for rest := strings.TrimSpace(list); rest != ""; {
+		item, fallThrough := rest, false
+		if i := strings.IndexAny(rest, ",|"); i >= 0 {
+			item, fallThrough, rest = rest[:i], rest[i] == '|', rest[i+1:]
+		} else {
+			rest = ""
+		}
+
+		switch item = strings.TrimSpace(item); item {
+		case "":
+			continue
+		case "direct":
+			direct = true
+			continue
+		case "off":
+			proxies = append(proxies, Proxy{Off: true})
+			continue
+		}
+
+		if !strings.Contains(item, "://") {
+			item = "https://" + item
+		}
+
+		u, err := url.Parse(item)
+		if err != nil {
+			return nil, fmt.Errorf("proxy url %q parse failed: %w", item, err)
+		}
+
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("proxy url %q must be an http(s) url with host", item)
+		}
+
+		proxies = append(proxies, Proxy{URL: strings.TrimSuffix(item, "/"), FallThroughOnError: fallThrough})
+	}
+
+	switch {
+	case len(proxies) == 0 && direct:
+		return nil, fmt.Errorf("proxy list %q: %w", list, ErrDirectNotSupported)
+	case len(proxies) == 0:
+		return nil, fmt.Errorf("proxy list %q contains no proxies", list)
+	default:
+		return proxies, nil
+	}
+}
+
+// HasDirect reports if proxy list contains "direct" entry which is ignored by ParseProxyList
+func HasDirect(list string) bool {
+	for _, item := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '|' }) {
+		if strings.TrimSpace(item) == "direct" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Walk calls fn with proxy base urls in order like go command does with GOPROXY list.
+// Next proxy is tried if fn returns error wrapping ErrModuleNotFound or any error if proxy is followed by "|".
+// Error of last called fn is returned, ErrProxyOff returned if "off" reached.
+func Walk(proxies []Proxy, fn func(baseURL string) error) error {
+	err := ErrModuleNotFound
+	for _, proxy := range proxies {
+		if proxy.Off {
+			return ErrProxyOff
+		}
+
+		err = fn(proxy.URL)
+		switch {
+		case errors.Is(err, nil):
+			return nil
+		case errors.Is(err, ErrModuleNotFound), proxy.FallThroughOnError:
+			continue
+		default:
+			return err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,69 @@
+package goproxy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/goproxy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProxyList(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		list    string
+		proxies []goproxy.Proxy
+		err     bool
+		errIs   error
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.list, func(t *testing.T) {
+			t.Parallel()
+
+			proxies, err := goproxy.ParseProxyList(tc.list)
+			if tc.err {
+				assert.Error(t, err)
+				if tc.errIs != nil {
+					assert.True(t, errors.Is(err, tc.errIs), "unexpected error: %v", err)
+				}
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.proxies, proxies)
+			}
+		})
+	}
+
+	f(testCase{
+		list:    "https://proxy.golang.org",
+		proxies: []goproxy.Proxy{{URL: "https://proxy.golang.org"}},
+	})
+	f(testCase{
+		list: "http://internal.corp/goproxy/|proxy.golang.org,direct",
+		proxies: []goproxy.Proxy{
+			{URL: "http://internal.corp/goproxy", FallThroughOnError: true},
+			{URL: "https://proxy.golang.org"},
+		},
+	})
+	f(testCase{
+		list:    "https://proxy.golang.org,off",
+		proxies: []goproxy.Proxy{{URL: "https://proxy.golang.org"}, {Off: true}},
+	})
+	f(testCase{list: "direct", err: true, errIs: goproxy.ErrDirectNotSupported})
+	f(testCase{list: " direct | direct ", err: true, errIs: goproxy.ErrDirectNotSupported})
+	f(testCase{list: ",", err: true})
+	f(testCase{list: "ftp://proxy.corp", err: true})
+}
+
+func TestHasDirect(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, goproxy.HasDirect("https://proxy.golang.org,direct"))
+	assert.True(t, goproxy.HasDirect("https://proxy.golang.org| direct"))
+	assert.False(t, goproxy.HasDirect("https://proxy.golang.org,off"))
+	assert.False(t, goproxy.HasDirect("https://direct.corp"))
+}
@@ -8,7 +8,7 @@ import (
 	"mime"
 	"net/http"
 
-	"github.com/xakep666/licensevalidator/pkg/spdx"
+	"github.com/xakep666/licensevalidator/pkg/detect"
 	"github.com/xakep666/licensevalidator/pkg/validation"
 
 	bufra "github.com/avvmoto/buf-readerat"
@@ -18,6 +18,9 @@ import (
 	"gopkg.in/src-d/go-license-detector.v3/licensedb/api"
 )
 
+// ErrModuleNotFound returned if proxy responded that module doesn't exist
+var ErrModuleNotFound = fmt.Errorf("module not found")
+
 type InvalidContentTypeErr string
 
 func (e InvalidContentTypeErr) Error() string {
@@ -27,7 +30,8 @@ func (e InvalidContentTypeErr) Error() string {
 type ClientParams struct {
 	HTTPClient *http.Client
 
-	// BaseURL is a proxy base url (i.e. https://proxy.golang.org)
+	// BaseURL is a proxy base url (i.e. https://proxy.golang.org) or list of proxies
+	// with GOPROXY environment variable syntax (see ParseProxyList)
 	BaseURL string
 
 	// StoreMemLimit is a limit for in-memory zip storage when server not supports http range requests
@@ -45,36 +49,95 @@ type ClientParams struct {
 type Client struct {
 	ClientParams
 
-	log    *zap.Logger
-	client *http.Client
+	log     *zap.Logger
+	client  *http.Client
+	proxies []Proxy
 }
 
-func NewClient(logger *zap.Logger, params ClientParams) *Client {
+func NewClient(logger *zap.Logger, params ClientParams) (*Client, error) {
+	proxies, err := ParseProxyList(params.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
 	hc := http.DefaultClient
 	if params.HTTPClient != nil {
 		hc = params.HTTPClient
 	}
-	return &Client{
+
+	c := &Client{
 		ClientParams: params,
 		log:          logger.With(zap.String("component", "goproxy_client")),
 		client:       hc,
+		proxies:      proxies,
 	}
+
+	if HasDirect(params.BaseURL) {
+		c.log.Warn("\"direct\" entries of proxy list are ignored, modules are fetched only through proxies", zap.String("list", params.BaseURL))
+	}
+
+	return c, nil
 }
 
+// Name returns resolver name used as component in explanation steps
+func (*Client) Name() string { return "goproxy" }
+
 // ResolveLicense attempts to resolve license using project zip file.
 // Content-Type must be application/zip otherwise InvalidContentTypeErr error returned.
 // It uses http range requests to not fully download file when server supports it.
+// Proxies are tried in order according to list syntax.
 func (c *Client) ResolveLicense(ctx context.Context, m validation.Module) (validation.License, error) {
-	l := c.log.With(zap.Stringer("module", &m))
-	moduleZIPPath := fmt.Sprintf("%s/%s/@v/%s.zip", c.BaseURL, m.Name, m.Version.Original())
+	zf, err := c.ZipFiler(ctx, m)
+	switch {
+	case errors.Is(err, nil):
+		// pass
+	case errors.Is(err, ErrModuleNotFound), errors.Is(err, ErrProxyOff):
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: c.Name(),
+			Message:   fmt.Sprintf("%s: %s", m.Name, err),
+		})
+		return validation.License{}, validation.ErrUnknownLicense
+	default:
+		return validation.License{}, err
+	}
+
+	defer zf.Close()
+
+	licMatches, err := licensedb.Detect(zf)
+	if err != nil {
+		return validation.License{}, fmt.Errorf("licensedb detect failure: %w", err)
+	}
+
+	return c.licenseToReturn(ctx, m, licMatches)
+}
+
+// ZipFiler opens module zip file and returns filer over its content. Proxies are tried in order according to list syntax.
+// ErrModuleNotFound returned if proxies don't have such module, ErrProxyOff returned if "off" reached.
+// Returned filer must be closed after usage to release downloaded data.
+func (c *Client) ZipFiler(ctx context.Context, m validation.Module) (*ZipFiler, error) {
+	var zf *ZipFiler
+	err := Walk(c.proxies, func(baseURL string) (err error) {
+		zf, err = c.zipFiler(ctx, baseURL, m)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return zf, nil
+}
+
+func (c *Client) zipFiler(ctx context.Context, baseURL string, m validation.Module) (*ZipFiler, error) {
+	l := c.log.With(zap.Stringer("module", &m), zap.String("proxy", baseURL))
+	moduleZIPPath := fmt.Sprintf("%s/%s/@v/%s.zip", baseURL, m.Name, m.Version.Original())
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, moduleZIPPath, nil)
 	if err != nil {
-		return validation.License{}, fmt.Errorf("construct request failed: %w", err)
+		return nil, fmt.Errorf("construct request failed: %w", err)
 	}
 
 	store := c.makeStore()
-	defer store.Close()
 
 	var codeErr *httpreaderat.ErrUnexpectedResponseCode
 	rd, err := httpreaderat.New(c.client, req, store)
@@ -82,35 +145,35 @@ func (c *Client) ResolveLicense(ctx context.Context, m validation.Module) (valid
 	case errors.Is(err, nil):
 		// pass
 	case errors.As(err, &codeErr):
+		store.Close()
 		if codeErr.Code == http.StatusNotFound || codeErr.Code == http.StatusGone {
-			return validation.License{}, validation.ErrUnknownLicense
+			return nil, ErrModuleNotFound
 		}
 		l.Error("unexpected status code", zap.Error(err))
-		fallthrough
+		return nil, fmt.Errorf("module zip request failed: %w", err)
 	default:
-		return validation.License{}, fmt.Errorf("module zip request failed: %w", err)
+		store.Close()
+		return nil, fmt.Errorf("module zip request failed: %w", err)
 	}
 
 	mt, _, err := mime.ParseMediaType(rd.ContentType())
 	if err != nil {
-		return validation.License{}, fmt.Errorf("parse content type failed: %w", InvalidContentTypeErr(rd.ContentType()))
+		store.Close()
+		return nil, fmt.Errorf("parse content type failed: %w", InvalidContentTypeErr(rd.ContentType()))
 	}
 
 	if mt != "application/zip" {
-		return validation.License{}, InvalidContentTypeErr(mt)
+		store.Close()
+		return nil, InvalidContentTypeErr(mt)
 	}
 
 	moduleZIP, err := zip.NewReader(bufra.NewBufReaderAt(rd, 1024*1024), rd.Size())
 	if err != nil {
-		return validation.License{}, fmt.Errorf("module zip open failed: %w", err)
-	}
-
-	licMatches, err := licensedb.Detect(&ZipFiler{Reader: moduleZIP, Module: m})
-	if err != nil {
-		return validation.License{}, fmt.Errorf("licensedb detect failure: %w", err)
+		store.Close()
+		return nil, fmt.Errorf("module zip open failed: %w", err)
 	}
 
-	return c.licenseToReturn(m, licMatches)
+	return NewZipFiler(moduleZIP, m, store.Close), nil
 }
 
 func (c *Client) makeStore() httpreaderat.Store {
@@ -129,40 +192,34 @@ func (c *Client) makeStore() httpreaderat.Store {
 			httpreaderat.NewStoreFile(), storeFileLimit, nil))
 }
 
-func (c *Client) licenseToReturn(m validation.Module, matches map[string]api.Match) (validation.License, error) {
-	var (
-		mostConfidentLicence string
-		maxConfidence        float64
-	)
-
+func (c *Client) licenseToReturn(ctx context.Context, m validation.Module, matches map[string]api.Match) (validation.License, error) {
 	c.log.Debug(
 		"license detector success",
 		zap.Reflect("license_matches", matches),
 		zap.Stringer("module", &m),
 	)
 
-	for name, match := range matches {
-		confidence := float64(match.Confidence)
-		if confidence >= c.ConfidenceThreshold && confidence > maxConfidence {
-			maxConfidence = confidence
-			mostConfidentLicence = name
-		}
-	}
+	return detect.BestMatch(ctx, c.Name(), matches, c.ConfidenceThreshold)
+}
 
-	if mostConfidentLicence == "" {
-		return validation.License{}, validation.ErrUnknownLicense
+// Check ensures that proxies are available. Proxies are checked in order until available one found
+// the same way as modules are requested.
+func (c *Client) Check(ctx context.Context) error {
+	var lastErr error
+	err := Walk(c.proxies, func(baseURL string) error {
+		lastErr = c.check(ctx, baseURL)
+		return lastErr
+	})
+	if errors.Is(err, ErrProxyOff) {
+		// nothing to check after "off"
+		return lastErr
 	}
 
-	licInfo, _ := spdx.LicenseByID(mostConfidentLicence)
-
-	return validation.License{
-		Name:   licInfo.Name,
-		SPDXID: mostConfidentLicence,
-	}, nil
+	return err
 }
 
-func (c *Client) Check(ctx context.Context) error {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/github.com/golang/go/@v/list", c.BaseURL), nil)
+func (c *Client) check(ctx context.Context, baseURL string) error {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/github.com/golang/go/@v/list", baseURL), nil)
 	if err != nil {
 		return fmt.Errorf("http request construct failed: %w", err)
 	}
@@ -14,9 +14,17 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
 
+func newClient(t *testing.T, params goproxy.ClientParams) *goproxy.Client {
+	client, err := goproxy.NewClient(zaptest.NewLogger(t), params)
+	require.NoError(t, err)
+
+	return client
+}
+
 func TestClient_ResolveLicense(t *testing.T) {
 	t.Parallel()
 	mockedServerMux := http.NewServeMux()
@@ -39,7 +47,7 @@ func TestClient_ResolveLicense(t *testing.T) {
 	server := httptest.NewServer(mockedServerMux)
 
 	t.Run("detect MIT", func(t *testing.T) {
-		client := goproxy.NewClient(zaptest.NewLogger(t), goproxy.ClientParams{
+		client := newClient(t, goproxy.ClientParams{
 			HTTPClient:          server.Client(),
 			BaseURL:             server.URL,
 			ConfidenceThreshold: 0.8,
@@ -58,7 +66,7 @@ func TestClient_ResolveLicense(t *testing.T) {
 	})
 
 	t.Run("detection fails by threshold", func(t *testing.T) {
-		client := goproxy.NewClient(zaptest.NewLogger(t), goproxy.ClientParams{
+		client := newClient(t, goproxy.ClientParams{
 			HTTPClient:          server.Client(),
 			BaseURL:             server.URL,
 			ConfidenceThreshold: 0.99,
@@ -72,7 +80,7 @@ func TestClient_ResolveLicense(t *testing.T) {
 	})
 
 	t.Run("invalid content-type", func(t *testing.T) {
-		client := goproxy.NewClient(zaptest.NewLogger(t), goproxy.ClientParams{
+		client := newClient(t, goproxy.ClientParams{
 			HTTPClient:          server.Client(),
 			BaseURL:             server.URL,
 			ConfidenceThreshold: 0.99,
@@ -90,7 +98,7 @@ func TestClient_ResolveLicense(t *testing.T) {
 	})
 
 	t.Run("not found module", func(t *testing.T) {
-		client := goproxy.NewClient(zaptest.NewLogger(t), goproxy.ClientParams{
+		client := newClient(t, goproxy.ClientParams{
 			HTTPClient:          server.Client(),
 			BaseURL:             server.URL,
 			ConfidenceThreshold: 0.99,
@@ -104,7 +112,7 @@ func TestClient_ResolveLicense(t *testing.T) {
 	})
 
 	t.Run("health check", func(t *testing.T) {
-		client := goproxy.NewClient(zaptest.NewLogger(t), goproxy.ClientParams{
+		client := newClient(t, goproxy.ClientParams{
 			HTTPClient:          server.Client(),
 			BaseURL:             server.URL,
 			ConfidenceThreshold: 0.99,
@@ -114,3 +122,89 @@ func TestClient_ResolveLicense(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestClient_proxyList(t *testing.T) {
+	t.Parallel()
+
+	main := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/stretchr/testify/@v/v1.5.1.zip":
+			http.ServeFile(w, r, path.Join("testdata", "testify-1.5.1.zip"))
+		case "/github.com/golang/go/@v/list":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(main.Close)
+
+	internal := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(internal.Close)
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	t.Cleanup(broken.Close)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	type testCase struct {
+		name     string
+		list     string
+		err      error
+		failure  bool
+		checkErr bool
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newClient(t, goproxy.ClientParams{
+				BaseURL:             tc.list,
+				ConfidenceThreshold: 0.8,
+			})
+
+			lic, err := client.ResolveLicense(context.Background(), validation.Module{
+				Name:    "github.com/stretchr/testify",
+				Version: semver.MustParse("v1.5.1"),
+			})
+			switch {
+			case tc.failure:
+				assert.Error(t, err)
+				assert.False(t, errors.Is(err, validation.ErrUnknownLicense), "resolvers chain must not continue")
+			case tc.err != nil:
+				assert.True(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+			case assert.NoError(t, err):
+				assert.Equal(t, "MIT", lic.SPDXID)
+			}
+
+			if tc.checkErr {
+				assert.Error(t, client.Check(context.Background()))
+			} else {
+				assert.NoError(t, client.Check(context.Background()))
+			}
+		})
+	}
+
+	f(testCase{
+		name: "not found falls through",
+		list: internal.URL + "," + main.URL,
+	})
+	f(testCase{
+		name:     "error doesn't fall through after comma",
+		list:     broken.URL + "," + main.URL,
+		failure:  true,
+		checkErr: true,
+	})
+	f(testCase{
+		name: "error falls through after pipe",
+		list: broken.URL + "|" + down.URL + "|" + main.URL,
+	})
+	f(testCase{
+		name: "off",
+		list: internal.URL + ",off," + main.URL,
+		err:  validation.ErrUnknownLicense,
+	})
+}
@@ -0,0 +1,160 @@
+// Package modcache contains license resolver working over local go modules cache (GOMODCACHE).
+// It's intended for air-gapped environments where neither goproxy nor VCS hosting is reachable.
+package modcache
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+
+	"github.com/xakep666/licensevalidator/pkg/detect"
+	"github.com/xakep666/licensevalidator/pkg/goproxy"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"go.uber.org/zap"
+	"golang.org/x/mod/module"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/api"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// ErrModuleNotFound returned if module is absent in cache
+var ErrModuleNotFound = fmt.Errorf("module not found in cache")
+
+// DefaultDir returns modules cache directory the same way as go command does it:
+// GOMODCACHE environment variable or "pkg/mod" inside first GOPATH entry.
+func DefaultDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	gopath := filepath.SplitList(build.Default.GOPATH)
+	if len(gopath) == 0 || gopath[0] == "" {
+		return ""
+	}
+
+	return filepath.Join(gopath[0], "pkg", "mod")
+}
+
+type ClientParams struct {
+	// Dir is a modules cache directory. Default is DefaultDir().
+	Dir string
+
+	// ConfidenceThreshold is a lower bound threshold of license matching confidence
+	ConfidenceThreshold float64
+}
+
+// Client resolves licenses using module files from extracted module directory (<module>@<version>)
+// or downloaded module zip (cache/download/<module>/@v/<version>.zip)
+type Client struct {
+	ClientParams
+
+	log *zap.Logger
+}
+
+func NewClient(logger *zap.Logger, params ClientParams) *Client {
+	if params.Dir == "" {
+		params.Dir = DefaultDir()
+	}
+
+	return &Client{
+		ClientParams: params,
+		log:          logger.With(zap.String("component", "modcache_client")),
+	}
+}
+
+// Name returns resolver name used as component in explanation steps
+func (*Client) Name() string { return "modcache" }
+
+// ResolveLicense detects module license from cached files.
+// ErrUnknownLicense returned if module is absent in cache.
+func (c *Client) ResolveLicense(ctx context.Context, m validation.Module) (validation.License, error) {
+	fs, err := c.Filer(m)
+	switch {
+	case errors.Is(err, nil):
+		// pass
+	case errors.Is(err, ErrModuleNotFound):
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: c.Name(),
+			Message:   fmt.Sprintf("%s: module not found in cache", m.Name),
+		})
+		return validation.License{}, validation.ErrUnknownLicense
+	default:
+		return validation.License{}, err
+	}
+
+	defer fs.Close()
+
+	licMatches, err := licensedb.Detect(fs)
+	switch {
+	case errors.Is(err, nil):
+		return c.licenseToReturn(ctx, m, licMatches)
+	case errors.Is(err, licensedb.ErrNoLicenseFound):
+		c.log.Debug("No license found in module", zap.Stringer("module", &m))
+		return c.licenseToReturn(ctx, m, nil)
+	default:
+		return validation.License{}, fmt.Errorf("licensedb detect failure: %w", err)
+	}
+}
+
+// Filer returns filer over module root. Extracted module directory is preferred over zip archive.
+// ErrModuleNotFound returned if module is absent in cache. Returned filer must be closed after usage.
+func (c *Client) Filer(m validation.Module) (filer.Filer, error) {
+	escapedPath, err := module.EscapePath(m.Name)
+	if err != nil {
+		return nil, fmt.Errorf("module path escape failed: %w", err)
+	}
+
+	escapedVersion, err := module.EscapeVersion(m.Version.Original())
+	if err != nil {
+		return nil, fmt.Errorf("module version escape failed: %w", err)
+	}
+
+	dir := filepath.Join(c.Dir, filepath.FromSlash(escapedPath)+"@"+escapedVersion)
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		c.log.Debug("Using extracted module", zap.Stringer("module", &m), zap.String("dir", dir))
+		return filer.FromDirectory(dir)
+	}
+
+	zipPath := filepath.Join(c.Dir, "cache", "download", filepath.FromSlash(escapedPath), "@v", escapedVersion+".zip")
+
+	rc, err := zip.OpenReader(zipPath)
+	switch {
+	case errors.Is(err, nil):
+		c.log.Debug("Using module zip", zap.Stringer("module", &m), zap.String("zip", zipPath))
+		return goproxy.NewZipFiler(&rc.Reader, m, rc.Close), nil
+	case os.IsNotExist(err):
+		return nil, ErrModuleNotFound
+	default:
+		return nil, fmt.Errorf("module zip open failed: %w", err)
+	}
+}
+
+func (c *Client) licenseToReturn(ctx context.Context, m validation.Module, matches map[string]api.Match) (validation.License, error) {
+	c.log.Debug(
+		"license detector success",
+		zap.Reflect("license_matches", matches),
+		zap.Stringer("module", &m),
+	)
+
+	return detect.BestMatch(ctx, c.Name(), matches, c.ConfidenceThreshold)
+}
+
+// Check ensures that cache directory is accessible
+func (c *Client) Check(context.Context) error {
+	info, err := os.Stat(c.Dir)
+	if err != nil {
+		return fmt.Errorf("modules cache stat failed: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("modules cache %s is not a directory", c.Dir)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package modcache_test
+
+import (
+	"archive/zip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/modcache"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const testifyZIP = "../goproxy/testdata/testify-1.5.1.zip"
+
+// prepareCache builds modules cache with testify zip, extracted module with upper-case letters in path
+// and extracted module without license
+func prepareCache(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "modcache")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	zipData, err := ioutil.ReadFile(testifyZIP)
+	require.NoError(t, err)
+
+	zipDir := filepath.Join(dir, "cache", "download", "github.com", "stretchr", "testify", "@v")
+	require.NoError(t, os.MkdirAll(zipDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(zipDir, "v1.5.1.zip"), zipData, 0644))
+
+	zr, err := zip.OpenReader(testifyZIP)
+	require.NoError(t, err)
+
+	defer zr.Close()
+
+	var license []byte
+	for _, f := range zr.File {
+		if f.Name == "github.com/stretchr/testify@v1.5.1/LICENSE" {
+			rd, err := f.Open()
+			require.NoError(t, err)
+
+			license, err = ioutil.ReadAll(rd)
+			rd.Close()
+			require.NoError(t, err)
+		}
+	}
+
+	require.NotEmpty(t, license)
+
+	moduleDir := filepath.Join(dir, "github.com", "!burnt!sushi", "toml@v0.3.1")
+	require.NoError(t, os.MkdirAll(moduleDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(moduleDir, "COPYING"), license, 0644))
+
+	unlicensedDir := filepath.Join(dir, "example.com", "unlicensed@v1.0.0")
+	require.NoError(t, os.MkdirAll(unlicensedDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(unlicensedDir, "go.mod"), []byte("module example.com/unlicensed\n"), 0644))
+
+	return dir
+}
+
+func TestClient_ResolveLicense(t *testing.T) {
+	t.Parallel()
+
+	dir := prepareCache(t)
+
+	client := modcache.NewClient(zaptest.NewLogger(t), modcache.ClientParams{
+		Dir:                 dir,
+		ConfidenceThreshold: 0.8,
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		lic, err := client.ResolveLicense(context.Background(), validation.Module{
+			Name:    "github.com/stretchr/testify",
+			Version: semver.MustParse("v1.5.1"),
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+		}
+	})
+
+	t.Run("extracted directory", func(t *testing.T) {
+		lic, err := client.ResolveLicense(context.Background(), validation.Module{
+			Name:    "github.com/BurntSushi/toml",
+			Version: semver.MustParse("v0.3.1"),
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, validation.License{Name: "MIT License", SPDXID: "MIT"}, lic)
+		}
+	})
+
+	t.Run("not in cache", func(t *testing.T) {
+		_, err := client.ResolveLicense(context.Background(), validation.Module{
+			Name:    "github.com/stretchr/testify",
+			Version: semver.MustParse("v1.6.0"),
+		})
+		assert.Equal(t, validation.ErrUnknownLicense, err)
+	})
+
+	t.Run("no license files", func(t *testing.T) {
+		var explanation validation.Explanation
+		_, err := client.ResolveLicense(validation.WithExplanation(context.Background(), &explanation), validation.Module{
+			Name:    "example.com/unlicensed",
+			Version: semver.MustParse("v1.0.0"),
+		})
+		assert.Equal(t, validation.ErrUnknownLicense, err)
+		if assert.Len(t, explanation.Steps, 1) {
+			assert.Equal(t, "no license matches with confidence >= 0.80", explanation.Steps[0].Message)
+		}
+	})
+
+	t.Run("detection fails by threshold", func(t *testing.T) {
+		client := modcache.NewClient(zaptest.NewLogger(t), modcache.ClientParams{
+			Dir:                 dir,
+			ConfidenceThreshold: 1.1,
+		})
+
+		_, err := client.ResolveLicense(context.Background(), validation.Module{
+			Name:    "github.com/stretchr/testify",
+			Version: semver.MustParse("v1.5.1"),
+		})
+		assert.Equal(t, validation.ErrUnknownLicense, err)
+	})
+}
+
+func TestClient_Check(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, modcache.NewClient(zaptest.NewLogger(t), modcache.ClientParams{Dir: prepareCache(t)}).Check(context.Background()))
+	assert.Error(t, modcache.NewClient(zaptest.NewLogger(t), modcache.ClientParams{Dir: "/nonexistent"}).Check(context.Background()))
+}
@@ -0,0 +1,270 @@
+// Package modlist contains parsers of module lists in formats used by go tooling:
+// go.mod, go.sum, "go list -m -json" output and plain JSON list of modules.
+package modlist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"golang.org/x/mod/modfile"
+)
+
+type Format string
+
+const (
+	// FormatAuto means that format should be detected from content
+	FormatAuto Format = ""
+
+	// FormatJSON is a JSON array of objects with "Module" and "Version" fields (same as admission request)
+	FormatJSON Format = "json"
+
+	// FormatGoMod is a go.mod file content. Local replacements are skipped.
+	FormatGoMod Format = "gomod"
+
+	// FormatGoSum is a go.sum file content. Only modules with content hashes (not go.mod-only) are taken.
+	FormatGoSum Format = "gosum"
+
+	// FormatGoList is an output of "go list -m -json all". Main module and local replacements are skipped.
+	FormatGoList Format = "golist"
+)
+
+// ErrUnknownFormat returned if format can't be detected or not supported
+var ErrUnknownFormat = fmt.Errorf("unknown module list format")
+
+// Detect guesses module list format by content
+func Detect(data []byte) Format {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return FormatAuto
+	case trimmed[0] == '[':
+		return FormatJSON
+	case trimmed[0] == '{':
+		return FormatGoList
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+
+		if len(fields) == 3 && strings.HasPrefix(fields[2], "h1:") {
+			return FormatGoSum
+		}
+
+		switch fields[0] {
+		case "module", "go", "require", "replace", "exclude", "retract":
+			return FormatGoMod
+		}
+
+		return FormatAuto
+	}
+
+	return FormatAuto
+}
+
+// Parse parses module list in given format. Format is detected if FormatAuto passed.
+// Returned modules are unique and ordered as in source.
+func Parse(data []byte, format Format) ([]validation.Module, error) {
+	if format == FormatAuto {
+		format = Detect(data)
+	}
+
+	var (
+		modules []validation.Module
+		err     error
+	)
+
+	switch format {
+	case FormatJSON:
+		modules, err = parseJSON(data)
+	case FormatGoMod:
+		modules, err = parseGoMod(data)
+	case FormatGoSum:
+		modules, err = parseGoSum(data)
+	case FormatGoList:
+		modules, err = parseGoList(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s parse failed: %w", format, err)
+	}
+
+	return unique(modules), nil
+}
+
+func newModule(name, version string) (validation.Module, error) {
+	if name == "" {
+		return validation.Module{}, fmt.Errorf("empty module name")
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return validation.Module{}, fmt.Errorf("invalid version %q of %s: %w", version, name, err)
+	}
+
+	return validation.Module{Name: name, Version: v}, nil
+}
+
+func parseJSON(data []byte) ([]validation.Module, error) {
+	var items []struct {
+		Module  string
+		Version string
+	}
+
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	ret := make([]validation.Module, 0, len(items))
+	for _, item := range items {
+		m, err := newModule(item.Module, item.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, m)
+	}
+
+	return ret, nil
+}
+
+func parseGoMod(data []byte) ([]validation.Module, error) {
+	file, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]validation.Module, 0, len(file.Require))
+	for _, req := range file.Require {
+		name, version := req.Mod.Path, req.Mod.Version
+
+		for _, replace := range file.Replace {
+			if replace.Old.Path != name || replace.Old.Version != "" && replace.Old.Version != version {
+				continue
+			}
+
+			name, version = replace.New.Path, replace.New.Version
+		}
+
+		if version == "" {
+			// local replacement
+			continue
+		}
+
+		m, err := newModule(name, version)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, m)
+	}
+
+	return ret, nil
+}
+
+func parseGoSum(data []byte) ([]validation.Module, error) {
+	var ret []validation.Module
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: malformed entry", line)
+		}
+
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			// module content is not downloaded
+			continue
+		}
+
+		m, err := newModule(fields[0], fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		ret = append(ret, m)
+	}
+
+	return ret, scanner.Err()
+}
+
+func parseGoList(data []byte) ([]validation.Module, error) {
+	type listModule struct {
+		Path    string
+		Version string
+		Main    bool
+		Replace *listModule
+	}
+
+	var ret []validation.Module
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var item listModule
+
+		err := decoder.Decode(&item)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if item.Main {
+			continue
+		}
+
+		if item.Replace != nil {
+			item = *item.Replace
+		}
+
+		if item.Version == "" {
+			// local replacement
+			continue
+		}
+
+		m, err := newModule(item.Path, item.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, m)
+	}
+
+	return ret, nil
+}
+
+func unique(modules []validation.Module) []validation.Module {
+	seen := make(map[string]struct{}, len(modules))
+	ret := modules[:0]
+
+	for _, m := range modules {
+		key := m.Name + "@" + m.Version.Original()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		ret = append(ret, m)
+	}
+
+	return ret
+}
@@ -0,0 +1,147 @@
+package modlist_test
+
+import (
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/modlist"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name            string
+		Data            string
+		Format          modlist.Format
+		ExpectedFormat  modlist.Format
+		ExpectedModules []string
+		ExpectError     bool
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			if tc.ExpectedFormat != "" {
+				assert.Equal(t, tc.ExpectedFormat, modlist.Detect([]byte(tc.Data)))
+			}
+
+			modules, err := modlist.Parse([]byte(tc.Data), tc.Format)
+			if tc.ExpectError {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.ExpectedModules, moduleStrings(modules))
+			}
+		})
+	}
+
+	f(testCase{
+		Name: "json",
+		Data: /*language=json*/ `[
+			{"Module": "github.com/stretchr/testify", "Version": "v1.5.1"},
+			{"Module": "go.uber.org/zap", "Version": "v1.15.0"},
+			{"Module": "github.com/stretchr/testify", "Version": "v1.5.1"}
+		]`,
+		ExpectedFormat:  modlist.FormatJSON,
+		ExpectedModules: []string{"github.com/stretchr/testify@v1.5.1", "go.uber.org/zap@v1.15.0"},
+	})
+
+	f(testCase{
+		Name: "go.mod",
+		Data: `// comment
+module github.com/test/test
+
+go 1.14
+
+require (
+	github.com/stretchr/testify v1.5.1
+	go.uber.org/zap v1.15.0 // indirect
+	github.com/local/module v1.0.0
+	github.com/forked/module v1.0.0
+)
+
+replace github.com/local/module => ../module
+
+replace github.com/forked/module v1.0.0 => github.com/fork/module v1.0.1
+`,
+		ExpectedFormat: modlist.FormatGoMod,
+		ExpectedModules: []string{
+			"github.com/stretchr/testify@v1.5.1",
+			"go.uber.org/zap@v1.15.0",
+			"github.com/fork/module@v1.0.1",
+		},
+	})
+
+	f(testCase{
+		Name: "go.sum",
+		Data: `github.com/davecgh/go-spew v1.1.0/go.mod h1:J7Y8YcW2NihsgmVo/mv3lAwl/skON4iLHjSsI+c5H38=
+github.com/davecgh/go-spew v1.1.1 h1:vj9j/u1bqnvCEfJOwUhtlOARqs3+rkHYY13jYWTU97c=
+github.com/davecgh/go-spew v1.1.1/go.mod h1:J7Y8YcW2NihsgmVo/mv3lAwl/skON4iLHjSsI+c5H38=
+github.com/stretchr/testify v1.5.1 h1:nOGnQDM7FYENwehXlg/kFVnos3rEvtKTjRvOWSzb6H4=
+`,
+		ExpectedFormat:  modlist.FormatGoSum,
+		ExpectedModules: []string{"github.com/davecgh/go-spew@v1.1.1", "github.com/stretchr/testify@v1.5.1"},
+	})
+
+	f(testCase{
+		Name: "go list",
+		Data: `{
+	"Path": "github.com/test/test",
+	"Main": true,
+	"Dir": "/src/test"
+}
+{
+	"Path": "github.com/stretchr/testify",
+	"Version": "v1.5.1"
+}
+{
+	"Path": "github.com/forked/module",
+	"Version": "v1.0.0",
+	"Replace": {
+		"Path": "github.com/fork/module",
+		"Version": "v1.0.1"
+	}
+}
+{
+	"Path": "github.com/local/module",
+	"Version": "v1.0.0",
+	"Replace": {
+		"Path": "../module"
+	}
+}
+`,
+		ExpectedFormat:  modlist.FormatGoList,
+		ExpectedModules: []string{"github.com/stretchr/testify@v1.5.1", "github.com/fork/module@v1.0.1"},
+	})
+
+	f(testCase{
+		Name:        "invalid version",
+		Data:        `[{"Module": "github.com/stretchr/testify", "Version": "bla"}]`,
+		ExpectError: true,
+	})
+
+	f(testCase{
+		Name:        "malformed go.sum",
+		Data:        "github.com/stretchr/testify v1.5.1",
+		Format:      modlist.FormatGoSum,
+		ExpectError: true,
+	})
+
+	f(testCase{
+		Name:        "unknown format",
+		Data:        "some text",
+		ExpectError: true,
+	})
+}
+
+func moduleStrings(modules []validation.Module) []string {
+	ret := make([]string, 0, len(modules))
+	for _, m := range modules {
+		ret = append(ret, m.Name+"@"+m.Version.Original())
+	}
+
+	return ret
+}
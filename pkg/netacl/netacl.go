@@ -0,0 +1,171 @@
+// Package netacl contains network access control for http handlers:
+// caller networks allow/deny lists, client address detection behind trusted proxies
+// and protection from requests originated by target goproxy.
+package netacl
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ParseCIDRs parses list of networks in CIDR notation. Single IP addresses are also accepted.
+func ParseCIDRs(items []string) ([]*net.IPNet, error) {
+	ret := make([]*net.IPNet, 0, len(items))
+	for _, item := range items {
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip address %s", item)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+
+			ret = append(ret, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %s: %w", item, err)
+		}
+
+		ret = append(ret, ipNet)
+	}
+
+	return ret, nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return net.ParseIP(host)
+}
+
+// ClientIP detects real client address.
+// X-Forwarded-For and X-Real-Ip headers are taken into account only if request came from trusted proxy.
+type ClientIP struct {
+	TrustedProxies []*net.IPNet
+}
+
+// Resolve returns client address. It returns nil if address can't be determined.
+func (c *ClientIP) Resolve(r *http.Request) net.IP {
+	ip := remoteIP(r)
+	if ip == nil || !containsIP(c.TrustedProxies, ip) {
+		return ip
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, item := range strings.Split(header, ",") {
+			forwarded = append(forwarded, strings.TrimSpace(item))
+		}
+	}
+
+	// walk from nearest hop skipping trusted proxies
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := net.ParseIP(forwarded[i])
+		if hop == nil {
+			// can't trust anything behind malformed entry
+			return ip
+		}
+
+		ip = hop
+		if !containsIP(c.TrustedProxies, hop) {
+			return hop
+		}
+	}
+
+	if len(forwarded) == 0 {
+		if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-Ip"))); realIP != nil {
+			return realIP
+		}
+	}
+
+	return ip
+}
+
+// Middleware replaces request RemoteAddr host with detected client address
+func (c *ClientIP) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := c.Resolve(r); ip != nil {
+			_, port, _ := net.SplitHostPort(r.RemoteAddr)
+			r.RemoteAddr = net.JoinHostPort(ip.String(), port)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Source is a dynamic set of request sources
+type Source interface {
+	ContainsIP(ip net.IP) bool
+	ContainsHost(host string) bool
+}
+
+type ACLParams struct {
+	// Allowed contains networks allowed to make requests. If empty, all networks except denied are allowed.
+	Allowed []*net.IPNet
+
+	// Denied contains networks which are not allowed to make requests
+	Denied []*net.IPNet
+
+	// Forbidden is an optional set of sources which must never call handler (i.e. target goproxy).
+	// Request from such source means misconfiguration.
+	Forbidden Source
+}
+
+// ACL checks request source before passing it to handler
+type ACL struct {
+	ACLParams
+
+	log *zap.Logger
+}
+
+func NewACL(log *zap.Logger, params ACLParams) *ACL {
+	return &ACL{
+		ACLParams: params,
+		log:       log.With(zap.String("component", "network_acl")),
+	}
+}
+
+func (a *ACL) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteIP(r)
+		l := a.log.With(zap.String("from", r.RemoteAddr), zap.String("host", r.Host))
+
+		// misconfiguration protection (when target goproxy configured as Athens calling this endpoint)
+		if a.Forbidden != nil && (ip != nil && a.Forbidden.ContainsIP(ip) || a.Forbidden.ContainsHost(r.Host)) {
+			l.Error("Got request from forbidden source")
+			http.Error(w, "Misconfiguration found, got request from forbidden source (target goproxy)", http.StatusInternalServerError)
+			return
+		}
+
+		if ip == nil || containsIP(a.Denied, ip) || len(a.Allowed) > 0 && !containsIP(a.Allowed, ip) {
+			l.Warn("Request from not allowed network")
+			http.Error(w, "Access from your network is not allowed", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,187 @@
+package netacl_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/netacl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func mustParseCIDRs(t *testing.T, items ...string) []*net.IPNet {
+	t.Helper()
+
+	ret, err := netacl.ParseCIDRs(items)
+	require.NoError(t, err)
+
+	return ret
+}
+
+func TestParseCIDRs(t *testing.T) {
+	t.Parallel()
+	nets, err := netacl.ParseCIDRs([]string{"10.0.0.0/8", "192.168.1.1", "fd00::/8", "::1"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1/32", "fd00::/8", "::1/128"}, func() []string {
+			var ret []string
+			for _, n := range nets {
+				ret = append(ret, n.String())
+			}
+			return ret
+		}())
+	}
+
+	_, err = netacl.ParseCIDRs([]string{"bla"})
+	assert.Error(t, err)
+
+	_, err = netacl.ParseCIDRs([]string{"10.0.0.0/99"})
+	assert.Error(t, err)
+}
+
+func TestClientIP_Resolve(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name       string
+		RemoteAddr string
+		Headers    map[string]string
+		ExpectedIP string
+	}
+
+	clientIP := &netacl.ClientIP{TrustedProxies: mustParseCIDRs(t, "10.0.0.0/8")}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.RemoteAddr
+			for k, v := range tc.Headers {
+				req.Header.Set(k, v)
+			}
+
+			assert.Equal(t, tc.ExpectedIP, clientIP.Resolve(req).String())
+		})
+	}
+
+	f(testCase{
+		Name:       "direct request",
+		RemoteAddr: "192.168.0.1:1234",
+		ExpectedIP: "192.168.0.1",
+	})
+
+	f(testCase{
+		Name:       "headers from untrusted source ignored",
+		RemoteAddr: "192.168.0.1:1234",
+		Headers:    map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Real-Ip": "2.2.2.2"},
+		ExpectedIP: "192.168.0.1",
+	})
+
+	f(testCase{
+		Name:       "forwarded by trusted proxy",
+		RemoteAddr: "10.0.0.1:1234",
+		Headers:    map[string]string{"X-Forwarded-For": "1.1.1.1"},
+		ExpectedIP: "1.1.1.1",
+	})
+
+	f(testCase{
+		Name:       "spoofed entry before trusted chain ignored",
+		RemoteAddr: "10.0.0.1:1234",
+		Headers:    map[string]string{"X-Forwarded-For": "6.6.6.6, 1.1.1.1, 10.0.0.2"},
+		ExpectedIP: "1.1.1.1",
+	})
+
+	f(testCase{
+		Name:       "real ip header from trusted proxy",
+		RemoteAddr: "10.0.0.1:1234",
+		Headers:    map[string]string{"X-Real-Ip": "2.2.2.2"},
+		ExpectedIP: "2.2.2.2",
+	})
+
+	f(testCase{
+		Name:       "malformed forwarded entry",
+		RemoteAddr: "10.0.0.1:1234",
+		Headers:    map[string]string{"X-Forwarded-For": "bla"},
+		ExpectedIP: "10.0.0.1",
+	})
+}
+
+func TestACL_Middleware(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name         string
+		Params       netacl.ACLParams
+		RemoteAddr   string
+		Host         string
+		ExpectedCode int
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tc.RemoteAddr
+			if tc.Host != "" {
+				req.Host = tc.Host
+			}
+			rec := httptest.NewRecorder()
+
+			netacl.NewACL(zaptest.NewLogger(t), tc.Params).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+		})
+	}
+
+	f(testCase{
+		Name:         "no restrictions",
+		RemoteAddr:   "192.168.0.1:1234",
+		ExpectedCode: http.StatusNoContent,
+	})
+
+	f(testCase{
+		Name:         "allowed network",
+		Params:       netacl.ACLParams{Allowed: mustParseCIDRs(t, "192.168.0.0/16")},
+		RemoteAddr:   "192.168.0.1:1234",
+		ExpectedCode: http.StatusNoContent,
+	})
+
+	f(testCase{
+		Name:         "not in allowed network",
+		Params:       netacl.ACLParams{Allowed: mustParseCIDRs(t, "192.168.0.0/16")},
+		RemoteAddr:   "10.0.0.1:1234",
+		ExpectedCode: http.StatusForbidden,
+	})
+
+	f(testCase{
+		Name: "denied network wins",
+		Params: netacl.ACLParams{
+			Allowed: mustParseCIDRs(t, "192.168.0.0/16"),
+			Denied:  mustParseCIDRs(t, "192.168.1.0/24"),
+		},
+		RemoteAddr:   "192.168.1.1:1234",
+		ExpectedCode: http.StatusForbidden,
+	})
+
+	goproxyResolver := netacl.NewHostResolver(zaptest.NewLogger(t), netacl.HostResolverParams{Hosts: []string{"192.168.0.1"}})
+	require.NoError(t, goproxyResolver.Refresh(context.Background()))
+
+	f(testCase{
+		Name:         "request from goproxy",
+		Params:       netacl.ACLParams{Forbidden: goproxyResolver},
+		RemoteAddr:   "192.168.0.1:1234",
+		ExpectedCode: http.StatusInternalServerError,
+	})
+
+	f(testCase{
+		Name: "request to goproxy host",
+		Params: netacl.ACLParams{
+			Forbidden: netacl.NewHostResolver(zaptest.NewLogger(t), netacl.HostResolverParams{Hosts: []string{"goproxy.example.com:8080"}}),
+		},
+		RemoteAddr:   "192.168.0.1:1234",
+		Host:         "goproxy.example.com:8080",
+		ExpectedCode: http.StatusInternalServerError,
+	})
+}
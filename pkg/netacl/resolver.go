@@ -0,0 +1,161 @@
+package netacl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type HostResolverParams struct {
+	// Hosts contains host names (optionally with port) to resolve
+	Hosts []string
+
+	// Interval is a period of re-resolution. Default is 1 minute.
+	Interval time.Duration
+
+	// Resolver is optional custom resolver
+	Resolver *net.Resolver
+}
+
+// HostResolver periodically resolves set of hosts to addresses.
+// It implements Source so requests from resolved addresses or to these hosts can be matched.
+type HostResolver struct {
+	HostResolverParams
+
+	log *zap.Logger
+
+	mu      sync.RWMutex
+	addrs   map[string]struct{}
+	lastErr error
+}
+
+func NewHostResolver(log *zap.Logger, params HostResolverParams) *HostResolver {
+	return &HostResolver{
+		HostResolverParams: params,
+		log:                log.With(zap.String("component", "host_resolver")),
+		addrs:              map[string]struct{}{},
+	}
+}
+
+// Refresh resolves all hosts. Addresses of hosts which failed to resolve are kept from previous run.
+func (h *HostResolver) Refresh(ctx context.Context) error {
+	resolver := h.Resolver
+	if resolver == nil {
+		resolver = &net.Resolver{PreferGo: true}
+	}
+
+	addrs := make(map[string]struct{})
+	var errs []string
+
+	for _, host := range h.Hosts {
+		hostname := hostname(host)
+		if ip := net.ParseIP(hostname); ip != nil {
+			addrs[ip.String()] = struct{}{}
+			continue
+		}
+
+		ips, err := resolver.LookupIPAddr(ctx, hostname)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", hostname, err))
+			continue
+		}
+
+		for _, ip := range ips {
+			addrs[ip.IP.String()] = struct{}{}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(errs) > 0 {
+		// keep previous addresses to not loose protection on temporary dns failure
+		for addr := range h.addrs {
+			addrs[addr] = struct{}{}
+		}
+		h.lastErr = fmt.Errorf("hosts lookup failed: %s", strings.Join(errs, "; "))
+	} else {
+		h.lastErr = nil
+	}
+
+	h.addrs = addrs
+
+	return h.lastErr
+}
+
+// Watch refreshes addresses periodically until context done
+func (h *HostResolver) Watch(ctx context.Context) {
+	interval := h.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := h.Refresh(ctx); err != nil {
+				h.log.Warn("Hosts re-resolution failed", zap.Error(err))
+				continue
+			}
+
+			h.log.Debug("Hosts re-resolved", zap.Strings("addrs", h.Addrs()))
+		}
+	}
+}
+
+// Addrs returns currently known addresses
+func (h *HostResolver) Addrs() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ret := make([]string, 0, len(h.addrs))
+	for addr := range h.addrs {
+		ret = append(ret, addr)
+	}
+
+	return ret
+}
+
+func (h *HostResolver) ContainsIP(ip net.IP) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	_, ok := h.addrs[ip.String()]
+	return ok
+}
+
+func (h *HostResolver) ContainsHost(host string) bool {
+	for _, item := range h.Hosts {
+		if strings.EqualFold(item, host) || strings.EqualFold(hostname(item), host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check returns last resolution error. It allows to register resolver as health observer.
+func (h *HostResolver) Check(context.Context) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.lastErr
+}
+
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+
+	return strings.Trim(host, "[]")
+}
@@ -0,0 +1,86 @@
+package netacl_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/netacl"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHostResolver(t *testing.T) {
+	t.Parallel()
+	failDNS := false
+	resolver := netacl.NewHostResolver(zaptest.NewLogger(t), netacl.HostResolverParams{
+		Hosts: []string{"127.0.0.2", "[::2]:443", "test.example"},
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				if failDNS {
+					return nil, fmt.Errorf("dns is down")
+				}
+
+				return fakeDNS(ctx, network, address)
+			},
+		},
+	})
+
+	assert.NoError(t, resolver.Refresh(context.Background()))
+	assert.ElementsMatch(t, []string{"127.0.0.2", "::2", "192.0.2.1"}, resolver.Addrs())
+	assert.True(t, resolver.ContainsIP(net.ParseIP("192.0.2.1")))
+	assert.True(t, resolver.ContainsHost("test.example"))
+	assert.True(t, resolver.ContainsHost("[::2]:443"))
+	assert.False(t, resolver.ContainsHost("other.example"))
+
+	t.Run("addresses kept on failure", func(t *testing.T) {
+		failDNS = true
+
+		assert.Error(t, resolver.Refresh(context.Background()))
+		assert.Error(t, resolver.Check(context.Background()))
+		assert.True(t, resolver.ContainsIP(net.ParseIP("192.0.2.1")))
+	})
+}
+
+// fakeDNS answers any A query with 192.0.2.1 and returns empty response for other queries
+func fakeDNS(_ context.Context, _, _ string) (net.Conn, error) {
+	client, server := net.Pipe()
+
+	go func() {
+		defer server.Close()
+
+		// stream connection used so messages prefixed with 2-byte length
+		var lenBuf [2]byte
+		if _, err := io.ReadFull(server, lenBuf[:]); err != nil {
+			return
+		}
+
+		query := make([]byte, int(lenBuf[0])<<8|int(lenBuf[1]))
+		if _, err := io.ReadFull(server, query); err != nil {
+			return
+		}
+
+		// skip header (12 bytes) and question name
+		i := 12
+		for i < len(query) && query[i] != 0 {
+			i += int(query[i]) + 1
+		}
+		question := query[12 : i+5]
+		qtype := uint16(query[i+1])<<8 | uint16(query[i+2])
+
+		resp := []byte{query[0], query[1], 0x81, 0x80, 0, 1, 0, 0, 0, 0, 0, 0}
+		resp = append(resp, question...)
+		if qtype == 1 {
+			resp[7] = 1
+			resp = append(resp, 0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 192, 0, 2, 1)
+		}
+
+		_, _ = server.Write(append([]byte{byte(len(resp) >> 8), byte(len(resp))}, resp...))
+	}()
+
+	return client, nil
+}
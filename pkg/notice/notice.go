@@ -0,0 +1,245 @@
+// Package notice contains collection of third-party attribution information (license texts, NOTICE files
+// and copyright lines) from module sources.
+package notice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"go.uber.org/zap"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+// DefaultConcurrency is a default number of simultaneously processed modules
+const DefaultConcurrency = 4
+
+var (
+	licenseFileRe   = regexp.MustCompile(`(?i)^(un)?licen[cs]e([-._][a-z0-9.-]+)?$|^copying([-._][a-z0-9.-]+)?$|^copyright(\.(md|txt))?$`)
+	noticeFileRe    = regexp.MustCompile(`(?i)^notice([-._][a-z0-9.-]+)?$`)
+	copyrightLineRe = regexp.MustCompile(`(?i)^(copyright\b|\(c\)|©)`)
+	yearRe          = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+)
+
+// FilerOpener provides access to module files
+type FilerOpener interface {
+	// OpenFiler returns filer over module root. Filer is closed by caller.
+	OpenFiler(ctx context.Context, m validation.Module) (filer.Filer, error)
+}
+
+// FilerOpenerFunc is an adapter to use ordinary functions as FilerOpener
+type FilerOpenerFunc func(ctx context.Context, m validation.Module) (filer.Filer, error)
+
+func (f FilerOpenerFunc) OpenFiler(ctx context.Context, m validation.Module) (filer.Filer, error) {
+	return f(ctx, m)
+}
+
+// File is a license or notice file found in module root
+type File struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Entry contains attribution information of single module
+type Entry struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+
+	// License is a SPDX id or name of resolved module license, empty if license unknown
+	License string `json:"license,omitempty"`
+
+	Copyrights []string `json:"copyrights,omitempty"`
+	Licenses   []File   `json:"licenses,omitempty"`
+	Notices    []File   `json:"notices,omitempty"`
+
+	// Error is filled if module files can't be read
+	Error string `json:"error,omitempty"`
+}
+
+// Document is an aggregated attribution document, entries are sorted by module name and version
+type Document struct {
+	Entries []Entry `json:"entries"`
+}
+
+type CollectorParams struct {
+	Opener FilerOpener
+
+	// LicenseResolver is an optional resolver to fill license id of entries
+	LicenseResolver validation.LicenseResolver
+
+	// Concurrency limits number of simultaneously processed modules. Default is DefaultConcurrency.
+	Concurrency int
+}
+
+// Collector gathers attribution information for module sets
+type Collector struct {
+	CollectorParams
+
+	log *zap.Logger
+}
+
+func NewCollector(log *zap.Logger, params CollectorParams) *Collector {
+	if params.Concurrency <= 0 {
+		params.Concurrency = DefaultConcurrency
+	}
+
+	return &Collector{
+		CollectorParams: params,
+		log:             log.With(zap.String("component", "notice_collector")),
+	}
+}
+
+// Collect gathers attribution information for all modules.
+// Failures of single modules are reported in entries, error returned only if context is done.
+func (c *Collector) Collect(ctx context.Context, modules []validation.Module) (*Document, error) {
+	entries := make([]Entry, len(modules))
+	jobs := make(chan int)
+	done := make(chan struct{})
+
+	workers := c.Concurrency
+	if workers > len(modules) {
+		workers = len(modules)
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+
+			for idx := range jobs {
+				entries[idx] = c.collect(ctx, modules[idx])
+			}
+		}()
+	}
+
+loop:
+	for i := range modules {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	close(jobs)
+
+	for i := 0; i < workers; i++ {
+		<-done
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Module != entries[j].Module {
+			return entries[i].Module < entries[j].Module
+		}
+
+		return entries[i].Version < entries[j].Version
+	})
+
+	return &Document{Entries: entries}, nil
+}
+
+func (c *Collector) collect(ctx context.Context, m validation.Module) Entry {
+	entry := Entry{Module: m.Name, Version: m.Version.Original()}
+	l := c.log.With(zap.Stringer("module", &m))
+
+	if c.LicenseResolver != nil {
+		lic, err := c.LicenseResolver.ResolveLicense(ctx, m)
+		switch {
+		case errors.Is(err, nil):
+			entry.License = lic.SPDXID
+			if entry.License == "" {
+				entry.License = lic.Name
+			}
+		case errors.Is(err, validation.ErrUnknownLicense):
+			l.Info("Module license unknown")
+		default:
+			l.Warn("Module license resolution failed", zap.Error(err))
+		}
+	}
+
+	fs, err := c.Opener.OpenFiler(ctx, m)
+	if err != nil {
+		l.Warn("Module files open failed", zap.Error(err))
+		entry.Error = fmt.Sprintf("module files open failed: %s", err)
+		return entry
+	}
+
+	defer fs.Close()
+
+	if err := collectFiles(fs, &entry); err != nil {
+		l.Warn("Module files read failed", zap.Error(err))
+		entry.Error = fmt.Sprintf("module files read failed: %s", err)
+	}
+
+	return entry
+}
+
+func collectFiles(fs filer.Filer, entry *Entry) error {
+	files, err := fs.ReadDir("")
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	seen := make(map[string]struct{})
+	for _, file := range files {
+		if file.IsDir || strings.HasSuffix(file.Name, ".go") {
+			continue
+		}
+
+		isLicense, isNotice := licenseFileRe.MatchString(file.Name), noticeFileRe.MatchString(file.Name)
+		if !isLicense && !isNotice {
+			continue
+		}
+
+		content, err := fs.ReadFile(file.Name)
+		if err != nil {
+			return err
+		}
+
+		item := File{Name: file.Name, Content: strings.TrimSpace(string(content))}
+		if isLicense {
+			entry.Licenses = append(entry.Licenses, item)
+		} else {
+			entry.Notices = append(entry.Notices, item)
+		}
+
+		for _, line := range CopyrightLines(content) {
+			if _, ok := seen[line]; ok {
+				continue
+			}
+
+			seen[line] = struct{}{}
+			entry.Copyrights = append(entry.Copyrights, line)
+		}
+	}
+
+	return nil
+}
+
+// CopyrightLines extracts copyright statements from text.
+// Only lines starting with copyright sign and containing year are returned to skip license templates placeholders.
+func CopyrightLines(text []byte) []string {
+	var ret []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(scanner.Text()), "#/*;-"))
+		if copyrightLineRe.MatchString(line) && yearRe.MatchString(line) {
+			ret = append(ret, line)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,228 @@
+package notice_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/goproxy"
+	"github.com/xakep666/licensevalidator/pkg/notice"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gopkg.in/src-d/go-license-detector.v3/licensedb/filer"
+)
+
+const apacheNotice = `Example Project
+Copyright 2015-2020 The Example Authors
+
+This product includes software developed at Example Corp.`
+
+const mitLicense = `MIT License
+
+Copyright (c) 2019 Test Author
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software").`
+
+func moduleZip(t *testing.T, m validation.Module, files map[string]string) *zip.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		w, err := zw.Create(m.Name + "@" + m.Version.Original() + "/" + name)
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	return zr
+}
+
+func TestCollector_Collect(t *testing.T) {
+	t.Parallel()
+
+	withFiles := validation.Module{Name: "github.com/test/project", Version: semver.MustParse("v1.2.0")}
+	missing := validation.Module{Name: "github.com/test/missing", Version: semver.MustParse("v0.1.0")}
+
+	zr := moduleZip(t, withFiles, map[string]string{
+		"LICENSE":                  mitLicense,
+		"NOTICE.txt":               apacheNotice,
+		"license.go":               "package project // Copyright 2020 Somebody",
+		"main.go":                  "package project",
+		"docs/LICENSE-THIRD-PARTY": "Copyright 2018 Third Party",
+	})
+
+	var resolverMock validation.LicenseResolverMock
+	defer resolverMock.AssertExpectations(t)
+
+	resolverMock.On("ResolveLicense", mock.Anything, withFiles).Return(validation.License{Name: "MIT License", SPDXID: "MIT"}, nil).Once()
+	resolverMock.On("ResolveLicense", mock.Anything, missing).Return(validation.License{}, validation.ErrUnknownLicense).Once()
+
+	doc, err := notice.NewCollector(zaptest.NewLogger(t), notice.CollectorParams{
+		Opener: notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+			if m.Name == missing.Name {
+				return nil, goproxy.ErrModuleNotFound
+			}
+
+			return &goproxy.ZipFiler{Reader: zr, Module: m}, nil
+		}),
+		LicenseResolver: &resolverMock,
+	}).Collect(context.Background(), []validation.Module{withFiles, missing})
+	require.NoError(t, err)
+
+	assert.Equal(t, &notice.Document{
+		Entries: []notice.Entry{
+			{
+				Module:  "github.com/test/missing",
+				Version: "v0.1.0",
+				Error:   "module files open failed: module not found",
+			},
+			{
+				Module:     "github.com/test/project",
+				Version:    "v1.2.0",
+				License:    "MIT",
+				Copyrights: []string{"Copyright (c) 2019 Test Author", "Copyright 2015-2020 The Example Authors"},
+				Licenses:   []notice.File{{Name: "LICENSE", Content: mitLicense}},
+				Notices:    []notice.File{{Name: "NOTICE.txt", Content: apacheNotice}},
+			},
+		},
+	}, doc)
+}
+
+func TestCollector_Collect_ContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := notice.NewCollector(zaptest.NewLogger(t), notice.CollectorParams{
+		Opener: notice.FilerOpenerFunc(func(ctx context.Context, m validation.Module) (filer.Filer, error) {
+			return nil, ctx.Err()
+		}),
+	}).Collect(ctx, []validation.Module{{Name: "github.com/test/project", Version: semver.MustParse("v1.2.0")}})
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestCopyrightLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{
+		"Copyright (c) 2009 The Go Authors. All rights reserved.",
+		"Copyright 2017 Test Author",
+		"(c) 2018 Other Author",
+	}, notice.CopyrightLines([]byte(`Copyright (c) 2009 The Go Authors. All rights reserved.
+   Copyright [yyyy] [name of copyright owner]
+// Copyright 2017 Test Author
+copyright notice, this list of conditions and the following disclaimer.
+(c) 2018 Other Author
+Copyright (c) <year> <copyright holders>`)))
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	doc := &notice.Document{
+		Entries: []notice.Entry{
+			{
+				Module:     "github.com/test/project",
+				Version:    "v1.2.0",
+				License:    "Apache-2.0",
+				Copyrights: []string{"Copyright 2015-2020 The Example Authors"},
+				Licenses:   []notice.File{{Name: "LICENSE", Content: "Apache License text"}},
+				Notices:    []notice.File{{Name: "NOTICE", Content: "Example <Project>"}},
+			},
+			{
+				Module:  "github.com/test/missing",
+				Version: "v0.1.0",
+				Error:   "module not found",
+			},
+		},
+	}
+
+	type testCase struct {
+		Format   notice.Format
+		Expected string
+	}
+
+	f := func(tc testCase) {
+		t.Run(string(tc.Format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, notice.Write(&buf, doc, tc.Format))
+			assert.Equal(t, tc.Expected, buf.String())
+		})
+	}
+
+	f(testCase{
+		Format: notice.FormatMarkdown,
+		Expected: "# Third-party notices\n\n" +
+			"This product includes the following third-party modules.\n\n" +
+			"## github.com/test/project@v1.2.0\n\n" +
+			"License: Apache-2.0\n\n" +
+			"Copyright 2015-2020 The Example Authors\n\n" +
+			"### LICENSE\n\n```text\nApache License text\n```\n\n" +
+			"### NOTICE\n\n```text\nExample <Project>\n```\n\n" +
+			"## github.com/test/missing@v0.1.0\n\n" +
+			"Attribution information is not available: module not found\n",
+	})
+
+	f(testCase{
+		Format: notice.FormatText,
+		Expected: "THIRD-PARTY NOTICES\n\n" +
+			"This product includes the following third-party modules.\n\n" +
+			"================================================================================\n" +
+			"github.com/test/project@v1.2.0\n" +
+			"License: Apache-2.0\n" +
+			"Copyright 2015-2020 The Example Authors\n\n" +
+			"----- LICENSE -----\nApache License text\n\n" +
+			"----- NOTICE -----\nExample <Project>\n\n" +
+			"================================================================================\n" +
+			"github.com/test/missing@v0.1.0\n" +
+			"Attribution information is not available: module not found\n",
+	})
+
+	f(testCase{
+		Format: notice.FormatHTML,
+		Expected: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Third-party notices</title>
+</head>
+<body>
+<h1>Third-party notices</h1>
+<p>This product includes the following third-party modules.</p>
+
+<section id="github.com/test/project@v1.2.0">
+<h2>github.com/test/project@v1.2.0</h2>
+<p>License: Apache-2.0</p>
+<ul>
+<li>Copyright 2015-2020 The Example Authors</li>
+</ul>
+<h3>LICENSE</h3>
+<pre>Apache License text</pre>
+<h3>NOTICE</h3>
+<pre>Example &lt;Project&gt;</pre>
+</section>
+
+<section id="github.com/test/missing@v0.1.0">
+<h2>github.com/test/missing@v0.1.0</h2>
+<p>Attribution information is not available: module not found</p>
+</section>
+</body>
+</html>
+`,
+	})
+}
@@ -0,0 +1,105 @@
+package notice
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"text/template"
+)
+
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatText     Format = "text"
+)
+
+// Formats contains all supported document formats
+var Formats = []Format{FormatMarkdown, FormatHTML, FormatText}
+
+var funcs = template.FuncMap{
+	"ref": func(e Entry) string { return e.Module + "@" + e.Version },
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(funcs).Parse(`# Third-party notices
+
+This product includes the following third-party modules.
+{{ range .Entries }}
+## {{ ref . }}
+{{ if .License }}
+License: {{ .License }}
+{{ end }}{{ if .Error }}
+Attribution information is not available: {{ .Error }}
+{{ end }}{{ range .Copyrights }}
+{{ . }}
+{{ end }}{{ range .Licenses }}
+### {{ .Name }}
+
+` + "```" + `text
+{{ .Content }}
+` + "```" + `
+{{ end }}{{ range .Notices }}
+### {{ .Name }}
+
+` + "```" + `text
+{{ .Content }}
+` + "```" + `
+{{ end }}{{ end }}`))
+
+var textTemplate = template.Must(template.New("text").Funcs(funcs).Parse(`THIRD-PARTY NOTICES
+
+This product includes the following third-party modules.
+{{ range .Entries }}
+================================================================================
+{{ ref . }}
+{{ if .License }}License: {{ .License }}
+{{ end }}{{ if .Error }}Attribution information is not available: {{ .Error }}
+{{ end }}{{ range .Copyrights }}{{ . }}
+{{ end }}{{ range .Licenses }}
+----- {{ .Name }} -----
+{{ .Content }}
+{{ end }}{{ range .Notices }}
+----- {{ .Name }} -----
+{{ .Content }}
+{{ end }}{{ end }}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap(funcs)).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Third-party notices</title>
+</head>
+<body>
+<h1>Third-party notices</h1>
+<p>This product includes the following third-party modules.</p>
+{{ range .Entries }}
+<section id="{{ ref . }}">
+<h2>{{ ref . }}</h2>
+{{ if .License }}<p>License: {{ .License }}</p>
+{{ end }}{{ if .Error }}<p>Attribution information is not available: {{ .Error }}</p>
+{{ end }}{{ if .Copyrights }}<ul>
+{{ range .Copyrights }}<li>{{ . }}</li>
+{{ end }}</ul>
+{{ end }}{{ range .Licenses }}<h3>{{ .Name }}</h3>
+<pre>{{ .Content }}</pre>
+{{ end }}{{ range .Notices }}<h3>{{ .Name }}</h3>
+<pre>{{ .Content }}</pre>
+{{ end }}</section>
+{{ end }}</body>
+</html>
+`))
+
+// Write writes attribution document in given format
+func Write(w io.Writer, doc *Document, format Format) error {
+	switch format {
+	case FormatMarkdown:
+		return markdownTemplate.Execute(w, doc)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, doc)
+	case FormatText:
+		return textTemplate.Execute(w, doc)
+	default:
+		return fmt.Errorf("unknown notice format %s", format)
+	}
+}
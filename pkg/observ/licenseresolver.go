@@ -34,12 +34,28 @@ func (l *LicenseResolver) ResolveLicense(ctx context.Context, m validation.Modul
 	l.initMetrics()
 
 	lic, err := l.LicenseResolver.ResolveLicense(ctx, m)
+	l.record(ctx, &lic, err)
+
+	return lic, err
+}
+
+// ResolveLicenses resolves licenses with wrapped resolver at once if it supports it
+func (l *LicenseResolver) ResolveLicenses(ctx context.Context, modules []validation.Module) []validation.LicenseResult {
+	l.initMetrics()
+
+	results := validation.ResolveLicenses(ctx, l.LicenseResolver, modules)
+	for i := range results {
+		l.record(ctx, &results[i].License, results[i].Err)
+	}
+
+	return results
+}
+
+func (l *LicenseResolver) record(ctx context.Context, lic *validation.License, err error) {
 	switch {
 	case errors.Is(err, nil):
 		l.licenseMetric.Add(ctx, 1, key.String("name", lic.Name), key.String("id", lic.SPDXID))
 	case errors.Is(err, validation.ErrUnknownLicense):
 		l.licenseMetric.Add(ctx, 1, key.String("name", "unknown"), key.String("id", "unknown"))
 	}
-
-	return lic, err
 }
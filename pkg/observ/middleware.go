@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/xakep666/licensevalidator/pkg/netacl"
+
 	"go.opentelemetry.io/otel/api/metric"
 	"go.opentelemetry.io/otel/plugin/othttp"
 	"go.uber.org/zap"
@@ -55,8 +57,6 @@ func Middleware(log *zap.Logger, meter metric.Meter) func(next http.Handler) htt
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			_, port, _ := net.SplitHostPort(r.RemoteAddr)
-			r.RemoteAddr = net.JoinHostPort(ReadUserIP(r), port)
 			readCounter := ReadCounter{ReadCloser: r.Body}
 			writerInterceptor := WriterInterceptor{ResponseWriter: w}
 
@@ -87,13 +87,15 @@ func Middleware(log *zap.Logger, meter metric.Meter) func(next http.Handler) htt
 	}
 }
 
+// ReadUserIP returns request source address.
+// X-Forwarded-For and X-Real-Ip headers are not taken into account because they can be forged by any client.
+//
+// Deprecated: use netacl.ClientIP which trusts forwarding headers only from configured proxies.
 func ReadUserIP(r *http.Request) string {
-	IPAddress := r.Header.Get("X-Real-Ip")
-	if IPAddress == "" {
-		IPAddress = r.Header.Get("X-Forwarded-For")
-	}
-	if IPAddress == "" {
-		IPAddress, _, _ = net.SplitHostPort(r.RemoteAddr)
+	if ip := (&netacl.ClientIP{}).Resolve(r); ip != nil {
+		return ip.String()
 	}
-	return IPAddress
+
+	host, _, _ := net.SplitHostPort(r.RemoteAddr)
+	return host
 }
@@ -0,0 +1,21 @@
+package observ_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xakep666/licensevalidator/pkg/observ"
+)
+
+func TestReadUserIP(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.1:12345"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.Header.Set("X-Real-Ip", "198.51.100.2")
+
+	assert.Equal(t, "192.0.2.1", observ.ReadUserIP(r), "forwarding headers must not be trusted")
+}
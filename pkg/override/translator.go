@@ -24,6 +24,9 @@ func NewTranslator(log *zap.Logger, overrides []TranslateOverride) *Translator {
 	return &Translator{overrides: overrides, log: log.With(zap.String("component", "override_translator"))}
 }
 
+// Name returns component name of path overrides in explanation steps
+func (Translator) Name() string { return "override_translator" }
+
 func (t Translator) Translate(ctx context.Context, m validation.Module) (validation.Module, error) {
 	for _, override := range t.overrides {
 		if override.Match.MatchString(m.Name) {
@@ -0,0 +1,522 @@
+// Package proxy contains goproxy protocol server which filters modules by validator.
+// It allows to use this app as GOPROXY in front of upstream proxy without Athens.
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/pkg/goproxy"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"go.uber.org/zap"
+	"golang.org/x/mod/module"
+)
+
+type requestKind int
+
+const (
+	requestList requestKind = iota
+	requestInfo
+	requestMod
+	requestZip
+	requestLatest
+)
+
+// request is a parsed goproxy protocol request
+type request struct {
+	kind    requestKind
+	module  string // unescaped module path
+	version string // unescaped version, empty for list and latest requests
+}
+
+// ErrInvalidPath returned if request path is not a part of goproxy protocol
+var ErrInvalidPath = fmt.Errorf("invalid goproxy path")
+
+func parseRequest(p string) (request, error) {
+	p = strings.TrimPrefix(p, "/")
+
+	if escaped := strings.TrimSuffix(p, "/@latest"); escaped != p {
+		mod, err := module.UnescapePath(escaped)
+		if err != nil {
+			return request{}, fmt.Errorf("%w: %s", ErrInvalidPath, err)
+		}
+
+		return request{kind: requestLatest, module: mod}, nil
+	}
+
+	i := strings.LastIndex(p, "/@v/")
+	if i < 0 {
+		return request{}, ErrInvalidPath
+	}
+
+	mod, err := module.UnescapePath(p[:i])
+	if err != nil {
+		return request{}, fmt.Errorf("%w: %s", ErrInvalidPath, err)
+	}
+
+	file := p[i+len("/@v/"):]
+	if file == "list" {
+		return request{kind: requestList, module: mod}, nil
+	}
+
+	var kind requestKind
+	ext := path.Ext(file)
+	switch ext {
+	case ".info":
+		kind = requestInfo
+	case ".mod":
+		kind = requestMod
+	case ".zip":
+		kind = requestZip
+	default:
+		return request{}, ErrInvalidPath
+	}
+
+	version, err := module.UnescapeVersion(strings.TrimSuffix(file, ext))
+	if err != nil {
+		return request{}, fmt.Errorf("%w: %s", ErrInvalidPath, err)
+	}
+
+	return request{kind: kind, module: mod, version: version}, nil
+}
+
+type HandlerParams struct {
+	Client *http.Client
+
+	// UpstreamURL is an upstream goproxy base url (i.e. https://proxy.golang.org)
+	// or list of upstreams with GOPROXY environment variable syntax (see goproxy.ParseProxyList)
+	UpstreamURL string
+
+	Validator validation.Validator
+
+	// HelpURL is an optional link added to denial description
+	HelpURL string
+
+	// Messages contains optional custom denial messages
+	Messages validation.DenialMessages
+
+	// DenyStatus is a response status code for denied modules. Default is 403 (Forbidden).
+	// 410 (Gone) may be used to let go command fall through to next proxy in GOPROXY list.
+	DenyStatus int
+
+	// FilterConcurrency limits number of simultaneous validations during version list filtering.
+	// Default is validation.DefaultFilterConcurrency.
+	FilterConcurrency int
+}
+
+// Handler serves goproxy protocol streaming responses from upstream.
+// Modules are validated before serving .mod and .zip files.
+// Denied versions are filtered out from version lists and @latest resolves to the newest allowed version.
+type Handler struct {
+	HandlerParams
+
+	log       *zap.Logger
+	client    *http.Client
+	upstreams []goproxy.Proxy
+}
+
+func NewHandler(log *zap.Logger, params HandlerParams) (*Handler, error) {
+	upstreams, err := goproxy.ParseProxyList(params.UpstreamURL)
+	if err != nil {
+		return nil, fmt.Errorf("upstream list parse failed: %w", err)
+	}
+
+	client := http.DefaultClient
+	if params.Client != nil {
+		client = params.Client
+	}
+
+	if params.DenyStatus == 0 {
+		params.DenyStatus = http.StatusForbidden
+	}
+
+	h := &Handler{
+		HandlerParams: params,
+		log:           log.With(zap.String("component", "goproxy_handler")),
+		client:        client,
+		upstreams:     upstreams,
+	}
+
+	if goproxy.HasDirect(params.UpstreamURL) {
+		h.log.Warn("\"direct\" entries of upstream list are ignored, modules are fetched only through upstream proxies", zap.String("list", params.UpstreamURL))
+	}
+
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// checksum database proxying
+	if strings.HasPrefix(r.URL.Path, "/sumdb/") {
+		h.forward(w, r)
+		return
+	}
+
+	req, err := parseRequest(r.URL.EscapedPath())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch req.kind {
+	case requestList:
+		h.serveList(w, r, req)
+	case requestLatest:
+		h.serveLatest(w, r, req)
+	case requestMod, requestZip:
+		if h.validate(w, r, req) {
+			h.forward(w, r)
+		}
+	default:
+		h.forward(w, r)
+	}
+}
+
+// validate checks module and writes denial if module forbidden. It returns true if request may be served.
+func (h *Handler) validate(w http.ResponseWriter, r *http.Request, req request) bool {
+	version, err := semver.NewVersion(req.version)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid version %s: %s", req.version, err), http.StatusNotFound)
+		return false
+	}
+
+	m := validation.Module{Name: req.module, Version: version}
+
+	denial, err := h.check(r.Context(), m)
+	switch {
+	case err != nil:
+		http.Error(w, fmt.Sprintf("module validation failed: %s", err), http.StatusInternalServerError)
+		return false
+	case denial != nil:
+		validation.WriteDenial(w, r, h.DenyStatus, *denial)
+		return false
+	default:
+		return true
+	}
+}
+
+// check validates module. It returns non-nil denial if module forbidden.
+func (h *Handler) check(ctx context.Context, m validation.Module) (*validation.Denial, error) {
+	l := h.log.With(zap.Stringer("module", &m))
+
+	err := h.Validator.Validate(ctx, m)
+	if errors.Is(err, nil) {
+		return nil, nil
+	}
+
+	denial, ok := validation.DenialFromError(m, err)
+	if !ok {
+		l.Error("Module validation failed", zap.Error(err))
+		return nil, err
+	}
+
+	denial.HelpURL = h.HelpURL
+	if err := h.Messages.Render(&denial); err != nil {
+		l.Error("Denial message render failed", zap.Error(err))
+	}
+
+	l.Info("Module denied", zap.String("reason", string(denial.Reason)), zap.String("rule", denial.Rule))
+
+	return &denial, nil
+}
+
+// serveList serves upstream version list without denied versions
+func (h *Handler) serveList(w http.ResponseWriter, r *http.Request, req request) {
+	resp, ok := h.fetch(w, r, http.MethodGet, r.URL.EscapedPath())
+	if !ok {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		copyResponse(w, resp)
+		return
+	}
+
+	versions, err := readVersionList(resp.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("upstream version list read failed: %s", err), http.StatusBadGateway)
+		return
+	}
+
+	allowed, err := h.filter(r.Context(), req.module, versions)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("versions validation failed: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	for _, version := range allowed {
+		_, _ = fmt.Fprintln(w, version.Original())
+	}
+}
+
+// serveLatest serves upstream latest version info if it's allowed.
+// Otherwise info of newest allowed version from list is served.
+func (h *Handler) serveLatest(w http.ResponseWriter, r *http.Request, req request) {
+	resp, ok := h.fetch(w, r, http.MethodGet, r.URL.EscapedPath())
+	if !ok {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		copyResponse(w, resp)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("upstream response read failed: %s", err), http.StatusBadGateway)
+		return
+	}
+
+	var info struct {
+		Version string
+	}
+
+	if err := json.Unmarshal(body, &info); err != nil {
+		http.Error(w, fmt.Sprintf("upstream response decode failed: %s", err), http.StatusBadGateway)
+		return
+	}
+
+	latest, err := semver.NewVersion(info.Version)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("upstream returned invalid version %s: %s", info.Version, err), http.StatusBadGateway)
+		return
+	}
+
+	denial, err := h.check(r.Context(), validation.Module{Name: req.module, Version: latest})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("module validation failed: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	if denial == nil {
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		copyResponse(w, resp)
+		return
+	}
+
+	escapedModule := strings.TrimSuffix(r.URL.EscapedPath(), "/@latest")
+
+	replacement, err := h.newestAllowed(r, escapedModule, req.module)
+	switch {
+	case err != nil:
+		http.Error(w, fmt.Sprintf("allowed version lookup failed: %s", err), http.StatusBadGateway)
+	case replacement == nil:
+		validation.WriteDenial(w, r, h.DenyStatus, *denial)
+	default:
+		escapedVersion, err := module.EscapeVersion(replacement.Original())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("version escape failed: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		h.log.Debug("Latest version replaced",
+			zap.String("module", req.module),
+			zap.String("latest", info.Version),
+			zap.String("replacement", replacement.Original()),
+		)
+
+		infoResp, ok := h.fetch(w, r, http.MethodGet, escapedModule+"/@v/"+escapedVersion+".info")
+		if !ok {
+			return
+		}
+
+		defer infoResp.Body.Close()
+
+		copyResponse(w, infoResp)
+	}
+}
+
+// newestAllowed returns newest allowed version of module from upstream list or nil if there is no such version
+func (h *Handler) newestAllowed(r *http.Request, escapedModule, name string) (*semver.Version, error) {
+	resp, err := h.do(r.Context(), http.MethodGet, escapedModule+"/@v/list")
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected upstream list status %d", resp.StatusCode)
+	}
+
+	versions, err := readVersionList(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := h.filter(r.Context(), name, versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return newest(allowed), nil
+}
+
+func (h *Handler) filter(ctx context.Context, name string, versions []*semver.Version) ([]*semver.Version, error) {
+	allowed, err := validation.FilterVersions(ctx, h.Validator, name, versions, h.FilterConcurrency)
+	if err != nil {
+		h.log.Error("Versions validation failed", zap.String("module", name), zap.Error(err))
+		return nil, err
+	}
+
+	return allowed, nil
+}
+
+// readVersionList parses version list in goproxy format. Invalid versions are skipped.
+func readVersionList(r io.Reader) ([]*semver.Version, error) {
+	var versions []*semver.Version
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		version, err := semver.NewVersion(line)
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, scanner.Err()
+}
+
+// newest returns newest release version like go command does.
+// Pre-release version returned only if there is no releases.
+func newest(versions []*semver.Version) *semver.Version {
+	var release, prerelease *semver.Version
+
+	for _, version := range versions {
+		if version.Prerelease() == "" {
+			if release == nil || version.GreaterThan(release) {
+				release = version
+			}
+		} else if prerelease == nil || version.GreaterThan(prerelease) {
+			prerelease = version
+		}
+	}
+
+	if release != nil {
+		return release
+	}
+
+	return prerelease
+}
+
+// forward streams upstream response to client
+func (h *Handler) forward(w http.ResponseWriter, r *http.Request) {
+	resp, ok := h.fetch(w, r, r.Method, r.URL.EscapedPath())
+	if !ok {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	copyResponse(w, resp)
+}
+
+// fetch makes upstream request. It writes error to client and returns false if request failed.
+func (h *Handler) fetch(w http.ResponseWriter, r *http.Request, method, escapedPath string) (*http.Response, bool) {
+	resp, err := h.do(r.Context(), method, escapedPath)
+	switch {
+	case errors.Is(err, nil):
+		return resp, true
+	case errors.Is(err, goproxy.ErrProxyOff):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	default:
+		h.log.Error("Upstream request failed", zap.Error(err), zap.String("path", escapedPath))
+		http.Error(w, fmt.Sprintf("upstream request failed: %s", err), http.StatusBadGateway)
+		return nil, false
+	}
+}
+
+// errUpstreamStatus returned to try next upstream if response status is not successful
+var errUpstreamStatus = fmt.Errorf("upstream returned unsuccessful status")
+
+// do makes request to upstreams in order according to upstream list syntax.
+// Unsuccessful response of last tried upstream is returned as is.
+func (h *Handler) do(ctx context.Context, method, escapedPath string) (*http.Response, error) {
+	var resp *http.Response
+
+	err := goproxy.Walk(h.upstreams, func(baseURL string) error {
+		if resp != nil {
+			resp.Body.Close()
+			resp = nil
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, baseURL+escapedPath, nil)
+		if err != nil {
+			return fmt.Errorf("upstream request construct failed: %w", err)
+		}
+
+		resp, err = h.client.Do(req)
+		switch {
+		case err != nil:
+			return err
+		case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+			return goproxy.ErrModuleNotFound
+		case resp.StatusCode >= http.StatusBadRequest:
+			return fmt.Errorf("%w: %d", errUpstreamStatus, resp.StatusCode)
+		default:
+			return nil
+		}
+	})
+	switch {
+	case errors.Is(err, nil):
+		return resp, nil
+	case resp != nil && !errors.Is(err, goproxy.ErrProxyOff):
+		return resp, nil
+	default:
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		return nil, err
+	}
+}
+
+// forwardedHeaders contains upstream response headers passed to client
+var forwardedHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"Cache-Control",
+	"ETag",
+	"Last-Modified",
+	"Expires",
+}
+
+func copyResponse(w http.ResponseWriter, resp *http.Response) {
+	for _, header := range forwardedHeaders {
+		if value := resp.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
@@ -0,0 +1,290 @@
+package proxy_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/proxy"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/github.com/!azure/test/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "v1.0.0\nv1.1.0\n")
+	})
+	mux.HandleFunc("/github.com/!azure/test/@v/v1.0.0.info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"Version":"v1.0.0","Time":"2020-01-01T00:00:00Z"}`)
+	})
+	mux.HandleFunc("/github.com/!azure/test/@latest", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"Version":"v1.1.0","Time":"2020-02-01T00:00:00Z"}`)
+	})
+	mux.HandleFunc("/github.com/!azure/test/@v/v1.0.0.mod", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "module github.com/Azure/test\n")
+	})
+	mux.HandleFunc("/github.com/!azure/test/@v/v1.0.0.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		_, _ = fmt.Fprint(w, "zip content")
+	})
+	mux.HandleFunc("/sumdb/sum.golang.org/supported", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	upstream := newUpstream(t)
+
+	internal := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(internal.Close)
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	t.Cleanup(broken.Close)
+
+	module := validation.Module{Name: "github.com/Azure/test", Version: semver.MustParse("v1.0.0")}
+	latestModule := validation.Module{Name: "github.com/Azure/test", Version: semver.MustParse("v1.1.0")}
+
+	type testCase struct {
+		Name               string
+		Path               string
+		Upstreams          string
+		DenyStatus         int
+		ValidatorMockSetup func(m *validation.ValidatorMock)
+		ExpectedCode       int
+		ExpectedBody       string
+		ExpectedHeaders    map[string]string
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			var validatorMock validation.ValidatorMock
+			if tc.ValidatorMockSetup != nil {
+				tc.ValidatorMockSetup(&validatorMock)
+			}
+
+			defer validatorMock.AssertExpectations(t)
+
+			rec := httptest.NewRecorder()
+
+			upstreams := tc.Upstreams
+			if upstreams == "" {
+				upstreams = upstream.URL
+			}
+
+			handler, err := proxy.NewHandler(zaptest.NewLogger(t), proxy.HandlerParams{
+				Client:      upstream.Client(),
+				UpstreamURL: upstreams,
+				Validator:   &validatorMock,
+				HelpURL:     "https://example.com/policy",
+				DenyStatus:  tc.DenyStatus,
+			})
+			require.NoError(t, err)
+
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.Path, nil))
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+			if tc.ExpectedBody != "" {
+				assert.Equal(t, tc.ExpectedBody, strings.TrimRight(rec.Body.String(), "\n"))
+			}
+
+			for k, v := range tc.ExpectedHeaders {
+				assert.Equal(t, v, rec.Header().Get(k))
+			}
+		})
+	}
+
+	f(testCase{
+		Name: "list",
+		Path: "/github.com/!azure/test/@v/list",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(nil).Once()
+			m.On("Validate", mock.Anything, latestModule).Return(nil).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: "v1.0.0\nv1.1.0",
+	})
+
+	f(testCase{
+		Name: "list without denied versions",
+		Path: "/github.com/!azure/test/@v/list",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(nil).Once()
+			m.On("Validate", mock.Anything, latestModule).Return(validation.ErrUnknownLicense).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: "v1.0.0",
+	})
+
+	f(testCase{
+		Name: "list validation error",
+		Path: "/github.com/!azure/test/@v/list",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, mock.Anything).Return(fmt.Errorf("test error"))
+		},
+		ExpectedCode: http.StatusInternalServerError,
+	})
+
+	f(testCase{
+		Name: "allowed latest",
+		Path: "/github.com/!azure/test/@latest",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, latestModule).Return(nil).Once()
+		},
+		ExpectedCode:    http.StatusOK,
+		ExpectedBody:    `{"Version":"v1.1.0","Time":"2020-02-01T00:00:00Z"}`,
+		ExpectedHeaders: map[string]string{"Content-Type": "application/json"},
+	})
+
+	f(testCase{
+		Name: "denied latest replaced by newest allowed",
+		Path: "/github.com/!azure/test/@latest",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, latestModule).Return(validation.ErrUnknownLicense).Twice()
+			m.On("Validate", mock.Anything, module).Return(nil).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{"Version":"v1.0.0","Time":"2020-01-01T00:00:00Z"}`,
+	})
+
+	f(testCase{
+		Name: "no allowed versions for latest",
+		Path: "/github.com/!azure/test/@latest",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, mock.Anything).Return(validation.ErrUnknownLicense)
+		},
+		ExpectedCode: http.StatusForbidden,
+		ExpectedBody: "license of github.com/Azure/test@v1.1.0 can't be determined\nSee https://example.com/policy for details",
+	})
+
+	f(testCase{
+		Name:            "info",
+		Path:            "/github.com/!azure/test/@v/v1.0.0.info",
+		ExpectedCode:    http.StatusOK,
+		ExpectedBody:    `{"Version":"v1.0.0","Time":"2020-01-01T00:00:00Z"}`,
+		ExpectedHeaders: map[string]string{"Content-Type": "application/json"},
+	})
+
+	f(testCase{
+		Name: "allowed mod",
+		Path: "/github.com/!azure/test/@v/v1.0.0.mod",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(nil).Once()
+		},
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: "module github.com/Azure/test",
+	})
+
+	f(testCase{
+		Name: "allowed zip",
+		Path: "/github.com/!azure/test/@v/v1.0.0.zip",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(nil).Once()
+		},
+		ExpectedCode:    http.StatusOK,
+		ExpectedBody:    "zip content",
+		ExpectedHeaders: map[string]string{"Content-Type": "application/zip"},
+	})
+
+	f(testCase{
+		Name: "denied zip",
+		Path: "/github.com/!azure/test/@v/v1.0.0.zip",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(validation.ErrUnknownLicense).Once()
+		},
+		ExpectedCode: http.StatusForbidden,
+		ExpectedBody: "license of github.com/Azure/test@v1.0.0 can't be determined\nSee https://example.com/policy for details",
+	})
+
+	f(testCase{
+		Name:       "denied mod with custom status",
+		Path:       "/github.com/!azure/test/@v/v1.0.0.mod",
+		DenyStatus: http.StatusGone,
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(validation.ErrUnknownLicense).Once()
+		},
+		ExpectedCode: http.StatusGone,
+	})
+
+	f(testCase{
+		Name: "validation error",
+		Path: "/github.com/!azure/test/@v/v1.0.0.zip",
+		ValidatorMockSetup: func(m *validation.ValidatorMock) {
+			m.On("Validate", mock.Anything, module).Return(fmt.Errorf("test error")).Once()
+		},
+		ExpectedCode: http.StatusInternalServerError,
+	})
+
+	f(testCase{
+		Name:         "unknown file extension",
+		Path:         "/github.com/!azure/test/@v/v1.0.0.info.bla",
+		ExpectedCode: http.StatusNotFound,
+	})
+
+	f(testCase{
+		Name:         "upstream missing version",
+		Path:         "/github.com/!azure/test/@v/v2.0.0.info",
+		ExpectedCode: http.StatusNotFound,
+	})
+
+	f(testCase{
+		Name:         "invalid path",
+		Path:         "/github.com/!azure/test",
+		ExpectedCode: http.StatusNotFound,
+	})
+
+	f(testCase{
+		Name:         "sumdb",
+		Path:         "/sumdb/sum.golang.org/supported",
+		ExpectedCode: http.StatusOK,
+	})
+
+	f(testCase{
+		Name:         "upstream list falls through on not found",
+		Path:         "/github.com/!azure/test/@v/v1.0.0.info",
+		Upstreams:    internal.URL + "," + upstream.URL,
+		ExpectedCode: http.StatusOK,
+		ExpectedBody: `{"Version":"v1.0.0","Time":"2020-01-01T00:00:00Z"}`,
+	})
+
+	f(testCase{
+		Name:         "upstream list error after comma",
+		Path:         "/github.com/!azure/test/@v/v1.0.0.info",
+		Upstreams:    broken.URL + "," + upstream.URL,
+		ExpectedCode: http.StatusInternalServerError,
+	})
+
+	f(testCase{
+		Name:         "upstream list error after pipe",
+		Path:         "/github.com/!azure/test/@v/v1.0.0.info",
+		Upstreams:    broken.URL + "|" + upstream.URL,
+		ExpectedCode: http.StatusOK,
+	})
+
+	f(testCase{
+		Name:         "upstream list off",
+		Path:         "/github.com/!azure/test/@v/v1.0.0.info",
+		Upstreams:    internal.URL + ",off," + upstream.URL,
+		ExpectedCode: http.StatusNotFound,
+		ExpectedBody: "module lookup disabled by GOPROXY=off",
+	})
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+)
+
+// WriteExplanation writes validation explanation as a human-readable step list or as JSON
+func WriteExplanation(w io.Writer, e *validation.Explanation, format Format) error {
+	switch format {
+	case FormatTable:
+		return WriteExplanationTable(w, e)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(e)
+	default:
+		return fmt.Errorf("unsupported explanation format %s", format)
+	}
+}
+
+// WriteExplanationTable writes numbered validation steps followed by outcome
+func WriteExplanationTable(w io.Writer, e *validation.Explanation) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "#\tSTAGE\tCOMPONENT\tDETAILS")
+	for i, step := range e.Steps {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i+1, step.Stage, step.Component, stepDetails(&step))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%s@%s: %s\n", e.Module, e.Version, outcomeDetails(e))
+	return err
+}
+
+func stepDetails(step *validation.Step) string {
+	ret := step.Message
+	if step.License != "" {
+		ret += ", license " + step.License
+	}
+
+	if step.Error != "" {
+		ret += ": " + step.Error
+	}
+
+	return ret
+}
+
+func outcomeDetails(e *validation.Explanation) string {
+	switch {
+	case e.Denial != nil:
+		ret := fmt.Sprintf("%s, %s (%s)", e.Outcome, e.Denial.Message, e.Denial.Rule)
+		if note := e.Denial.HelpNote(); note != "" {
+			ret += "\n" + note
+		}
+
+		return ret
+	case e.Error != "":
+		return fmt.Sprintf("%s, %s", e.Outcome, e.Error)
+	default:
+		return string(e.Outcome)
+	}
+}
@@ -0,0 +1,176 @@
+// Package report contains writers of batch validation reports in formats suitable for humans and CI systems
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+)
+
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatJUnit Format = "junit"
+	FormatSARIF Format = "sarif"
+)
+
+// Formats contains all supported output formats
+var Formats = []Format{FormatTable, FormatJSON, FormatJUnit, FormatSARIF}
+
+// Write writes report in given format.
+// Source is a name of validated file (i.e. go.mod), it's used to point result locations.
+func Write(w io.Writer, r *batch.Report, format Format, source string) error {
+	switch format {
+	case FormatTable:
+		return WriteTable(w, r)
+	case FormatJSON:
+		return WriteJSON(w, r)
+	case FormatJUnit:
+		return WriteJUnit(w, r, source)
+	case FormatSARIF:
+		return WriteSARIF(w, r, source)
+	default:
+		return fmt.Errorf("unknown report format %s", format)
+	}
+}
+
+// WriteTable writes human-readable table with summary
+func WriteTable(w io.Writer, r *batch.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "MODULE\tVERSION\tSTATUS\tDETAILS")
+	for _, result := range r.Modules {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Module, result.Version, result.Status, details(&result))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%s: %d modules, %d allowed, %d denied, %d errors\n",
+		r.Verdict, r.Summary.Total, r.Summary.Allowed, r.Summary.Denied, r.Summary.Errors)
+	return err
+}
+
+func details(result *batch.Result) string {
+	switch {
+	case result.Denial != nil:
+		return fmt.Sprintf("%s (%s)", result.Denial.Message, result.Denial.Rule)
+	case result.Error != "":
+		return result.Error
+	default:
+		return "-"
+	}
+}
+
+// WriteJSON writes report as is in JSON
+func WriteJSON(w io.Writer, r *batch.Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes report as JUnit XML. Each module is a test case, denied modules are failures.
+func WriteJUnit(w io.Writer, r *batch.Report, source string) error {
+	suite := junitTestSuite{
+		Name:      source,
+		Tests:     r.Summary.Total,
+		Failures:  r.Summary.Denied,
+		Errors:    r.Summary.Errors,
+		TestCases: make([]junitTestCase, 0, len(r.Modules)),
+	}
+
+	for _, result := range r.Modules {
+		testCase := junitTestCase{
+			ClassName: "licensevalidator",
+			Name:      result.Module + "@" + result.Version,
+		}
+
+		switch {
+		case result.Denial != nil:
+			testCase.Failure = &junitMessage{
+				Message: result.Denial.Message,
+				Type:    string(result.Denial.Reason),
+				Text:    denialText(result.Denial),
+			}
+		case result.Error != "":
+			testCase.Error = &junitMessage{
+				Message: result.Error,
+				Type:    string(batch.StatusError),
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err := encoder.Encode(junitTestSuites{
+		Name:     "licensevalidator",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Suites:   []junitTestSuite{suite},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func denialText(d *validation.Denial) string {
+	text := "Rule: " + d.Rule
+	if d.License != "" {
+		text += "\nLicense: " + d.License
+	}
+
+	if note := d.HelpNote(); note != "" {
+		text += "\n" + note
+	}
+
+	return text
+}
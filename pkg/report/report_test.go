@@ -0,0 +1,171 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/report"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testReport = &batch.Report{
+	Verdict: batch.StatusDenied,
+	Summary: batch.Summary{Total: 3, Allowed: 1, Denied: 1, Errors: 1},
+	Modules: []batch.Result{
+		{Module: "github.com/test/allowed", Version: "v1.0.0", Status: batch.StatusAllowed},
+		{
+			Module:  "github.com/test/denied",
+			Version: "v1.1.0",
+			Status:  batch.StatusDenied,
+			Denial: &validation.Denial{
+				Module:  "github.com/test/denied",
+				Version: "v1.1.0",
+				License: "AGPL-3.0",
+				Rule:    "denied_licenses: AGPL-3.0",
+				Reason:  validation.DenialDeniedLicense,
+				Message: "github.com/test/denied@v1.1.0 is licensed under AGPL-3.0 which is not allowed",
+				HelpURL: "https://example.com/policy",
+			},
+		},
+		{Module: "github.com/test/failed", Version: "v1.2.0", Status: batch.StatusError, Error: "test error"},
+	},
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Format   report.Format
+		Expected string
+		JSON     bool
+	}
+
+	f := func(tc testCase) {
+		t.Run(string(tc.Format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, report.Write(&buf, testReport, tc.Format, "go.mod"))
+
+			if tc.JSON {
+				assert.JSONEq(t, tc.Expected, buf.String())
+			} else {
+				assert.Equal(t, tc.Expected, buf.String())
+			}
+		})
+	}
+
+	f(testCase{
+		Format: report.FormatTable,
+		Expected: `MODULE                   VERSION  STATUS   DETAILS
+github.com/test/allowed  v1.0.0   allowed  -
+github.com/test/denied   v1.1.0   denied   github.com/test/denied@v1.1.0 is licensed under AGPL-3.0 which is not allowed (denied_licenses: AGPL-3.0)
+github.com/test/failed   v1.2.0   error    test error
+
+denied: 3 modules, 1 allowed, 1 denied, 1 errors
+`,
+	})
+
+	f(testCase{
+		Format: report.FormatJUnit,
+		Expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="licensevalidator" tests="3" failures="1" errors="1">
+  <testsuite name="go.mod" tests="3" failures="1" errors="1">
+    <testcase classname="licensevalidator" name="github.com/test/allowed@v1.0.0"></testcase>
+    <testcase classname="licensevalidator" name="github.com/test/denied@v1.1.0">
+      <failure message="github.com/test/denied@v1.1.0 is licensed under AGPL-3.0 which is not allowed" type="denied_license">Rule: denied_licenses: AGPL-3.0&#xA;License: AGPL-3.0&#xA;See https://example.com/policy for details</failure>
+    </testcase>
+    <testcase classname="licensevalidator" name="github.com/test/failed@v1.2.0">
+      <error message="test error" type="error"></error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+	})
+
+	f(testCase{
+		Format: report.FormatSARIF,
+		JSON:   true,
+		Expected: `{
+			"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+			"version": "2.1.0",
+			"runs": [{
+				"tool": {
+					"driver": {
+						"name": "licensevalidator",
+						"informationUri": "https://github.com/xakep666/licensevalidator",
+						"rules": [
+							{"id": "blacklisted", "shortDescription": {"text": "Module is blacklisted"}, "helpUri": "https://example.com/policy"},
+							{"id": "denied_license", "shortDescription": {"text": "Module license is not allowed"}, "helpUri": "https://example.com/policy"},
+							{"id": "unknown_license", "shortDescription": {"text": "Module license can't be determined"}, "helpUri": "https://example.com/policy"},
+							{"id": "validation_error", "shortDescription": {"text": "Module validation failed"}}
+						]
+					}
+				},
+				"results": [
+					{
+						"ruleId": "denied_license",
+						"level": "error",
+						"message": {"text": "github.com/test/denied@v1.1.0 is licensed under AGPL-3.0 which is not allowed"},
+						"locations": [{
+							"physicalLocation": {"artifactLocation": {"uri": "go.mod"}},
+							"logicalLocations": [{"name": "github.com/test/denied@v1.1.0", "kind": "module"}]
+						}]
+					},
+					{
+						"ruleId": "validation_error",
+						"level": "warning",
+						"message": {"text": "test error"},
+						"locations": [{
+							"physicalLocation": {"artifactLocation": {"uri": "go.mod"}},
+							"logicalLocations": [{"name": "github.com/test/failed@v1.2.0", "kind": "module"}]
+						}]
+					}
+				]
+			}]
+		}`,
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Error(t, report.Write(&bytes.Buffer{}, testReport, "bla", ""))
+	})
+}
+
+func TestWriteExplanationTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteExplanationTable(&buf, &validation.Explanation{
+		Module:  "rsc.io/pdf",
+		Version: "v0.1.1",
+		Steps: []validation.Step{
+			{Stage: validation.StageTranslate, Component: "override_translator", Message: "rsc.io/pdf -> github.com/rsc/pdf"},
+			{Stage: validation.StageCache, Component: "memory_cache", Message: "github.com/rsc/pdf@v0.1.1: cache miss"},
+			{Stage: validation.StageResolve, Component: "github", Message: "github.com/rsc/pdf: resolution failed", Error: "rate limit"},
+			{
+				Stage:      validation.StageDetect,
+				Component:  "goproxy",
+				Message:    "license detected with confidence 0.98",
+				License:    "BSD-3-Clause",
+				Confidence: 0.98,
+			},
+			{Stage: validation.StageRule, Component: "denied_licenses", Message: "BSD-3-Clause: matched, module denied"},
+		},
+		Outcome: validation.OutcomeDenied,
+		Denial: &validation.Denial{
+			Rule:    "denied_licenses: BSD-3-Clause",
+			Message: "rsc.io/pdf@v0.1.1 is licensed under BSD-3-Clause which is not allowed",
+		},
+	}))
+
+	assert.Equal(t, `#  STAGE      COMPONENT            DETAILS
+1  translate  override_translator  rsc.io/pdf -> github.com/rsc/pdf
+2  cache      memory_cache         github.com/rsc/pdf@v0.1.1: cache miss
+3  resolve    github               github.com/rsc/pdf: resolution failed: rate limit
+4  detect     goproxy              license detected with confidence 0.98, license BSD-3-Clause
+5  rule       denied_licenses      BSD-3-Clause: matched, module denied
+
+rsc.io/pdf@v0.1.1: denied, rsc.io/pdf@v0.1.1 is licensed under BSD-3-Clause which is not allowed (denied_licenses: BSD-3-Clause)
+`, buf.String())
+}
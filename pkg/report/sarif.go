@@ -0,0 +1,148 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	// sarifErrorRule is a rule id for modules which validation failed
+	sarifErrorRule = "validation_error"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// WriteSARIF writes report in SARIF 2.1.0 format. Only denied modules and validation errors are included to results.
+func WriteSARIF(w io.Writer, r *batch.Report, source string) error {
+	var helpURI string
+
+	run := sarifRun{Results: []sarifResult{}}
+
+	for _, result := range r.Modules {
+		var res sarifResult
+
+		switch {
+		case result.Denial != nil:
+			res = sarifResult{
+				RuleID:  string(result.Denial.Reason),
+				Level:   "error",
+				Message: sarifMessage{Text: result.Denial.Message},
+			}
+			helpURI = result.Denial.HelpURL
+		case result.Error != "":
+			res = sarifResult{
+				RuleID:  sarifErrorRule,
+				Level:   "warning",
+				Message: sarifMessage{Text: result.Error},
+			}
+		default:
+			continue
+		}
+
+		location := sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{{Name: result.Module + "@" + result.Version, Kind: "module"}},
+		}
+
+		if source != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: source}}
+		}
+
+		res.Locations = []sarifLocation{location}
+		run.Results = append(run.Results, res)
+	}
+
+	run.Tool.Driver = sarifDriver{
+		Name:           "licensevalidator",
+		InformationURI: "https://github.com/xakep666/licensevalidator",
+		Rules: []sarifRule{
+			{
+				ID:               string(validation.DenialBlacklisted),
+				ShortDescription: sarifMessage{Text: "Module is blacklisted"},
+				HelpURI:          helpURI,
+			},
+			{
+				ID:               string(validation.DenialDeniedLicense),
+				ShortDescription: sarifMessage{Text: "Module license is not allowed"},
+				HelpURI:          helpURI,
+			},
+			{
+				ID:               string(validation.DenialUnknownLicense),
+				ShortDescription: sarifMessage{Text: "Module license can't be determined"},
+				HelpURI:          helpURI,
+			},
+			{
+				ID:               sarifErrorRule,
+				ShortDescription: sarifMessage{Text: "Module validation failed"},
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
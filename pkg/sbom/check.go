@@ -0,0 +1,133 @@
+package sbom
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// LicenseSource defines where licenses of SBOM components are taken from during check
+type LicenseSource string
+
+const (
+	// LicensesDeclared trusts licenses declared in document, components without declared license have unknown license
+	LicensesDeclared LicenseSource = "declared"
+
+	// LicensesResolve ignores declared licenses and resolves them with configured license resolvers
+	LicensesResolve LicenseSource = "resolve"
+)
+
+// LicenseSources contains all supported license sources
+var LicenseSources = []LicenseSource{LicensesDeclared, LicensesResolve}
+
+// DeclaredResolver is a license resolver which returns licenses declared in SBOM document
+type DeclaredResolver struct {
+	licenses map[string]validation.License
+}
+
+func NewDeclaredResolver(declared []Declared) *DeclaredResolver {
+	licenses := make(map[string]validation.License, len(declared))
+	for _, item := range declared {
+		licenses[declaredKey(item.Module)] = item.License
+	}
+
+	return &DeclaredResolver{licenses: licenses}
+}
+
+func declaredKey(m validation.Module) string {
+	return m.Name + "@" + m.Version.Original()
+}
+
+// Name returns resolver name shown in validation explanations, licenses are declared by SBOM document
+func (*DeclaredResolver) Name() string { return "sbom" }
+
+func (r *DeclaredResolver) ResolveLicense(ctx context.Context, m validation.Module) (validation.License, error) {
+	license, ok := r.licenses[declaredKey(m)]
+	if !ok || (license == validation.License{}) {
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: r.Name(),
+			Message:   fmt.Sprintf("%s: license not declared", m.Name),
+		})
+
+		return validation.License{}, validation.ErrUnknownLicense
+	}
+
+	id := license.SPDXID
+	if id == "" {
+		id = license.Name
+	}
+
+	validation.RecordStep(ctx, validation.Step{
+		Stage:     validation.StageResolve,
+		Component: r.Name(),
+		Message:   fmt.Sprintf("%s: license declared", m.Name),
+		License:   id,
+	})
+
+	return license, nil
+}
+
+type CheckerParams struct {
+	// Validator validates modules resolving licenses with configured resolvers, used for LicensesResolve
+	Validator validation.Validator
+
+	// DeclaredValidator builds validator which takes licenses from provided resolver, used for LicensesDeclared.
+	// Such validator should apply the same rules as Validator.
+	DeclaredValidator func(resolver validation.LicenseResolver) validation.Validator
+
+	// Concurrency limits number of simultaneous validations. Default is batch.DefaultConcurrency.
+	Concurrency int
+
+	// HelpURL is an optional link added to denial descriptions
+	HelpURL string
+
+	// Messages contains optional custom denial messages
+	Messages validation.DenialMessages
+}
+
+// Checker validates go modules listed in SBOM documents against rule set
+type Checker struct {
+	CheckerParams
+
+	log *zap.Logger
+}
+
+func NewChecker(log *zap.Logger, params CheckerParams) *Checker {
+	return &Checker{
+		CheckerParams: params,
+		log:           log.With(zap.String("component", "sbom_checker")),
+	}
+}
+
+// Check validates all declared modules taking licenses from source and returns report
+func (c *Checker) Check(ctx context.Context, declared []Declared, source LicenseSource) (*batch.Report, error) {
+	var validator validation.Validator
+
+	switch source {
+	case "", LicensesDeclared:
+		validator = c.DeclaredValidator(NewDeclaredResolver(declared))
+	case LicensesResolve:
+		validator = c.Validator
+	default:
+		return nil, fmt.Errorf("unknown license source %s", source)
+	}
+
+	modules := make([]validation.Module, 0, len(declared))
+	for _, item := range declared {
+		modules = append(modules, item.Module)
+	}
+
+	c.log.Debug("Checking sbom", zap.Int("modules", len(modules)), zap.String("licenses", string(source)))
+
+	return batch.NewValidator(c.log, batch.ValidatorParams{
+		Validator:   validator,
+		Concurrency: c.Concurrency,
+		HelpURL:     c.HelpURL,
+		Messages:    c.Messages,
+	}).Validate(ctx, modules)
+}
@@ -0,0 +1,119 @@
+package sbom_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/batch"
+	"github.com/xakep666/licensevalidator/pkg/sbom"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	dual := sbom.Declared{
+		Module:  validation.Module{Name: "github.com/test/dual", Version: semver.MustParse("v1.2.0")},
+		License: validation.License{SPDXID: "GPL-3.0 OR MIT"},
+	}
+	gpl := sbom.Declared{
+		Module:  validation.Module{Name: "github.com/test/gpl", Version: semver.MustParse("v1.0.0")},
+		License: validation.License{SPDXID: "GPL-3.0"},
+	}
+	undeclared := sbom.Declared{
+		Module: validation.Module{Name: "github.com/test/undeclared", Version: semver.MustParse("v0.1.0")},
+	}
+
+	declared := []sbom.Declared{dual, gpl, undeclared}
+
+	newChecker := func(t *testing.T, validator validation.Validator) *sbom.Checker {
+		log := zaptest.NewLogger(t)
+		return sbom.NewChecker(log, sbom.CheckerParams{
+			Validator: validator,
+			DeclaredValidator: func(resolver validation.LicenseResolver) validation.Validator {
+				return validation.NewNotifyingValidator(log, validation.NotifyingValidatorParams{
+					Validator: validation.NewRuleSetValidator(log, validation.RuleSetValidatorParams{
+						Translator:      &validation.ChainedTranslator{},
+						LicenseResolver: resolver,
+						RuleSet: validation.RuleSet{
+							AllowedLicenses: []validation.License{{SPDXID: "MIT"}},
+						},
+					}),
+					UnknownLicenseAction: validation.UnknownLicenseDeny,
+				})
+			},
+		})
+	}
+
+	t.Run("declared", func(t *testing.T) {
+		t.Parallel()
+
+		report, err := newChecker(t, nil).Check(context.Background(), declared, sbom.LicensesDeclared)
+		require.NoError(t, err)
+
+		assert.Equal(t, batch.StatusDenied, report.Verdict)
+		assert.Equal(t, batch.Summary{Total: 3, Allowed: 1, Denied: 2}, report.Summary)
+		assert.Equal(t, batch.StatusAllowed, report.Modules[0].Status)
+		assert.Equal(t, batch.StatusDenied, report.Modules[1].Status)
+		assert.Equal(t, batch.StatusDenied, report.Modules[2].Status)
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		t.Parallel()
+
+		var validatorMock validation.ValidatorMock
+		defer validatorMock.AssertExpectations(t)
+
+		for _, item := range declared {
+			validatorMock.On("Validate", mock.Anything, item.Module).Return(nil).Once()
+		}
+
+		report, err := newChecker(t, &validatorMock).Check(context.Background(), declared, sbom.LicensesResolve)
+		require.NoError(t, err)
+
+		assert.Equal(t, batch.StatusAllowed, report.Verdict)
+		assert.Equal(t, batch.Summary{Total: 3, Allowed: 3}, report.Summary)
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sbom.NewChecker(zap.NewNop(), sbom.CheckerParams{}).Check(context.Background(), declared, "unknown")
+		assert.Error(t, err)
+	})
+}
+
+func TestDeclaredResolver_ResolveLicense(t *testing.T) {
+	t.Parallel()
+
+	named := sbom.Declared{
+		Module:  validation.Module{Name: "github.com/test/named", Version: semver.MustParse("v1.0.0")},
+		License: validation.License{Name: "Custom License"},
+	}
+
+	resolver := sbom.NewDeclaredResolver([]sbom.Declared{named})
+
+	var explanation validation.Explanation
+	lic, err := resolver.ResolveLicense(validation.WithExplanation(context.Background(), &explanation), named.Module)
+	require.NoError(t, err)
+	assert.Equal(t, named.License, lic)
+	assert.Equal(t, []validation.Step{{
+		Stage:     validation.StageResolve,
+		Component: "sbom",
+		Message:   "github.com/test/named: license declared",
+		License:   "Custom License",
+	}}, explanation.Steps)
+
+	_, err = resolver.ResolveLicense(context.Background(), validation.Module{
+		Name:    "github.com/test/named",
+		Version: semver.MustParse("v1.0.1"),
+	})
+	assert.Equal(t, validation.ErrUnknownLicense, err)
+}
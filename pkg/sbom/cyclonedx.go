@@ -0,0 +1,124 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+type cdxDocument struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp"`
+	Tools     cdxTools      `json:"tools"`
+	Component *cdxComponent `json:"component,omitempty"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string           `json:"type"`
+	BOMRef     string           `json:"bom-ref,omitempty"`
+	Name       string           `json:"name"`
+	Version    string           `json:"version,omitempty"`
+	Purl       string           `json:"purl,omitempty"`
+	Licenses   []cdxLicenseItem `json:"licenses,omitempty"`
+	Properties []cdxProperty    `json:"properties,omitempty"`
+}
+
+type cdxLicenseItem struct {
+	License cdxLicense `json:"license"`
+}
+
+type cdxLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// property names used to annotate components
+const (
+	cdxPropertyResolver   = ToolName + ":resolver"
+	cdxPropertyConfidence = ToolName + ":confidence"
+	cdxPropertyError      = ToolName + ":error"
+)
+
+func cycloneDXFromBOM(bom *BOM) *cdxDocument {
+	doc := &cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + bom.ID,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: bom.Created.UTC().Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{{Type: "application", Name: ToolName}},
+			},
+		},
+		Components: make([]cdxComponent, 0, len(bom.Components)),
+	}
+
+	if bom.Name != "" {
+		doc.Metadata.Component = &cdxComponent{Type: "application", Name: bom.Name}
+	}
+
+	for i := range bom.Components {
+		c := &bom.Components[i]
+		purl := Purl(c.Module, c.Version)
+
+		component := cdxComponent{
+			Type:    "library",
+			BOMRef:  purl,
+			Name:    c.Module,
+			Version: c.Version,
+			Purl:    purl,
+		}
+
+		switch {
+		case c.License.SPDXID != "":
+			component.Licenses = []cdxLicenseItem{{License: cdxLicense{ID: c.License.SPDXID}}}
+		case c.License.Name != "":
+			component.Licenses = []cdxLicenseItem{{License: cdxLicense{Name: c.License.Name}}}
+		}
+
+		if c.Resolver != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: cdxPropertyResolver, Value: c.Resolver})
+		}
+
+		if c.Confidence > 0 {
+			component.Properties = append(component.Properties, cdxProperty{
+				Name:  cdxPropertyConfidence,
+				Value: strconv.FormatFloat(c.Confidence, 'f', 2, 64),
+			})
+		}
+
+		if c.Error != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: cdxPropertyError, Value: c.Error})
+		}
+
+		doc.Components = append(doc.Components, component)
+	}
+
+	return doc
+}
+
+// WriteCycloneDXJSON writes BOM as CycloneDX 1.5 JSON document
+func WriteCycloneDXJSON(w io.Writer, bom *BOM) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(cycloneDXFromBOM(bom))
+}
@@ -0,0 +1,308 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ErrUnknownDocument returned if document is neither SPDX nor CycloneDX
+var ErrUnknownDocument = errors.New("unknown sbom document format")
+
+// Declared is a go module found in SBOM document with license declared there.
+// License is empty if document doesn't declare it. License SPDX id may be a compound expression (i.e. "MIT OR Apache-2.0").
+type Declared struct {
+	Module  validation.Module
+	License validation.License
+}
+
+// Parse extracts go modules (components with "pkg:golang" package URL) from SPDX (JSON or tag-value)
+// or CycloneDX (JSON) document. Format is detected by content.
+func Parse(data []byte) ([]Declared, error) {
+	trimmed := bytes.TrimSpace(data)
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var probe struct {
+			SPDXVersion string `json:"spdxVersion"`
+			BOMFormat   string `json:"bomFormat"`
+		}
+
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return nil, fmt.Errorf("json decode failed: %w", err)
+		}
+
+		switch {
+		case probe.SPDXVersion != "":
+			return parseSPDXJSON(trimmed)
+		case probe.BOMFormat == "CycloneDX":
+			return parseCycloneDXJSON(trimmed)
+		}
+	case bytes.HasPrefix(trimmed, []byte("SPDXVersion:")):
+		return parseSPDXTagValue(trimmed)
+	}
+
+	return nil, ErrUnknownDocument
+}
+
+// ParsePurl extracts module path and version from go package URL (i.e. pkg:golang/github.com/stretchr/testify@v1.5.1).
+// ok is false if purl is not a go package URL.
+func ParsePurl(purl string) (module, version string, ok bool) {
+	const prefix = "pkg:golang/"
+	if !strings.HasPrefix(purl, prefix) {
+		return "", "", false
+	}
+
+	purl = strings.TrimPrefix(purl, prefix)
+
+	// qualifiers and subpath are not interesting
+	if idx := strings.IndexAny(purl, "?#"); idx >= 0 {
+		purl = purl[:idx]
+	}
+
+	if idx := strings.LastIndex(purl, "@"); idx >= 0 {
+		purl, version = purl[:idx], purl[idx+1:]
+	}
+
+	module, err := url.PathUnescape(purl)
+	if err != nil || module == "" {
+		return "", "", false
+	}
+
+	version, err = url.PathUnescape(version)
+	if err != nil {
+		return "", "", false
+	}
+
+	return module, version, true
+}
+
+func declaredModule(purl string, license validation.License) (Declared, bool, error) {
+	module, version, ok := ParsePurl(purl)
+	if !ok {
+		return Declared{}, false, nil
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return Declared{}, false, fmt.Errorf("module %s version %q parse failed: %w", module, version, err)
+	}
+
+	return Declared{
+		Module:  validation.Module{Name: module, Version: v},
+		License: license,
+	}, true, nil
+}
+
+// spdxDeclaredLicense returns license from SPDX license fields, declared license takes precedence
+func spdxDeclaredLicense(declared, concluded string) validation.License {
+	for _, id := range []string{declared, concluded} {
+		id = strings.TrimSpace(id)
+		if id != "" && id != spdxNoAssertion && id != spdxNone {
+			return validation.License{SPDXID: id}
+		}
+	}
+
+	return validation.License{}
+}
+
+func parseSPDXJSON(data []byte) ([]Declared, error) {
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("spdx document decode failed: %w", err)
+	}
+
+	var ret []Declared
+	for _, pkg := range doc.Packages {
+		for _, ref := range pkg.ExternalRefs {
+			if ref.ReferenceType != "purl" {
+				continue
+			}
+
+			item, ok, err := declaredModule(ref.ReferenceLocator, spdxDeclaredLicense(pkg.LicenseDeclared, pkg.LicenseConcluded))
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				ret = append(ret, item)
+				break
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func parseSPDXTagValue(data []byte) ([]Declared, error) {
+	type spdxTagPackage struct {
+		purls               []string
+		declared, concluded string
+	}
+
+	var (
+		packages []spdxTagPackage
+		inText   bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// multiline <text> values are not interesting
+		if inText {
+			inText = !strings.Contains(line, "</text>")
+			continue
+		}
+
+		if strings.Contains(line, "<text>") && !strings.Contains(line, "</text>") {
+			inText = true
+			continue
+		}
+
+		idx := strings.Index(line, ":")
+		if idx < 0 {
+			continue
+		}
+
+		tag, value := strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])
+
+		if tag == "PackageName" {
+			packages = append(packages, spdxTagPackage{})
+			continue
+		}
+
+		if len(packages) == 0 {
+			continue
+		}
+
+		pkg := &packages[len(packages)-1]
+		switch tag {
+		case "PackageLicenseDeclared":
+			pkg.declared = value
+		case "PackageLicenseConcluded":
+			pkg.concluded = value
+		case "ExternalRef":
+			// ExternalRef: <category> <type> <locator>
+			fields := strings.Fields(value)
+			if len(fields) == 3 && fields[1] == "purl" {
+				pkg.purls = append(pkg.purls, fields[2])
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("spdx document read failed: %w", err)
+	}
+
+	var ret []Declared
+	for _, pkg := range packages {
+		for _, purl := range pkg.purls {
+			item, ok, err := declaredModule(purl, spdxDeclaredLicense(pkg.declared, pkg.concluded))
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				ret = append(ret, item)
+				break
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func parseCycloneDXJSON(data []byte) ([]Declared, error) {
+	var doc struct {
+		Components []cdxInputComponent `json:"components"`
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("cyclonedx document decode failed: %w", err)
+	}
+
+	var ret []Declared
+	err := walkCycloneDXComponents(doc.Components, func(c *cdxInputComponent) error {
+		item, ok, err := declaredModule(c.Purl, c.license())
+		if ok {
+			ret = append(ret, item)
+		}
+
+		return err
+	})
+
+	return ret, err
+}
+
+// cdxInputComponent is a CycloneDX component with all license choices and nested components
+type cdxInputComponent struct {
+	Purl       string              `json:"purl"`
+	Licenses   []cdxInputLicense   `json:"licenses"`
+	Components []cdxInputComponent `json:"components"`
+}
+
+type cdxInputLicense struct {
+	License    *cdxLicense `json:"license"`
+	Expression string      `json:"expression"`
+}
+
+// license joins all component licenses to single license. Multiple licenses are treated as applied together.
+func (c *cdxInputComponent) license() validation.License {
+	var (
+		ids   []string
+		names []string
+	)
+
+	for _, item := range c.Licenses {
+		switch {
+		case item.Expression != "":
+			ids = append(ids, item.Expression)
+		case item.License != nil && item.License.ID != "":
+			ids = append(ids, item.License.ID)
+		case item.License != nil && item.License.Name != "":
+			names = append(names, item.License.Name)
+		}
+	}
+
+	switch {
+	case len(ids) == 1 && len(names) == 0:
+		return validation.License{SPDXID: ids[0]}
+	case len(ids) > 0 && len(names) == 0:
+		for i, id := range ids {
+			if validation.IsLicenseExpression(id) {
+				ids[i] = "(" + id + ")"
+			}
+		}
+
+		return validation.License{SPDXID: strings.Join(ids, " AND ")}
+	case len(ids) == 0 && len(names) == 1:
+		return validation.License{Name: names[0]}
+	default:
+		// license names can't be combined with expressions, so treat license as unknown
+		return validation.License{}
+	}
+}
+
+func walkCycloneDXComponents(components []cdxInputComponent, fn func(c *cdxInputComponent) error) error {
+	for i := range components {
+		if err := fn(&components[i]); err != nil {
+			return err
+		}
+
+		if err := walkCycloneDXComponents(components[i].Components, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package sbom_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/sbom"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Generated(t *testing.T) {
+	t.Parallel()
+
+	pdf := sbom.Declared{
+		Module:  validation.Module{Name: "rsc.io/pdf", Version: semver.MustParse("v0.1.1")},
+		License: validation.License{SPDXID: "BSD-3-Clause"},
+	}
+	custom := sbom.Declared{
+		Module: validation.Module{Name: "github.com/test/custom", Version: semver.MustParse("v1.0.0+incompatible")},
+	}
+	failed := sbom.Declared{
+		Module: validation.Module{Name: "github.com/test/failed", Version: semver.MustParse("v1.0.0")},
+	}
+
+	customNamed := custom
+	customNamed.License = validation.License{Name: "Custom License"}
+	expected := map[sbom.Format][]sbom.Declared{
+		sbom.FormatSPDXJSON:      {pdf, custom, failed},
+		sbom.FormatSPDXTagValue:  {pdf, custom, failed},
+		sbom.FormatCycloneDXJSON: {pdf, customNamed, failed},
+	}
+
+	for _, format := range sbom.Formats {
+		format := format
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, sbom.Write(&buf, testBOM, format))
+
+			declared, err := sbom.Parse(buf.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, expected[format], declared)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name     string
+		Document string
+		Expected []sbom.Declared
+		Error    error
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			declared, err := sbom.Parse([]byte(tc.Document))
+			if tc.Error != nil {
+				assert.True(t, errors.Is(err, tc.Error))
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.Expected, declared)
+			}
+		})
+	}
+
+	f(testCase{
+		Name: "cyclonedx expressions and nested components",
+		Document: `{
+			"bomFormat": "CycloneDX",
+			"specVersion": "1.5",
+			"components": [
+				{
+					"type": "library",
+					"name": "github.com/test/dual",
+					"purl": "pkg:golang/github.com/test/dual@v1.2.0?type=module",
+					"licenses": [{"expression": "MIT OR Apache-2.0"}],
+					"components": [
+						{
+							"type": "library",
+							"name": "github.com/test/multi",
+							"purl": "pkg:golang/github.com/test/multi@v0.3.0",
+							"licenses": [{"license": {"id": "MIT"}}, {"expression": "BSD-2-Clause OR ISC"}]
+						}
+					]
+				},
+				{
+					"type": "library",
+					"name": "left-pad",
+					"purl": "pkg:npm/left-pad@1.3.0",
+					"licenses": [{"license": {"id": "WTFPL"}}]
+				}
+			]
+		}`,
+		Expected: []sbom.Declared{
+			{
+				Module:  validation.Module{Name: "github.com/test/dual", Version: semver.MustParse("v1.2.0")},
+				License: validation.License{SPDXID: "MIT OR Apache-2.0"},
+			},
+			{
+				Module:  validation.Module{Name: "github.com/test/multi", Version: semver.MustParse("v0.3.0")},
+				License: validation.License{SPDXID: "MIT AND (BSD-2-Clause OR ISC)"},
+			},
+		},
+	})
+
+	f(testCase{
+		Name: "spdx declared license preferred",
+		Document: `{
+			"spdxVersion": "SPDX-2.3",
+			"packages": [
+				{
+					"name": "github.com/test/declared",
+					"licenseConcluded": "MIT",
+					"licenseDeclared": "Apache-2.0",
+					"externalRefs": [
+						{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:test:declared:1.0.0"},
+						{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:golang/github.com/test/declared@v1.0.0"}
+					]
+				},
+				{
+					"name": "github.com/test/concluded",
+					"licenseConcluded": "MIT",
+					"licenseDeclared": "NOASSERTION",
+					"externalRefs": [
+						{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:golang/github.com/test/concluded@v2.0.0"}
+					]
+				}
+			]
+		}`,
+		Expected: []sbom.Declared{
+			{
+				Module:  validation.Module{Name: "github.com/test/declared", Version: semver.MustParse("v1.0.0")},
+				License: validation.License{SPDXID: "Apache-2.0"},
+			},
+			{
+				Module:  validation.Module{Name: "github.com/test/concluded", Version: semver.MustParse("v2.0.0")},
+				License: validation.License{SPDXID: "MIT"},
+			},
+		},
+	})
+
+	f(testCase{
+		Name:     "unknown document",
+		Document: `{"modules": []}`,
+		Error:    sbom.ErrUnknownDocument,
+	})
+}
+
+func TestParsePurl(t *testing.T) {
+	t.Parallel()
+
+	module, version, ok := sbom.ParsePurl("pkg:golang/github.com/test/escaped%2Bname@v1.0.0%2Bincompatible?type=module#sub/dir")
+	assert.True(t, ok)
+	assert.Equal(t, "github.com/test/escaped+name", module)
+	assert.Equal(t, "v1.0.0+incompatible", version)
+
+	_, _, ok = sbom.ParsePurl("pkg:npm/left-pad@1.3.0")
+	assert.False(t, ok)
+}
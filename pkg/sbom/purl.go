@@ -0,0 +1,21 @@
+package sbom
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Purl returns package URL of go module (i.e. pkg:golang/github.com/stretchr/testify@v1.5.1)
+func Purl(module, version string) string {
+	parts := strings.Split(module, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+
+	ret := "pkg:golang/" + strings.Join(parts, "/")
+	if version != "" {
+		ret += "@" + url.PathEscape(version)
+	}
+
+	return ret
+}
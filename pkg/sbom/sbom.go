@@ -0,0 +1,174 @@
+// Package sbom contains generation of license-annotated software bills of materials in SPDX and CycloneDX formats
+package sbom
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// DefaultConcurrency is a default number of simultaneous license resolutions
+const DefaultConcurrency = 8
+
+// ToolName is a name of tool put to BOM creation info
+const ToolName = "licensevalidator"
+
+// Component is a module included to BOM with its resolved license
+type Component struct {
+	Module  string
+	Version string
+
+	// License is a resolved (concluded) license, it's empty if license unknown or resolution failed
+	License validation.License
+
+	// Resolver is a name of component which provided license (license resolver or cache)
+	Resolver string
+
+	// Confidence is a license detector confidence, it's zero if license wasn't detected from files
+	Confidence float64
+
+	// Error is a license resolution error text
+	Error string
+}
+
+// BOM is a format-independent bill of materials
+type BOM struct {
+	// Name is a name of described project or input file
+	Name string
+
+	// ID is a random UUID used as document namespace and serial number
+	ID string
+
+	Created    time.Time
+	Components []Component
+}
+
+type GeneratorParams struct {
+	LicenseResolver validation.LicenseResolver
+
+	// Concurrency limits number of simultaneous license resolutions. Default is DefaultConcurrency.
+	Concurrency int
+}
+
+// Generator builds BOMs for module sets resolving licenses with provided resolver
+type Generator struct {
+	GeneratorParams
+
+	log *zap.Logger
+}
+
+func NewGenerator(log *zap.Logger, params GeneratorParams) *Generator {
+	if params.Concurrency <= 0 {
+		params.Concurrency = DefaultConcurrency
+	}
+
+	return &Generator{
+		GeneratorParams: params,
+		log:             log.With(zap.String("component", "sbom_generator")),
+	}
+}
+
+// Generate resolves licenses of all modules and returns BOM with components in input order.
+// Resolution failures are reported in components, error returned only if context is done.
+func (g *Generator) Generate(ctx context.Context, name string, modules []validation.Module) (*BOM, error) {
+	components := make([]Component, len(modules))
+	jobs := make(chan int)
+	done := make(chan struct{})
+
+	workers := g.Concurrency
+	if workers > len(modules) {
+		workers = len(modules)
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+
+			for idx := range jobs {
+				components[idx] = g.resolve(ctx, modules[idx])
+			}
+		}()
+	}
+
+loop:
+	for i := range modules {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	close(jobs)
+
+	for i := 0; i < workers; i++ {
+		<-done
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &BOM{
+		Name:       name,
+		ID:         newUUID(),
+		Created:    time.Now().UTC().Truncate(time.Second),
+		Components: components,
+	}, nil
+}
+
+func (g *Generator) resolve(ctx context.Context, m validation.Module) Component {
+	component := Component{Module: m.Name, Version: m.Version.Original()}
+
+	// explanation is used to find out which resolver gave the license and with what confidence
+	var explanation validation.Explanation
+
+	lic, err := g.LicenseResolver.ResolveLicense(validation.WithExplanation(ctx, &explanation), m)
+	switch {
+	case errors.Is(err, nil):
+		component.License = lic
+		component.Resolver, component.Confidence = provenance(explanation.Steps)
+	case errors.Is(err, validation.ErrUnknownLicense):
+		g.log.Info("Module license unknown", zap.Stringer("module", &m))
+	default:
+		g.log.Warn("Module license resolution failed", zap.Stringer("module", &m), zap.Error(err))
+		component.Error = err.Error()
+	}
+
+	return component
+}
+
+// provenance returns name of component which resolved license and detection confidence
+func provenance(steps []validation.Step) (resolver string, confidence float64) {
+	for _, step := range steps {
+		if step.License == "" {
+			continue
+		}
+
+		switch step.Stage {
+		case validation.StageResolve, validation.StageCache:
+			resolver = step.Component
+		case validation.StageDetect:
+			confidence = step.Confidence
+		}
+	}
+
+	return resolver, confidence
+}
+
+// newUUID returns random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
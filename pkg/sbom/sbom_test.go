@@ -0,0 +1,309 @@
+package sbom_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/sbom"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGenerator_Generate(t *testing.T) {
+	t.Parallel()
+
+	detected := validation.Module{Name: "rsc.io/pdf", Version: semver.MustParse("v0.1.1")}
+	cached := validation.Module{Name: "github.com/stretchr/testify", Version: semver.MustParse("v1.5.1")}
+	unknown := validation.Module{Name: "github.com/test/unknown", Version: semver.MustParse("v1.0.0")}
+	failed := validation.Module{Name: "github.com/test/failed", Version: semver.MustParse("v1.0.0")}
+
+	var resolverMock validation.LicenseResolverMock
+	defer resolverMock.AssertExpectations(t)
+
+	resolverMock.On("ResolveLicense", mock.Anything, detected).Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		validation.RecordStep(ctx, validation.Step{Stage: validation.StageCache, Component: "memory_cache", Message: "cache miss"})
+		validation.RecordStep(ctx, validation.Step{Stage: validation.StageResolve, Component: "github", Message: "license unknown"})
+		validation.RecordStep(ctx, validation.Step{
+			Stage:      validation.StageDetect,
+			Component:  "goproxy",
+			Message:    "license detected",
+			License:    "BSD-3-Clause",
+			Confidence: 0.97,
+		})
+		validation.RecordStep(ctx, validation.Step{
+			Stage:     validation.StageResolve,
+			Component: "goproxy",
+			Message:   "license resolved",
+			License:   "BSD-3-Clause",
+		})
+	}).Return(validation.License{Name: "BSD 3-Clause \"New\" or \"Revised\" License", SPDXID: "BSD-3-Clause"}, nil).Once()
+
+	resolverMock.On("ResolveLicense", mock.Anything, cached).Run(func(args mock.Arguments) {
+		validation.RecordStep(args.Get(0).(context.Context), validation.Step{
+			Stage:     validation.StageCache,
+			Component: "memory_cache",
+			Message:   "cache hit",
+			License:   "MIT",
+		})
+	}).Return(validation.License{Name: "MIT License", SPDXID: "MIT"}, nil).Once()
+
+	resolverMock.On("ResolveLicense", mock.Anything, unknown).Return(validation.License{}, validation.ErrUnknownLicense).Once()
+	resolverMock.On("ResolveLicense", mock.Anything, failed).Return(validation.License{}, errors.New("test error")).Once()
+
+	bom, err := sbom.NewGenerator(zaptest.NewLogger(t), sbom.GeneratorParams{
+		LicenseResolver: &resolverMock,
+	}).Generate(context.Background(), "go.mod", []validation.Module{detected, cached, unknown, failed})
+	require.NoError(t, err)
+
+	assert.Equal(t, "go.mod", bom.Name)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, bom.ID)
+	assert.WithinDuration(t, time.Now(), bom.Created, time.Minute)
+	assert.Equal(t, []sbom.Component{
+		{
+			Module:     "rsc.io/pdf",
+			Version:    "v0.1.1",
+			License:    validation.License{Name: "BSD 3-Clause \"New\" or \"Revised\" License", SPDXID: "BSD-3-Clause"},
+			Resolver:   "goproxy",
+			Confidence: 0.97,
+		},
+		{
+			Module:   "github.com/stretchr/testify",
+			Version:  "v1.5.1",
+			License:  validation.License{Name: "MIT License", SPDXID: "MIT"},
+			Resolver: "memory_cache",
+		},
+		{Module: "github.com/test/unknown", Version: "v1.0.0"},
+		{Module: "github.com/test/failed", Version: "v1.0.0", Error: "test error"},
+	}, bom.Components)
+}
+
+var testBOM = &sbom.BOM{
+	Name:    "go.mod",
+	ID:      "0b5e1a8c-3d0a-4c1e-9a45-2f7b8c6d1e90",
+	Created: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+	Components: []sbom.Component{
+		{
+			Module:     "rsc.io/pdf",
+			Version:    "v0.1.1",
+			License:    validation.License{Name: "BSD 3-Clause \"New\" or \"Revised\" License", SPDXID: "BSD-3-Clause"},
+			Resolver:   "goproxy",
+			Confidence: 0.97,
+		},
+		{
+			Module:   "github.com/test/custom",
+			Version:  "v1.0.0+incompatible",
+			License:  validation.License{Name: "Custom License"},
+			Resolver: "github",
+		},
+		{Module: "github.com/test/failed", Version: "v1.0.0", Error: "test error"},
+	},
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Format   sbom.Format
+		Expected string
+		JSON     bool
+	}
+
+	f := func(tc testCase) {
+		t.Run(string(tc.Format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, sbom.Write(&buf, testBOM, tc.Format))
+
+			if tc.JSON {
+				assert.JSONEq(t, tc.Expected, buf.String())
+			} else {
+				assert.Equal(t, tc.Expected, buf.String())
+			}
+		})
+	}
+
+	f(testCase{
+		Format: sbom.FormatSPDXJSON,
+		JSON:   true,
+		Expected: `{
+			"spdxVersion": "SPDX-2.3",
+			"dataLicense": "CC0-1.0",
+			"SPDXID": "SPDXRef-DOCUMENT",
+			"name": "go.mod",
+			"documentNamespace": "https://spdx.org/spdxdocs/go.mod-0b5e1a8c-3d0a-4c1e-9a45-2f7b8c6d1e90",
+			"creationInfo": {"created": "2020-06-01T12:00:00Z", "creators": ["Tool: licensevalidator"]},
+			"packages": [
+				{
+					"name": "rsc.io/pdf",
+					"SPDXID": "SPDXRef-Package-rsc.io-pdf-v0.1.1",
+					"versionInfo": "v0.1.1",
+					"downloadLocation": "NOASSERTION",
+					"filesAnalyzed": false,
+					"licenseConcluded": "BSD-3-Clause",
+					"licenseDeclared": "NOASSERTION",
+					"copyrightText": "NOASSERTION",
+					"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:golang/rsc.io/pdf@v0.1.1"}],
+					"annotations": [{
+						"annotationDate": "2020-06-01T12:00:00Z",
+						"annotationType": "OTHER",
+						"annotator": "Tool: licensevalidator",
+						"comment": "license: BSD 3-Clause \"New\" or \"Revised\" License, resolver: goproxy, confidence: 0.97"
+					}]
+				},
+				{
+					"name": "github.com/test/custom",
+					"SPDXID": "SPDXRef-Package-github.com-test-custom-v1.0.0-incompatible",
+					"versionInfo": "v1.0.0+incompatible",
+					"downloadLocation": "NOASSERTION",
+					"filesAnalyzed": false,
+					"licenseConcluded": "NOASSERTION",
+					"licenseDeclared": "NOASSERTION",
+					"copyrightText": "NOASSERTION",
+					"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:golang/github.com/test/custom@v1.0.0+incompatible"}],
+					"annotations": [{
+						"annotationDate": "2020-06-01T12:00:00Z",
+						"annotationType": "OTHER",
+						"annotator": "Tool: licensevalidator",
+						"comment": "license: Custom License, resolver: github"
+					}]
+				},
+				{
+					"name": "github.com/test/failed",
+					"SPDXID": "SPDXRef-Package-github.com-test-failed-v1.0.0",
+					"versionInfo": "v1.0.0",
+					"downloadLocation": "NOASSERTION",
+					"filesAnalyzed": false,
+					"licenseConcluded": "NOASSERTION",
+					"licenseDeclared": "NOASSERTION",
+					"copyrightText": "NOASSERTION",
+					"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:golang/github.com/test/failed@v1.0.0"}],
+					"annotations": [{
+						"annotationDate": "2020-06-01T12:00:00Z",
+						"annotationType": "OTHER",
+						"annotator": "Tool: licensevalidator",
+						"comment": "license resolution failed: test error"
+					}]
+				}
+			],
+			"relationships": [
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Package-rsc.io-pdf-v0.1.1"},
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Package-github.com-test-custom-v1.0.0-incompatible"},
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Package-github.com-test-failed-v1.0.0"}
+			]
+		}`,
+	})
+
+	f(testCase{
+		Format: sbom.FormatSPDXTagValue,
+		Expected: `SPDXVersion: SPDX-2.3
+DataLicense: CC0-1.0
+SPDXID: SPDXRef-DOCUMENT
+DocumentName: go.mod
+DocumentNamespace: https://spdx.org/spdxdocs/go.mod-0b5e1a8c-3d0a-4c1e-9a45-2f7b8c6d1e90
+Creator: Tool: licensevalidator
+Created: 2020-06-01T12:00:00Z
+Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-rsc.io-pdf-v0.1.1
+Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-github.com-test-custom-v1.0.0-incompatible
+Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-github.com-test-failed-v1.0.0
+
+PackageName: rsc.io/pdf
+SPDXID: SPDXRef-Package-rsc.io-pdf-v0.1.1
+PackageVersion: v0.1.1
+PackageDownloadLocation: NOASSERTION
+FilesAnalyzed: false
+PackageLicenseConcluded: BSD-3-Clause
+PackageLicenseDeclared: NOASSERTION
+PackageCopyrightText: NOASSERTION
+ExternalRef: PACKAGE-MANAGER purl pkg:golang/rsc.io/pdf@v0.1.1
+Annotator: Tool: licensevalidator
+AnnotationDate: 2020-06-01T12:00:00Z
+AnnotationType: OTHER
+SPDXREF: SPDXRef-Package-rsc.io-pdf-v0.1.1
+AnnotationComment: <text>license: BSD 3-Clause "New" or "Revised" License, resolver: goproxy, confidence: 0.97</text>
+
+PackageName: github.com/test/custom
+SPDXID: SPDXRef-Package-github.com-test-custom-v1.0.0-incompatible
+PackageVersion: v1.0.0+incompatible
+PackageDownloadLocation: NOASSERTION
+FilesAnalyzed: false
+PackageLicenseConcluded: NOASSERTION
+PackageLicenseDeclared: NOASSERTION
+PackageCopyrightText: NOASSERTION
+ExternalRef: PACKAGE-MANAGER purl pkg:golang/github.com/test/custom@v1.0.0+incompatible
+Annotator: Tool: licensevalidator
+AnnotationDate: 2020-06-01T12:00:00Z
+AnnotationType: OTHER
+SPDXREF: SPDXRef-Package-github.com-test-custom-v1.0.0-incompatible
+AnnotationComment: <text>license: Custom License, resolver: github</text>
+
+PackageName: github.com/test/failed
+SPDXID: SPDXRef-Package-github.com-test-failed-v1.0.0
+PackageVersion: v1.0.0
+PackageDownloadLocation: NOASSERTION
+FilesAnalyzed: false
+PackageLicenseConcluded: NOASSERTION
+PackageLicenseDeclared: NOASSERTION
+PackageCopyrightText: NOASSERTION
+ExternalRef: PACKAGE-MANAGER purl pkg:golang/github.com/test/failed@v1.0.0
+Annotator: Tool: licensevalidator
+AnnotationDate: 2020-06-01T12:00:00Z
+AnnotationType: OTHER
+SPDXREF: SPDXRef-Package-github.com-test-failed-v1.0.0
+AnnotationComment: <text>license resolution failed: test error</text>
+`,
+	})
+
+	f(testCase{
+		Format: sbom.FormatCycloneDXJSON,
+		JSON:   true,
+		Expected: `{
+			"bomFormat": "CycloneDX",
+			"specVersion": "1.5",
+			"serialNumber": "urn:uuid:0b5e1a8c-3d0a-4c1e-9a45-2f7b8c6d1e90",
+			"version": 1,
+			"metadata": {
+				"timestamp": "2020-06-01T12:00:00Z",
+				"tools": {"components": [{"type": "application", "name": "licensevalidator"}]},
+				"component": {"type": "application", "name": "go.mod"}
+			},
+			"components": [
+				{
+					"type": "library",
+					"bom-ref": "pkg:golang/rsc.io/pdf@v0.1.1",
+					"name": "rsc.io/pdf",
+					"version": "v0.1.1",
+					"purl": "pkg:golang/rsc.io/pdf@v0.1.1",
+					"licenses": [{"license": {"id": "BSD-3-Clause"}}],
+					"properties": [
+						{"name": "licensevalidator:resolver", "value": "goproxy"},
+						{"name": "licensevalidator:confidence", "value": "0.97"}
+					]
+				},
+				{
+					"type": "library",
+					"bom-ref": "pkg:golang/github.com/test/custom@v1.0.0+incompatible",
+					"name": "github.com/test/custom",
+					"version": "v1.0.0+incompatible",
+					"purl": "pkg:golang/github.com/test/custom@v1.0.0+incompatible",
+					"licenses": [{"license": {"name": "Custom License"}}],
+					"properties": [{"name": "licensevalidator:resolver", "value": "github"}]
+				},
+				{
+					"type": "library",
+					"bom-ref": "pkg:golang/github.com/test/failed@v1.0.0",
+					"name": "github.com/test/failed",
+					"version": "v1.0.0",
+					"purl": "pkg:golang/github.com/test/failed@v1.0.0",
+					"properties": [{"name": "licensevalidator:error", "value": "test error"}]
+				}
+			]
+		}`,
+	})
+}
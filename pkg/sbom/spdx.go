@@ -0,0 +1,218 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	spdxVersion     = "SPDX-2.3"
+	spdxDataLicense = "CC0-1.0"
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+	spdxNoAssertion = "NOASSERTION"
+	spdxNone        = "NONE"
+	spdxCreator     = "Tool: " + ToolName
+)
+
+var spdxIDInvalidCharsRe = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs"`
+	Annotations      []spdxAnnotation  `json:"annotations,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxAnnotation struct {
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func spdxFromBOM(bom *BOM) *spdxDocument {
+	created := bom.Created.UTC().Format(time.RFC3339)
+
+	doc := &spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       spdxDataLicense,
+		SPDXID:            spdxDocumentID,
+		Name:              bom.Name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", spdxIDInvalidCharsRe.ReplaceAllString(bom.Name, "-"), bom.ID),
+		CreationInfo: spdxCreationInfo{
+			Created:  created,
+			Creators: []string{spdxCreator},
+		},
+		Packages:      make([]spdxPackage, 0, len(bom.Components)),
+		Relationships: make([]spdxRelationship, 0, len(bom.Components)),
+	}
+
+	ids := make(map[string]int)
+	for i := range bom.Components {
+		c := &bom.Components[i]
+
+		base := "SPDXRef-Package-" + spdxIDInvalidCharsRe.ReplaceAllString(c.Module+"-"+c.Version, "-")
+		id := base
+		if n := ids[base]; n > 0 {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		ids[base]++
+
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:             c.Module,
+			SPDXID:           id,
+			VersionInfo:      c.Version,
+			DownloadLocation: spdxNoAssertion,
+			FilesAnalyzed:    false,
+			LicenseConcluded: spdxLicense(c),
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			ExternalRefs: []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  Purl(c.Module, c.Version),
+			}},
+			Annotations: []spdxAnnotation{{
+				AnnotationDate: created,
+				AnnotationType: "OTHER",
+				Annotator:      spdxCreator,
+				Comment:        annotation(c),
+			}},
+		})
+
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxDocumentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return doc
+}
+
+// spdxLicense returns SPDX license expression. Licenses without SPDX id are not expressible so NOASSERTION returned.
+func spdxLicense(c *Component) string {
+	if c.License.SPDXID == "" {
+		return spdxNoAssertion
+	}
+
+	return c.License.SPDXID
+}
+
+// annotation describes license resolution result in human-readable form
+func annotation(c *Component) string {
+	switch {
+	case c.Error != "":
+		return fmt.Sprintf("license resolution failed: %s", c.Error)
+	case c.License.SPDXID == "" && c.License.Name == "":
+		return "license unknown"
+	}
+
+	name := c.License.Name
+	if name == "" {
+		name = c.License.SPDXID
+	}
+
+	parts := []string{fmt.Sprintf("license: %s", name)}
+	if c.Resolver != "" {
+		parts = append(parts, fmt.Sprintf("resolver: %s", c.Resolver))
+	}
+
+	if c.Confidence > 0 {
+		parts = append(parts, fmt.Sprintf("confidence: %.2f", c.Confidence))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// WriteSPDXJSON writes BOM as SPDX 2.3 JSON document
+func WriteSPDXJSON(w io.Writer, bom *BOM) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(spdxFromBOM(bom))
+}
+
+// WriteSPDXTagValue writes BOM as SPDX 2.3 tag-value document
+func WriteSPDXTagValue(w io.Writer, bom *BOM) error {
+	doc := spdxFromBOM(bom)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "SPDXVersion: %s\n", doc.SPDXVersion)
+	fmt.Fprintf(&b, "DataLicense: %s\n", doc.DataLicense)
+	fmt.Fprintf(&b, "SPDXID: %s\n", doc.SPDXID)
+	fmt.Fprintf(&b, "DocumentName: %s\n", doc.Name)
+	fmt.Fprintf(&b, "DocumentNamespace: %s\n", doc.DocumentNamespace)
+	for _, creator := range doc.CreationInfo.Creators {
+		fmt.Fprintf(&b, "Creator: %s\n", creator)
+	}
+	fmt.Fprintf(&b, "Created: %s\n", doc.CreationInfo.Created)
+
+	for _, rel := range doc.Relationships {
+		fmt.Fprintf(&b, "Relationship: %s %s %s\n", rel.SPDXElementID, rel.RelationshipType, rel.RelatedSPDXElement)
+	}
+
+	for _, pkg := range doc.Packages {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "PackageName: %s\n", pkg.Name)
+		fmt.Fprintf(&b, "SPDXID: %s\n", pkg.SPDXID)
+		fmt.Fprintf(&b, "PackageVersion: %s\n", pkg.VersionInfo)
+		fmt.Fprintf(&b, "PackageDownloadLocation: %s\n", pkg.DownloadLocation)
+		fmt.Fprintf(&b, "FilesAnalyzed: %t\n", pkg.FilesAnalyzed)
+		fmt.Fprintf(&b, "PackageLicenseConcluded: %s\n", pkg.LicenseConcluded)
+		fmt.Fprintf(&b, "PackageLicenseDeclared: %s\n", pkg.LicenseDeclared)
+		fmt.Fprintf(&b, "PackageCopyrightText: %s\n", pkg.CopyrightText)
+		for _, ref := range pkg.ExternalRefs {
+			fmt.Fprintf(&b, "ExternalRef: %s %s %s\n", ref.ReferenceCategory, ref.ReferenceType, ref.ReferenceLocator)
+		}
+
+		for _, a := range pkg.Annotations {
+			fmt.Fprintf(&b, "Annotator: %s\n", a.Annotator)
+			fmt.Fprintf(&b, "AnnotationDate: %s\n", a.AnnotationDate)
+			fmt.Fprintf(&b, "AnnotationType: %s\n", a.AnnotationType)
+			fmt.Fprintf(&b, "SPDXREF: %s\n", pkg.SPDXID)
+			fmt.Fprintf(&b, "AnnotationComment: <text>%s</text>\n", a.Comment)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,38 @@
+package sbom
+
+import (
+	"fmt"
+	"io"
+)
+
+type Format string
+
+const (
+	FormatSPDXJSON      Format = "spdx-json"
+	FormatSPDXTagValue  Format = "spdx"
+	FormatCycloneDXJSON Format = "cyclonedx-json"
+)
+
+// Formats contains all supported BOM formats
+var Formats = []Format{FormatSPDXJSON, FormatSPDXTagValue, FormatCycloneDXJSON}
+
+// ContentTypes contains media types of BOM formats
+var ContentTypes = map[Format]string{
+	FormatSPDXJSON:      "application/spdx+json",
+	FormatSPDXTagValue:  "text/spdx; charset=utf-8",
+	FormatCycloneDXJSON: "application/vnd.cyclonedx+json; version=1.5",
+}
+
+// Write writes BOM in given format
+func Write(w io.Writer, bom *BOM, format Format) error {
+	switch format {
+	case FormatSPDXJSON:
+		return WriteSPDXJSON(w, bom)
+	case FormatSPDXTagValue:
+		return WriteSPDXTagValue(w, bom)
+	case FormatCycloneDXJSON:
+		return WriteCycloneDXJSON(w, bom)
+	default:
+		return fmt.Errorf("unknown sbom format %s", format)
+	}
+}
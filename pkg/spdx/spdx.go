@@ -34,6 +34,7 @@ type LicenseInfo struct {
 
 var (
 	licenseIDIndex   map[string]LicenseInfo
+	licenseNameIndex map[string]LicenseInfo
 	licenseIndexOnce sync.Once
 )
 
@@ -51,9 +52,11 @@ func initIndexes() {
 		}
 
 		licenseIDIndex = make(map[string]LicenseInfo)
+		licenseNameIndex = make(map[string]LicenseInfo)
 
 		for _, item := range list.Licenses {
 			licenseIDIndex[item.ID] = item
+			licenseNameIndex[item.Name] = item
 		}
 	})
 }
@@ -64,3 +67,11 @@ func LicenseByID(id string) (LicenseInfo, bool) {
 	info, ok := licenseIDIndex[id]
 	return info, ok
 }
+
+// LicenseByName looks up license by human-readable name (i.e. "MIT License")
+func LicenseByName(name string) (LicenseInfo, bool) {
+	initIndexes()
+
+	info, ok := licenseNameIndex[name]
+	return info, ok
+}
@@ -27,3 +27,18 @@ func TestLicenseByID(t *testing.T) {
 		assert.False(t, ok)
 	})
 }
+
+func TestLicenseByName(t *testing.T) {
+	t.Parallel()
+	t.Run("find MIT", func(t *testing.T) {
+		licInfo, ok := spdx.LicenseByName("MIT License")
+		if assert.True(t, ok, "MIT present in SPDX but not found") {
+			assert.Equal(t, "MIT", licInfo.ID)
+		}
+	})
+
+	t.Run("non-existent", func(t *testing.T) {
+		_, ok := spdx.LicenseByName("MIT")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,202 @@
+// Package tlsreload contains TLS certificates provider which reloads them from disk when files change
+package tlsreload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ReloaderParams struct {
+	// CertFile and KeyFile are paths to PEM-encoded certificate (chain) and private key
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is optional path to PEM-encoded CA bundle for client certificates verification
+	ClientCAFile string
+
+	// CheckInterval is a files modification check period. Default is 10 seconds.
+	CheckInterval time.Duration
+}
+
+// Reloader holds current server certificate and client CA pool and periodically reloads them.
+// Reload affects only new TLS handshakes so established connections are served without interruption.
+type Reloader struct {
+	ReloaderParams
+
+	log *zap.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	hash      []byte
+	lastErr   error
+}
+
+// NewReloader creates reloader and loads certificates.
+// Error returned if initial loading failed.
+func NewReloader(log *zap.Logger, params ReloaderParams) (*Reloader, error) {
+	r := &Reloader{
+		ReloaderParams: params,
+		log:            log.With(zap.String("component", "tls_reloader"), zap.String("cert_file", params.CertFile)),
+	}
+
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Watch periodically checks files and reloads them if changed until context done.
+// Reload errors are logged and previously loaded certificates stay in use.
+func (r *Reloader) Watch(ctx context.Context) {
+	interval := r.CheckInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			switch {
+			case err != nil:
+				r.log.Error("Certificates reload failed, keep using previous ones", zap.Error(err))
+			case reloaded:
+				r.log.Info("Certificates reloaded")
+			}
+		}
+	}
+}
+
+// GetCertificate returns current certificate. It's suitable for tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// TLSConfig returns config for server using current certificate and client CA pool.
+// Base config fields are copied to result.
+func (r *Reloader) TLSConfig(base *tls.Config) *tls.Config {
+	cfg := base.Clone()
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+
+	cfg.GetCertificate = r.GetCertificate
+	if r.ClientCAFile != "" {
+		cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			clientCfg := cfg.Clone()
+			clientCfg.GetConfigForClient = nil
+			clientCfg.ClientCAs = r.clientCAs
+			return clientCfg, nil
+		}
+	}
+
+	return cfg
+}
+
+// Check returns last reload error. It allows to register reloader as health observer.
+func (r *Reloader) Check(context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.lastErr
+}
+
+func (r *Reloader) reload() (bool, error) {
+	certPEM, keyPEM, caPEM, hash, err := r.readFiles()
+	if err != nil {
+		r.setErr(err)
+		return false, err
+	}
+
+	r.mu.Lock()
+	unchanged := bytes.Equal(hash, r.hash)
+	if unchanged {
+		// files may be restored after failed read or broken content, previous certificates are valid again
+		r.lastErr = nil
+	}
+	r.mu.Unlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		err = fmt.Errorf("key pair load failed: %w", err)
+		r.setErr(err)
+		return false, err
+	}
+
+	var clientCAs *x509.CertPool
+	if r.ClientCAFile != "" {
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			err = fmt.Errorf("no certificates found in client CA file %s", r.ClientCAFile)
+			r.setErr(err)
+			return false, err
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.hash = hash
+	r.lastErr = nil
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+func (r *Reloader) readFiles() (certPEM, keyPEM, caPEM, hash []byte, err error) {
+	certPEM, err = ioutil.ReadFile(r.CertFile)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("certificate read failed: %w", err)
+	}
+
+	keyPEM, err = ioutil.ReadFile(r.KeyFile)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("key read failed: %w", err)
+	}
+
+	if r.ClientCAFile != "" {
+		caPEM, err = ioutil.ReadFile(r.ClientCAFile)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("client CA read failed: %w", err)
+		}
+	}
+
+	h := sha256.New()
+	for _, content := range [][]byte{certPEM, keyPEM, caPEM} {
+		sum := sha256.Sum256(content)
+		h.Write(sum[:])
+	}
+
+	return certPEM, keyPEM, caPEM, h.Sum(nil), nil
+}
+
+func (r *Reloader) setErr(err error) {
+	r.mu.Lock()
+	r.lastErr = err
+	r.mu.Unlock()
+}
@@ -0,0 +1,169 @@
+package tlsreload_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/tlsreload"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestReloader(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "tlsreload")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+
+	writeCert(t, certFile, keyFile, "first")
+	writeCert(t, caFile, filepath.Join(dir, "ca.key"), "client-ca")
+
+	reloader, err := tlsreload.NewReloader(zaptest.NewLogger(t), tlsreload.ReloaderParams{
+		CertFile:      certFile,
+		KeyFile:       keyFile,
+		ClientCAFile:  caFile,
+		CheckInterval: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go reloader.Watch(ctx)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = reloader.TLSConfig(&tls.Config{ClientAuth: tls.VerifyClientCertIfGiven})
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	assert.Equal(t, "first", serverCommonName(t, server.URL))
+
+	// keep established connection to ensure it's not broken by reload
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	writeCert(t, certFile, keyFile, "second")
+
+	assert.Eventually(t, func() bool {
+		return serverCommonName(t, server.URL) == "second"
+	}, 5*time.Second, 50*time.Millisecond, "certificate not reloaded")
+
+	resp, err = client.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, "first", resp.TLS.PeerCertificates[0].Subject.CommonName, "connection should be reused")
+	}
+
+	t.Run("broken files keep previous certificate", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(keyFile, []byte("broken"), 0600))
+
+		assert.Eventually(t, func() bool {
+			return reloader.Check(context.Background()) != nil
+		}, 5*time.Second, 50*time.Millisecond, "error not reported")
+		assert.Equal(t, "second", serverCommonName(t, server.URL))
+	})
+}
+
+func TestReloader_recovers_after_unreadable_files(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "tlsreload")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "first")
+
+	reloader, err := tlsreload.NewReloader(zaptest.NewLogger(t), tlsreload.ReloaderParams{
+		CertFile:      certFile,
+		KeyFile:       keyFile,
+		CheckInterval: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go reloader.Watch(ctx)
+
+	// file is unreadable while it's moved away, i.e. during non-atomic update
+	require.NoError(t, os.Rename(keyFile, keyFile+".bak"))
+
+	assert.Eventually(t, func() bool {
+		return reloader.Check(context.Background()) != nil
+	}, 5*time.Second, 50*time.Millisecond, "error not reported")
+
+	// the same content is restored, so certificates are not reloaded but error must be cleared
+	require.NoError(t, os.Rename(keyFile+".bak", keyFile))
+
+	assert.Eventually(t, func() bool {
+		return reloader.Check(context.Background()) == nil
+	}, 5*time.Second, 50*time.Millisecond, "error not cleared")
+}
+
+func TestNewReloader_fails_on_missing_files(t *testing.T) {
+	t.Parallel()
+	_, err := tlsreload.NewReloader(zaptest.NewLogger(t), tlsreload.ReloaderParams{
+		CertFile: "not-exists.crt",
+		KeyFile:  "not-exists.key",
+	})
+	assert.Error(t, err)
+}
+
+func serverCommonName(t *testing.T, url string) string {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", url[len("https://"):], &tls.Config{InsecureSkipVerify: true})
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func writeCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	// write key first because reloader may see new certificate with old key otherwise
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+}
@@ -13,11 +13,28 @@ type ChainedTranslator struct {
 
 func (ct *ChainedTranslator) Translate(ctx context.Context, m Module) (translated Module, err error) {
 	for _, translator := range ct.Translators {
+		in := m.Name
+
 		var err error
 		m, err = translator.Translate(ctx, m)
 		if err != nil {
+			RecordStep(ctx, Step{
+				Stage:     StageTranslate,
+				Component: componentName(translator),
+				Message:   fmt.Sprintf("%s: translation failed", in),
+				Error:     err.Error(),
+			})
 			return m, err
 		}
+
+		step := Step{Stage: StageTranslate, Component: componentName(translator)}
+		if in == m.Name {
+			step.Message = fmt.Sprintf("%s: unchanged", in)
+		} else {
+			step.Message = fmt.Sprintf("%s -> %s", in, m.Name)
+		}
+
+		RecordStep(ctx, step)
 	}
 
 	return m, nil
@@ -32,15 +49,63 @@ type ChainedLicenseResolver struct {
 func (crl *ChainedLicenseResolver) ResolveLicense(ctx context.Context, m Module) (License, error) {
 	for _, resolver := range crl.LicenseResolvers {
 		lic, err := resolver.ResolveLicense(ctx, m)
+		step := Step{Stage: StageResolve, Component: componentName(resolver)}
 		switch {
 		case errors.Is(err, nil):
+			step.Message, step.License = fmt.Sprintf("%s: license resolved", m.Name), licenseID(&lic)
+			RecordStep(ctx, step)
 			return lic, nil
 		case errors.Is(err, ErrUnknownLicense):
+			step.Message = fmt.Sprintf("%s: license unknown, trying next resolver", m.Name)
+			RecordStep(ctx, step)
 			continue
 		default:
+			step.Message, step.Error = fmt.Sprintf("%s: resolution failed", m.Name), err.Error()
+			RecordStep(ctx, step)
 			return License{}, fmt.Errorf("%w", err)
 		}
 	}
 
 	return License{}, ErrUnknownLicense
 }
+
+// ResolveLicenses resolves licenses of modules the same way as ResolveLicense does.
+// Each resolver receives all modules with still unknown license at once, so batch resolvers make less requests.
+func (crl *ChainedLicenseResolver) ResolveLicenses(ctx context.Context, modules []Module) []LicenseResult {
+	results := make([]LicenseResult, len(modules))
+	pending := make([]int, len(modules))
+	for i := range modules {
+		pending[i] = i
+	}
+
+	for _, resolver := range crl.LicenseResolvers {
+		if len(pending) == 0 {
+			break
+		}
+
+		batch := make([]Module, len(pending))
+		for i, idx := range pending {
+			batch[i] = modules[idx]
+		}
+
+		var next []int
+		for i, result := range ResolveLicenses(ctx, resolver, batch) {
+			switch idx := pending[i]; {
+			case errors.Is(result.Err, nil):
+				results[idx] = result
+			case errors.Is(result.Err, ErrUnknownLicense):
+				next = append(next, idx)
+			default:
+				results[idx].Err = fmt.Errorf("%w", result.Err)
+			}
+		}
+
+		pending = next
+	}
+
+	for _, idx := range pending {
+		results[idx].Err = ErrUnknownLicense
+	}
+
+	return results
+}
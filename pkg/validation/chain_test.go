@@ -133,3 +133,42 @@ func TestChainedLicenseResolver_ResolveLicense_error(t *testing.T) {
 
 	assert.True(t, errors.Is(err, testErr), "unexpected error", err)
 }
+
+func TestChainedLicenseResolver_ResolveLicenses(t *testing.T) {
+	t.Parallel()
+	var (
+		r1 validation.BatchLicenseResolverMock
+		r2 validation.LicenseResolverMock
+	)
+	defer r1.AssertExpectations(t)
+	defer r2.AssertExpectations(t)
+
+	resolved := validation.Module{Name: "resolved", Version: semver.MustParse("v1.0.0")}
+	next := validation.Module{Name: "next", Version: semver.MustParse("v1.0.0")}
+	failed := validation.Module{Name: "failed", Version: semver.MustParse("v1.0.0")}
+	unknown := validation.Module{Name: "unknown", Version: semver.MustParse("v1.0.0")}
+
+	mit := validation.License{Name: "MIT License", SPDXID: "MIT"}
+	testErr := fmt.Errorf("test-err")
+
+	r1.On("ResolveLicenses", mock.Anything, []validation.Module{resolved, next, failed, unknown}).Return([]validation.LicenseResult{
+		{License: mit},
+		{Err: validation.ErrUnknownLicense},
+		{Err: testErr},
+		{Err: validation.ErrUnknownLicense},
+	}).Once()
+	r2.On("ResolveLicense", mock.Anything, next).Return(mit, nil).Once()
+	r2.On("ResolveLicense", mock.Anything, unknown).Return(validation.License{}, validation.ErrUnknownLicense).Once()
+
+	results := (&validation.ChainedLicenseResolver{
+		LicenseResolvers: []validation.LicenseResolver{&r1, &r2},
+	}).ResolveLicenses(context.Background(), []validation.Module{resolved, next, failed, unknown})
+	if !assert.Len(t, results, 4) {
+		return
+	}
+
+	assert.Equal(t, validation.LicenseResult{License: mit}, results[0])
+	assert.Equal(t, validation.LicenseResult{License: mit}, results[1])
+	assert.True(t, errors.Is(results[2].Err, testErr), "unexpected error: %v", results[2].Err)
+	assert.Equal(t, validation.LicenseResult{Err: validation.ErrUnknownLicense}, results[3])
+}
@@ -0,0 +1,186 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// DenialReason is a machine-readable reason of module rejection
+type DenialReason string
+
+const (
+	// DenialBlacklisted means that module matched by one of RuleSet.BlacklistedModules
+	DenialBlacklisted DenialReason = "blacklisted"
+
+	// DenialDeniedLicense means that module license is denied by RuleSet
+	DenialDeniedLicense DenialReason = "denied_license"
+
+	// DenialUnknownLicense means that module license was not determined and such modules are denied
+	DenialUnknownLicense DenialReason = "unknown_license"
+)
+
+// Denial describes why module was rejected in a form suitable for showing to users
+type Denial struct {
+	Module  string       `json:"module"`
+	Version string       `json:"version,omitempty"`
+	License string       `json:"license,omitempty"`
+	Rule    string       `json:"rule,omitempty"`
+	Reason  DenialReason `json:"reason"`
+	Message string       `json:"message"`
+	HelpURL string       `json:"help_url,omitempty"`
+
+	// custom is set if message rendered from template, such messages mention HelpURL themselves
+	custom bool
+}
+
+// DenialFromError builds Denial for module from validation error.
+// It returns false if error is not a rule set rejection (i.e. some internal failure).
+func DenialFromError(m Module, err error) (Denial, bool) {
+	var (
+		blacklistErr     *ErrBlacklistedModule
+		deniedLicenseErr *ErrDeniedLicense
+	)
+
+	d := Denial{Module: m.Name}
+	if m.Version != nil {
+		d.Version = m.Version.Original()
+	}
+
+	switch {
+	case errors.As(err, &blacklistErr):
+		d.Reason = DenialBlacklisted
+		d.License = licenseID(&blacklistErr.Module.License)
+		d.Rule = fmt.Sprintf("blacklist: %s", matcherRule(&blacklistErr.Matcher))
+		d.Message = fmt.Sprintf("%s is blacklisted", d.moduleRef())
+	case errors.As(err, &deniedLicenseErr):
+		d.Reason = DenialDeniedLicense
+		d.License = licenseID(&deniedLicenseErr.Module.License)
+		if deniedLicenseErr.DeniedBy != nil {
+			d.Rule = fmt.Sprintf("denied_licenses: %s", licenseID(deniedLicenseErr.DeniedBy))
+		} else {
+			d.Rule = "allowed_licenses"
+		}
+		d.Message = fmt.Sprintf("%s is licensed under %s which is not allowed", d.moduleRef(), d.License)
+	case errors.Is(err, ErrUnknownLicense):
+		d.Reason = DenialUnknownLicense
+		d.Rule = "unknown_license_action: deny"
+		d.Message = fmt.Sprintf("license of %s can't be determined", d.moduleRef())
+	default:
+		return Denial{}, false
+	}
+
+	return d, true
+}
+
+func (d *Denial) moduleRef() string {
+	if d.Version == "" {
+		return d.Module
+	}
+
+	return d.Module + "@" + d.Version
+}
+
+// HelpNote returns reference to HelpURL shown after default message.
+// It's empty if there is no HelpURL or message rendered from custom template.
+func (d *Denial) HelpNote() string {
+	if d.HelpURL == "" || d.custom {
+		return ""
+	}
+
+	return fmt.Sprintf("See %s for details", d.HelpURL)
+}
+
+func licenseID(l *License) string {
+	if l.SPDXID != "" {
+		return l.SPDXID
+	}
+
+	return l.Name
+}
+
+func matcherRule(mm *ModuleMatcher) string {
+	if mm.Version == nil {
+		return mm.Name.String()
+	}
+
+	return fmt.Sprintf("%s %s", mm.Name, mm.Version)
+}
+
+// DenialMessages contains custom denial message templates by rejection reason.
+// Template execution context is Denial with default message in Message field.
+// HelpURL is not appended to rendered messages, templates should include it if needed.
+type DenialMessages map[DenialReason]*template.Template
+
+// Render replaces denial message with result of template execution.
+// Denial message stays untouched if there is no template for its reason.
+func (dm DenialMessages) Render(d *Denial) error {
+	tpl, ok := dm[d.Reason]
+	if !ok || tpl == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, d); err != nil {
+		return fmt.Errorf("%s message template execution failed: %w", d.Reason, err)
+	}
+
+	d.Message = strings.TrimSpace(buf.String())
+	d.custom = true
+	return nil
+}
+
+// WriteDenial writes denial to http response with provided status code.
+// Denial is written as JSON if client accepts it, otherwise short text message written.
+func WriteDenial(w http.ResponseWriter, r *http.Request, code int, d Denial) {
+	if acceptsJSON(r) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(d)
+		return
+	}
+
+	msg := d.Message
+	if note := d.HelpNote(); note != "" {
+		msg += "\n" + note
+	}
+
+	http.Error(w, msg, code)
+}
+
+// acceptsJSON checks if "application/json" is preferred over "text/plain" by Accept header
+func acceptsJSON(r *http.Request) bool {
+	var jsonQ, textQ float64 = -1, -1
+
+	for _, item := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qs, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mt {
+		case "application/json":
+			jsonQ = q
+		case "text/plain", "text/*":
+			if q > textQ {
+				textQ = q
+			}
+		}
+	}
+
+	return jsonQ > 0 && jsonQ >= textQ
+}
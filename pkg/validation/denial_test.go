@@ -0,0 +1,171 @@
+package validation_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDenialFromError(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name           string
+		Error          error
+		ExpectedDenial validation.Denial
+		ExpectedOk     bool
+	}
+
+	module := validation.Module{Name: "github.com/test/test", Version: semver.MustParse("v1.2.3")}
+	licensedModule := validation.LicensedModule{
+		Module:  module,
+		License: validation.License{Name: "GNU Affero General Public License v3.0", SPDXID: "AGPL-3.0"},
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			denial, ok := validation.DenialFromError(module, tc.Error)
+			assert.Equal(t, tc.ExpectedOk, ok)
+			assert.Equal(t, tc.ExpectedDenial, denial)
+		})
+	}
+
+	f(testCase{
+		Name: "blacklisted",
+		Error: fmt.Errorf("rule set validation failed: %w", &validation.ErrBlacklistedModule{
+			Module:  licensedModule,
+			Matcher: validation.ModuleMatcher{Name: regexp.MustCompile(`^github.com/test/.*$`), Version: MustParseConstraint("<2.0.0")},
+		}),
+		ExpectedDenial: validation.Denial{
+			Module:  "github.com/test/test",
+			Version: "v1.2.3",
+			License: "AGPL-3.0",
+			Rule:    "blacklist: ^github.com/test/.*$ <2.0.0",
+			Reason:  validation.DenialBlacklisted,
+			Message: "github.com/test/test@v1.2.3 is blacklisted",
+		},
+		ExpectedOk: true,
+	})
+
+	f(testCase{
+		Name: "denied license",
+		Error: fmt.Errorf("rule set validation failed: %w", &validation.ErrDeniedLicense{
+			Module:   licensedModule,
+			DeniedBy: &validation.License{SPDXID: "AGPL-3.0"},
+		}),
+		ExpectedDenial: validation.Denial{
+			Module:  "github.com/test/test",
+			Version: "v1.2.3",
+			License: "AGPL-3.0",
+			Rule:    "denied_licenses: AGPL-3.0",
+			Reason:  validation.DenialDeniedLicense,
+			Message: "github.com/test/test@v1.2.3 is licensed under AGPL-3.0 which is not allowed",
+		},
+		ExpectedOk: true,
+	})
+
+	f(testCase{
+		Name:  "unknown license",
+		Error: validation.ErrUnknownLicense,
+		ExpectedDenial: validation.Denial{
+			Module:  "github.com/test/test",
+			Version: "v1.2.3",
+			Rule:    "unknown_license_action: deny",
+			Reason:  validation.DenialUnknownLicense,
+			Message: "license of github.com/test/test@v1.2.3 can't be determined",
+		},
+		ExpectedOk: true,
+	})
+
+	f(testCase{
+		Name:       "internal error",
+		Error:      fmt.Errorf("test error"),
+		ExpectedOk: false,
+	})
+}
+
+func TestDenialMessages_Render(t *testing.T) {
+	t.Parallel()
+	messages := validation.DenialMessages{
+		validation.DenialDeniedLicense: template.Must(template.New("").Parse(
+			`{{ .Module }} uses {{ .License }} ({{ .Rule }}). Request an exception at {{ .HelpURL }}`,
+		)),
+	}
+
+	t.Run("template rendered", func(t *testing.T) {
+		denial := validation.Denial{
+			Module:  "github.com/test/test",
+			Version: "v1.2.3",
+			License: "AGPL-3.0",
+			Rule:    "denied_licenses: AGPL-3.0",
+			Reason:  validation.DenialDeniedLicense,
+			Message: "default",
+			HelpURL: "https://legal.example.com",
+		}
+
+		if assert.NoError(t, messages.Render(&denial)) {
+			assert.Equal(t,
+				"github.com/test/test uses AGPL-3.0 (denied_licenses: AGPL-3.0). Request an exception at https://legal.example.com",
+				denial.Message,
+			)
+		}
+	})
+
+	t.Run("no template for reason", func(t *testing.T) {
+		denial := validation.Denial{
+			Module:  "github.com/test/test",
+			Reason:  validation.DenialBlacklisted,
+			Message: "default",
+		}
+
+		if assert.NoError(t, messages.Render(&denial)) {
+			assert.Equal(t, "default", denial.Message)
+		}
+	})
+}
+
+func TestWriteDenial(t *testing.T) {
+	t.Parallel()
+
+	messages := validation.DenialMessages{
+		validation.DenialBlacklisted: template.Must(template.New("").Parse(`{{ .Module }} is forbidden, see {{ .HelpURL }}`)),
+	}
+
+	f := func(name string, d validation.Denial, expectedBody string) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.NoError(t, messages.Render(&d))
+
+			rec := httptest.NewRecorder()
+			validation.WriteDenial(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusForbidden, d)
+
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Equal(t, expectedBody, strings.TrimSuffix(rec.Body.String(), "\n"))
+		})
+	}
+
+	f("default message", validation.Denial{
+		Module:  "github.com/test/test",
+		Reason:  validation.DenialUnknownLicense,
+		Message: "license of github.com/test/test can't be determined",
+		HelpURL: "https://legal.example.com",
+	}, "license of github.com/test/test can't be determined\nSee https://legal.example.com for details")
+
+	// custom template mentions help url itself
+	f("custom message", validation.Denial{
+		Module:  "github.com/test/test",
+		Reason:  validation.DenialBlacklisted,
+		Message: "github.com/test/test is blacklisted",
+		HelpURL: "https://legal.example.com",
+	}, "github.com/test/test is forbidden, see https://legal.example.com")
+}
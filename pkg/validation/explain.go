@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Stage is a validation stage where explanation step was recorded
+type Stage string
+
+const (
+	StageTranslate Stage = "translate"
+	StageCache     Stage = "cache"
+	StageResolve   Stage = "resolve"
+	StageDetect    Stage = "detect"
+	StageRule      Stage = "rule"
+)
+
+// Outcome is a final validation result
+type Outcome string
+
+const (
+	OutcomeAllowed Outcome = "allowed"
+	OutcomeDenied  Outcome = "denied"
+	OutcomeError   Outcome = "error"
+)
+
+// Step is a single decision made during validation
+type Step struct {
+	Stage     Stage  `json:"stage"`
+	Component string `json:"component"`
+	Message   string `json:"message"`
+
+	// License is a license found on this step
+	License string `json:"license,omitempty"`
+
+	// Confidence is a license detector confidence, it's set only for detected licenses
+	Confidence float64 `json:"confidence,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Explanation contains all steps made during module validation and its outcome
+type Explanation struct {
+	Module  string  `json:"module"`
+	Version string  `json:"version"`
+	Steps   []Step  `json:"steps"`
+	Outcome Outcome `json:"outcome"`
+	Denial  *Denial `json:"denial,omitempty"`
+	Error   string  `json:"error,omitempty"`
+
+	mu sync.Mutex
+}
+
+func (e *Explanation) record(step Step) {
+	e.mu.Lock()
+	e.Steps = append(e.Steps, step)
+	e.mu.Unlock()
+}
+
+type explanationKey struct{}
+
+// WithExplanation returns context which makes validation components record their steps to explanation
+func WithExplanation(ctx context.Context, e *Explanation) context.Context {
+	return context.WithValue(ctx, explanationKey{}, e)
+}
+
+// ExplanationFromContext returns explanation attached to context or nil
+func ExplanationFromContext(ctx context.Context) *Explanation {
+	e, _ := ctx.Value(explanationKey{}).(*Explanation)
+	return e
+}
+
+// RecordStep adds step to explanation attached to context. It does nothing if there is no explanation.
+func RecordStep(ctx context.Context, step Step) {
+	if e := ExplanationFromContext(ctx); e != nil {
+		e.record(step)
+	}
+}
+
+// Named may be implemented by translators and license resolvers to be shown in explanations under readable name
+type Named interface {
+	Name() string
+}
+
+func componentName(component interface{}) string {
+	if named, ok := component.(Named); ok {
+		return named.Name()
+	}
+
+	return fmt.Sprintf("%T", component)
+}
+
+type ExplainerParams struct {
+	Validator Validator
+	HelpURL   string
+	Messages  DenialMessages
+}
+
+// Explainer validates modules recording all steps made by validation components
+type Explainer struct {
+	ExplainerParams
+
+	log *zap.Logger
+}
+
+func NewExplainer(log *zap.Logger, params ExplainerParams) *Explainer {
+	return &Explainer{
+		ExplainerParams: params,
+		log:             log.With(zap.String("component", "explainer")),
+	}
+}
+
+// Explain validates module and returns explanation of result
+func (e *Explainer) Explain(ctx context.Context, m Module) *Explanation {
+	ret := &Explanation{Module: m.Name, Steps: []Step{}}
+	if m.Version != nil {
+		ret.Version = m.Version.Original()
+	}
+
+	err := e.Validator.Validate(WithExplanation(ctx, ret), m)
+	if errors.Is(err, nil) {
+		ret.Outcome = OutcomeAllowed
+		return ret
+	}
+
+	denial, ok := DenialFromError(m, err)
+	if !ok {
+		ret.Outcome, ret.Error = OutcomeError, err.Error()
+		return ret
+	}
+
+	denial.HelpURL = e.HelpURL
+	if err := e.Messages.Render(&denial); err != nil {
+		e.log.Error("Denial message render failed", zap.Error(err))
+	}
+
+	ret.Outcome, ret.Denial = OutcomeDenied, &denial
+
+	return ret
+}
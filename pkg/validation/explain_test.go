@@ -0,0 +1,189 @@
+package validation_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestExplainer_Explain(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name               string
+		RuleSet            validation.RuleSet
+		UnknownLicense     bool
+		ResolveError       error
+		ExpectedSteps      []validation.Step
+		ExpectedOutcome    validation.Outcome
+		ExpectedDenialRule string
+		ExpectedError      string
+	}
+
+	module := validation.Module{Name: "rsc.io/pdf", Version: semver.MustParse("v0.1.1")}
+	translated := validation.Module{Name: "github.com/rsc/pdf", Version: semver.MustParse("v0.1.1")}
+
+	translateSteps := []validation.Step{
+		{Stage: validation.StageTranslate, Component: "*validation.TranslatorMock", Message: "rsc.io/pdf -> github.com/rsc/pdf"},
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				translatorMock validation.TranslatorMock
+				resolver1Mock  validation.LicenseResolverMock
+				resolver2Mock  validation.LicenseResolverMock
+			)
+			defer translatorMock.AssertExpectations(t)
+			defer resolver1Mock.AssertExpectations(t)
+			defer resolver2Mock.AssertExpectations(t)
+
+			translatorMock.On("Translate", mock.Anything, module).Return(translated, nil).Once()
+			resolver1Mock.On("ResolveLicense", mock.Anything, translated).
+				Return(validation.License{}, validation.ErrUnknownLicense).Once()
+
+			switch {
+			case tc.ResolveError != nil:
+				resolver2Mock.On("ResolveLicense", mock.Anything, translated).
+					Return(validation.License{}, tc.ResolveError).Once()
+			case tc.UnknownLicense:
+				resolver2Mock.On("ResolveLicense", mock.Anything, mock.Anything).
+					Return(validation.License{}, validation.ErrUnknownLicense).Twice()
+				resolver1Mock.On("ResolveLicense", mock.Anything, module).
+					Return(validation.License{}, validation.ErrUnknownLicense).Once()
+			default:
+				resolver2Mock.On("ResolveLicense", mock.Anything, translated).
+					Return(validation.License{Name: "BSD 3-Clause \"New\" or \"Revised\" License", SPDXID: "BSD-3-Clause"}, nil).Once()
+			}
+
+			log := zaptest.NewLogger(t)
+
+			explanation := validation.NewExplainer(log, validation.ExplainerParams{
+				Validator: validation.NewNotifyingValidator(log, validation.NotifyingValidatorParams{
+					Validator: validation.NewRuleSetValidator(log, validation.RuleSetValidatorParams{
+						Translator: &validation.ChainedTranslator{Translators: []validation.Translator{&translatorMock}},
+						LicenseResolver: &validation.ChainedLicenseResolver{
+							LicenseResolvers: []validation.LicenseResolver{&resolver1Mock, &resolver2Mock},
+						},
+						RuleSet: tc.RuleSet,
+					}),
+					UnknownLicenseAction: validation.UnknownLicenseDeny,
+				}),
+			}).Explain(context.Background(), module)
+
+			assert.Equal(t, "rsc.io/pdf", explanation.Module)
+			assert.Equal(t, "v0.1.1", explanation.Version)
+			assert.Equal(t, tc.ExpectedSteps, explanation.Steps)
+			assert.Equal(t, tc.ExpectedOutcome, explanation.Outcome)
+			assert.Equal(t, tc.ExpectedError, explanation.Error)
+			if tc.ExpectedDenialRule == "" {
+				assert.Nil(t, explanation.Denial)
+			} else if assert.NotNil(t, explanation.Denial) {
+				assert.Equal(t, tc.ExpectedDenialRule, explanation.Denial.Rule)
+			}
+		})
+	}
+
+	resolveSteps := append(translateSteps,
+		validation.Step{
+			Stage:     validation.StageResolve,
+			Component: "*validation.LicenseResolverMock",
+			Message:   "github.com/rsc/pdf: license unknown, trying next resolver",
+		},
+		validation.Step{
+			Stage:     validation.StageResolve,
+			Component: "*validation.LicenseResolverMock",
+			Message:   "github.com/rsc/pdf: license resolved",
+			License:   "BSD-3-Clause",
+		},
+	)
+
+	f(testCase{
+		Name: "allowed",
+		RuleSet: validation.RuleSet{
+			WhitelistedModules: []validation.ModuleMatcher{{Name: regexp.MustCompile(`^golang.org/x/.*$`)}},
+			DeniedLicenses:     []validation.License{{SPDXID: "GPL-3.0"}},
+		},
+		ExpectedSteps: append(resolveSteps[:len(resolveSteps):len(resolveSteps)],
+			validation.Step{Stage: validation.StageRule, Component: "whitelist", Message: "^golang.org/x/.*$: not matched"},
+			validation.Step{Stage: validation.StageRule, Component: "denied_licenses", Message: "GPL-3.0: not matched"},
+			validation.Step{Stage: validation.StageRule, Component: "rule_set", Message: "no rules denied module, module allowed"},
+		),
+		ExpectedOutcome: validation.OutcomeAllowed,
+	})
+
+	f(testCase{
+		Name: "not in allowed licenses",
+		RuleSet: validation.RuleSet{
+			AllowedLicenses: []validation.License{{SPDXID: "MIT"}},
+		},
+		ExpectedSteps: append(resolveSteps[:len(resolveSteps):len(resolveSteps)],
+			validation.Step{Stage: validation.StageRule, Component: "allowed_licenses", Message: "MIT: not matched"},
+			validation.Step{Stage: validation.StageRule, Component: "allowed_licenses", Message: "license BSD-3-Clause is not allowed, module denied"},
+		),
+		ExpectedOutcome:    validation.OutcomeDenied,
+		ExpectedDenialRule: "allowed_licenses",
+	})
+
+	f(testCase{
+		Name:           "unknown license",
+		UnknownLicense: true,
+		ExpectedSteps: append(translateSteps[:len(translateSteps):len(translateSteps)],
+			validation.Step{
+				Stage:     validation.StageResolve,
+				Component: "*validation.LicenseResolverMock",
+				Message:   "github.com/rsc/pdf: license unknown, trying next resolver",
+			},
+			validation.Step{
+				Stage:     validation.StageResolve,
+				Component: "*validation.LicenseResolverMock",
+				Message:   "github.com/rsc/pdf: license unknown, trying next resolver",
+			},
+			validation.Step{
+				Stage:     validation.StageResolve,
+				Component: "ruleset_validator",
+				Message:   "github.com/rsc/pdf: license unknown, trying original module rsc.io/pdf",
+			},
+			validation.Step{
+				Stage:     validation.StageResolve,
+				Component: "*validation.LicenseResolverMock",
+				Message:   "rsc.io/pdf: license unknown, trying next resolver",
+			},
+			validation.Step{
+				Stage:     validation.StageResolve,
+				Component: "*validation.LicenseResolverMock",
+				Message:   "rsc.io/pdf: license unknown, trying next resolver",
+			},
+			validation.Step{Stage: validation.StageRule, Component: "unknown_license_action", Message: "license unknown, action deny"},
+		),
+		ExpectedOutcome:    validation.OutcomeDenied,
+		ExpectedDenialRule: "unknown_license_action: deny",
+	})
+
+	f(testCase{
+		Name:         "resolver error",
+		ResolveError: fmt.Errorf("connection refused"),
+		ExpectedSteps: append(translateSteps[:len(translateSteps):len(translateSteps)],
+			validation.Step{
+				Stage:     validation.StageResolve,
+				Component: "*validation.LicenseResolverMock",
+				Message:   "github.com/rsc/pdf: license unknown, trying next resolver",
+			},
+			validation.Step{
+				Stage:     validation.StageResolve,
+				Component: "*validation.LicenseResolverMock",
+				Message:   "github.com/rsc/pdf: resolution failed",
+				Error:     "connection refused",
+			},
+		),
+		ExpectedOutcome: validation.OutcomeError,
+		ExpectedError:   "license resolution failed: connection refused",
+	})
+}
@@ -0,0 +1,143 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsLicenseExpression reports if SPDX id is a compound license expression (i.e. "MIT OR Apache-2.0")
+func IsLicenseExpression(id string) bool {
+	return strings.ContainsAny(id, " ()")
+}
+
+// ParseLicenseExpression parses SPDX license expression to disjunctive normal form:
+// list of alternatives each of which is a list of licenses applied together.
+// License exceptions ("WITH" operator) are dropped. Operators are case-insensitive.
+func ParseLicenseExpression(expr string) ([][]string, error) {
+	p := expressionParser{tokens: tokenizeExpression(expr)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+
+	ret, err := p.or()
+	if err != nil {
+		return nil, fmt.Errorf("license expression %q: %w", expr, err)
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("license expression %q: unexpected %q", expr, p.tokens[p.pos])
+	}
+
+	return ret, nil
+}
+
+func tokenizeExpression(expr string) []string {
+	expr = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr)
+	return strings.Fields(expr)
+}
+
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) isOperator(token, operator string) bool {
+	return strings.EqualFold(token, operator)
+}
+
+func (p *expressionParser) or() ([][]string, error) {
+	ret, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator(p.peek(), "OR") {
+		p.pos++
+
+		alternatives, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, alternatives...)
+	}
+
+	return ret, nil
+}
+
+func (p *expressionParser) and() ([][]string, error) {
+	ret, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator(p.peek(), "AND") {
+		p.pos++
+
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+
+		// (a OR b) AND (c OR d) = (a AND c) OR (a AND d) OR (b AND c) OR (b AND d)
+		product := make([][]string, 0, len(ret)*len(right))
+		for _, l := range ret {
+			for _, r := range right {
+				item := make([]string, 0, len(l)+len(r))
+				item = append(append(item, l...), r...)
+				product = append(product, item)
+			}
+		}
+
+		ret = product
+	}
+
+	return ret, nil
+}
+
+func (p *expressionParser) primary() ([][]string, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end")
+	case token == "(":
+		p.pos++
+
+		ret, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+
+		p.pos++
+		return ret, nil
+	case token == ")", p.isOperator(token, "AND"), p.isOperator(token, "OR"), p.isOperator(token, "WITH"):
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+
+	p.pos++
+
+	if p.isOperator(p.peek(), "WITH") {
+		p.pos++
+
+		exception := p.peek()
+		if exception == "" || exception == "(" || exception == ")" {
+			return nil, fmt.Errorf("missing license exception")
+		}
+
+		p.pos++
+	}
+
+	return [][]string{{token}}, nil
+}
@@ -0,0 +1,46 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Expression string
+		Expected   [][]string
+		Error      bool
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.Expression, func(t *testing.T) {
+			alternatives, err := validation.ParseLicenseExpression(tc.Expression)
+			if tc.Error {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.Expected, alternatives)
+			}
+		})
+	}
+
+	f(testCase{Expression: "MIT", Expected: [][]string{{"MIT"}}})
+	f(testCase{Expression: "MIT OR Apache-2.0", Expected: [][]string{{"MIT"}, {"Apache-2.0"}}})
+	f(testCase{Expression: "MIT and BSD-3-Clause", Expected: [][]string{{"MIT", "BSD-3-Clause"}}})
+	f(testCase{
+		Expression: "(MIT OR Apache-2.0) AND (BSD-2-Clause OR ISC)",
+		Expected:   [][]string{{"MIT", "BSD-2-Clause"}, {"MIT", "ISC"}, {"Apache-2.0", "BSD-2-Clause"}, {"Apache-2.0", "ISC"}},
+	})
+	f(testCase{Expression: "MIT OR Apache-2.0 AND ISC", Expected: [][]string{{"MIT"}, {"Apache-2.0", "ISC"}}})
+	f(testCase{Expression: "GPL-2.0 WITH Classpath-exception-2.0", Expected: [][]string{{"GPL-2.0"}}})
+	f(testCase{Expression: "", Error: true})
+	f(testCase{Expression: "(MIT OR Apache-2.0", Error: true})
+	f(testCase{Expression: "MIT OR", Error: true})
+	f(testCase{Expression: "MIT Apache-2.0", Error: true})
+}
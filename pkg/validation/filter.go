@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Masterminds/semver/v3"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultFilterConcurrency is a default number of simultaneous validations performed by FilterVersions
+const DefaultFilterConcurrency = 4
+
+// FilterVersions validates module versions and returns allowed ones keeping original order.
+// Versions rejected by rules (see DenialFromError) are dropped, other validation errors abort filtering.
+// Concurrency limits number of simultaneous validations, DefaultFilterConcurrency used if it's not positive.
+// Listing is not a download, so unknown licenses are not notified about (see WithoutNotifications).
+func FilterVersions(
+	ctx context.Context,
+	validator Validator,
+	name string,
+	versions []*semver.Version,
+	concurrency int,
+) ([]*semver.Version, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultFilterConcurrency
+	}
+
+	allowed := make([]bool, len(versions))
+	sem := make(chan struct{}, concurrency)
+	eg, egCtx := errgroup.WithContext(WithoutNotifications(ctx))
+
+loop:
+	for i, version := range versions {
+		i, m := i, Module{Name: name, Version: version}
+
+		select {
+		case sem <- struct{}{}:
+		case <-egCtx.Done():
+			break loop
+		}
+
+		eg.Go(func() error {
+			defer func() { <-sem }()
+
+			err := validator.Validate(egCtx, m)
+			if errors.Is(err, nil) {
+				allowed[i] = true
+				return nil
+			}
+
+			if _, denied := DenialFromError(m, err); denied {
+				return nil
+			}
+
+			return err
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*semver.Version, 0, len(versions))
+	for i, version := range versions {
+		if allowed[i] {
+			ret = append(ret, version)
+		}
+	}
+
+	return ret, nil
+}
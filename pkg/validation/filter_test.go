@@ -0,0 +1,82 @@
+package validation_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFilterVersions(t *testing.T) {
+	t.Parallel()
+
+	versions := []*semver.Version{
+		semver.MustParse("v1.0.0"),
+		semver.MustParse("v1.1.0"),
+		semver.MustParse("v1.2.0"),
+		semver.MustParse("v2.0.0"),
+	}
+
+	module := func(version string) validation.Module {
+		return validation.Module{Name: "test-mod", Version: semver.MustParse(version)}
+	}
+
+	t.Run("denied versions dropped", func(t *testing.T) {
+		var validatorMock validation.ValidatorMock
+		defer validatorMock.AssertExpectations(t)
+
+		validatorMock.On("Validate", mock.Anything, module("v1.0.0")).Return(nil).Once()
+		validatorMock.On("Validate", mock.Anything, module("v1.1.0")).
+			Return(&validation.ErrBlacklistedModule{
+				Module:  validation.LicensedModule{Module: module("v1.1.0")},
+				Matcher: validation.ModuleMatcher{Name: regexp.MustCompile(`^test-mod$`)},
+			}).Once()
+		validatorMock.On("Validate", mock.Anything, module("v1.2.0")).Return(validation.ErrUnknownLicense).Once()
+		validatorMock.On("Validate", mock.Anything, module("v2.0.0")).Return(nil).Once()
+
+		allowed, err := validation.FilterVersions(context.Background(), &validatorMock, "test-mod", versions, 2)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []*semver.Version{versions[0], versions[3]}, allowed)
+		}
+	})
+
+	t.Run("unknown license not notified", func(t *testing.T) {
+		var (
+			validatorMock validation.ValidatorMock
+			notifierMock  validation.UnknownLicenseNotifierMock
+		)
+		defer validatorMock.AssertExpectations(t)
+		defer notifierMock.AssertExpectations(t)
+
+		validatorMock.On("Validate", mock.Anything, mock.Anything).Return(validation.ErrUnknownLicense)
+
+		validator := validation.NewNotifyingValidator(zaptest.NewLogger(t), validation.NotifyingValidatorParams{
+			Validator:              &validatorMock,
+			UnknownLicenseAction:   validation.UnknownLicenseWarn,
+			UnknownLicenseNotifier: &notifierMock,
+		})
+
+		allowed, err := validation.FilterVersions(context.Background(), validator, "test-mod", versions, 2)
+		if assert.NoError(t, err) {
+			assert.Equal(t, versions, allowed)
+		}
+
+		notifierMock.AssertNotCalled(t, "NotifyUnknownLicense", mock.Anything, mock.Anything)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		var validatorMock validation.ValidatorMock
+
+		validatorMock.On("Validate", mock.Anything, mock.Anything).Return(fmt.Errorf("test error"))
+
+		_, err := validation.FilterVersions(context.Background(), &validatorMock, "test-mod", versions, 0)
+		assert.EqualError(t, err, "test error")
+	})
+}
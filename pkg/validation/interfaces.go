@@ -3,6 +3,7 @@ package validation
 import (
 	"context"
 	"fmt"
+	"sync"
 )
 
 var ErrUnknownLicense = fmt.Errorf("unknown license")
@@ -20,6 +21,76 @@ type LicenseResolver interface {
 	ResolveLicense(ctx context.Context, m Module) (License, error)
 }
 
+// LicenseResult is a license resolution result of single module
+type LicenseResult struct {
+	License License
+	Err     error
+}
+
+type BatchLicenseResolver interface {
+	// ResolveLicenses resolves licenses of many modules at once (i.e. with batched requests to remote service).
+	// Results have the same order as modules, each result has the same meaning as ResolveLicense return values.
+	ResolveLicenses(ctx context.Context, modules []Module) []LicenseResult
+}
+
+// ResolveLicenses resolves licenses of modules with resolver at once if it implements BatchLicenseResolver
+// or one by one otherwise. Modules are resolved one by one with number of simultaneous calls
+// set by WithResolveConcurrency (one by default).
+func ResolveLicenses(ctx context.Context, resolver LicenseResolver, modules []Module) []LicenseResult {
+	if batchResolver, ok := resolver.(BatchLicenseResolver); ok {
+		return batchResolver.ResolveLicenses(ctx, modules)
+	}
+
+	results := make([]LicenseResult, len(modules))
+	parallel(ctx, len(modules), func(i int) {
+		results[i].License, results[i].Err = resolver.ResolveLicense(ctx, modules[i])
+	})
+
+	return results
+}
+
+type resolveConcurrencyKey struct{}
+
+// WithResolveConcurrency returns context which makes ResolveLicenses call resolvers not supporting batching
+// for n modules simultaneously
+func WithResolveConcurrency(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, resolveConcurrencyKey{}, n)
+}
+
+// parallel calls fn for indexes in [0, count) with concurrency set by WithResolveConcurrency and waits for all calls
+func parallel(ctx context.Context, count int, fn func(i int)) {
+	workers, _ := ctx.Value(resolveConcurrencyKey{}).(int)
+	if workers <= 0 {
+		workers = 1
+	}
+
+	if workers > count {
+		workers = count
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for idx := range jobs {
+				fn(idx)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+}
+
 type UnknownLicenseNotifier interface {
 	// NotifyUnknownLicense triggered if unknown license found and UnknownLicenseWarn is set
 	NotifyUnknownLicense(ctx context.Context, m Module) error
@@ -32,6 +32,14 @@ func (m *LicenseResolverMock) ResolveLicense(ctx context.Context, module Module)
 	return args.Get(0).(License), args.Error(1)
 }
 
+type BatchLicenseResolverMock struct {
+	LicenseResolverMock
+}
+
+func (m *BatchLicenseResolverMock) ResolveLicenses(ctx context.Context, modules []Module) []LicenseResult {
+	return m.Called(ctx, modules).Get(0).([]LicenseResult)
+}
+
 type UnknownLicenseNotifierMock struct {
 	mock.Mock
 }
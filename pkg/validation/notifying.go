@@ -28,6 +28,14 @@ func NewNotifyingValidator(log *zap.Logger, params NotifyingValidatorParams) *No
 	}
 }
 
+type silentKey struct{}
+
+// WithoutNotifications returns context which makes NotifyingValidator apply unknown license action without notifying.
+// It's used for speculative validations (i.e. filtering of version lists) which are not actual module downloads.
+func WithoutNotifications(ctx context.Context) context.Context {
+	return context.WithValue(ctx, silentKey{}, true)
+}
+
 func (v *NotifyingValidator) Validate(ctx context.Context, m Module) error {
 	err := v.Validator.Validate(ctx, m)
 	switch {
@@ -42,12 +50,22 @@ func (v *NotifyingValidator) Validate(ctx context.Context, m Module) error {
 
 func (v *NotifyingValidator) onUnknownLicense(ctx context.Context, m Module) error {
 	l := v.log.With(zap.Stringer("module", &m))
+	RecordStep(ctx, Step{
+		Stage:     StageRule,
+		Component: "unknown_license_action",
+		Message:   fmt.Sprintf("license unknown, action %s", v.UnknownLicenseAction),
+	})
 
 	switch v.UnknownLicenseAction {
 	case UnknownLicenseAllow:
 		l.Debug("Allowing unknown license")
 		return nil
 	case UnknownLicenseWarn:
+		if silent, _ := ctx.Value(silentKey{}).(bool); silent {
+			l.Debug("Allowing unknown license without notification")
+			return nil
+		}
+
 		l.Info("Notifying about unknown license")
 		if err := v.UnknownLicenseNotifier.NotifyUnknownLicense(ctx, m); err != nil {
 			l.Error("Notifying about unknown license failed", zap.Error(err))
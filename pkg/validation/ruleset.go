@@ -1,8 +1,10 @@
 package validation
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 )
@@ -53,37 +55,117 @@ type RuleSet struct {
 
 // Validate validates provided module against rule set
 func (rs *RuleSet) Validate(lm LicensedModule) error {
-	for _, wm := range rs.WhitelistedModules {
+	return rs.ValidateContext(context.Background(), lm)
+}
+
+// ValidateContext acts as Validate but records every evaluated rule to explanation attached to context
+func (rs *RuleSet) ValidateContext(ctx context.Context, lm LicensedModule) error {
+	for i := range rs.WhitelistedModules {
+		wm := &rs.WhitelistedModules[i]
 		if wm.Match(&lm.Module) {
+			recordRule(ctx, "whitelist", matcherRule(wm), "matched, module allowed")
 			return nil
 		}
+
+		recordRule(ctx, "whitelist", matcherRule(wm), "not matched")
 	}
 
-	for i, bm := range rs.BlacklistedModules {
+	for i := range rs.BlacklistedModules {
+		bm := &rs.BlacklistedModules[i]
 		if bm.Match(&lm.Module) {
-			return &ErrBlacklistedModule{Module: lm, Matcher: rs.BlacklistedModules[i]}
+			recordRule(ctx, "blacklist", matcherRule(bm), "matched, module denied")
+			return &ErrBlacklistedModule{Module: lm, Matcher: *bm}
+		}
+
+		recordRule(ctx, "blacklist", matcherRule(bm), "not matched")
+	}
+
+	if IsLicenseExpression(lm.License.SPDXID) {
+		return rs.validateExpression(ctx, lm)
+	}
+
+	return rs.validateLicense(ctx, lm)
+}
+
+// validateExpression validates compound license expression.
+// Module is allowed if all licenses of any expression alternative are allowed.
+func (rs *RuleSet) validateExpression(ctx context.Context, lm LicensedModule) error {
+	alternatives, err := ParseLicenseExpression(lm.License.SPDXID)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, alternative := range alternatives {
+		RecordStep(ctx, Step{
+			Stage:     StageRule,
+			Component: "license_expression",
+			Message:   fmt.Sprintf("checking alternative %s", strings.Join(alternative, " AND ")),
+		})
+
+		var err error
+		for _, id := range alternative {
+			if err = rs.validateLicense(ctx, LicensedModule{Module: lm.Module, License: License{SPDXID: id}}); err != nil {
+				break
+			}
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
 		}
 	}
 
-	for _, al := range rs.AllowedLicenses {
+	// report whole expression in denial
+	if denied, ok := firstErr.(*ErrDeniedLicense); ok {
+		denied.Module = lm
+	}
+
+	return firstErr
+}
+
+func (rs *RuleSet) validateLicense(ctx context.Context, lm LicensedModule) error {
+	for i := range rs.AllowedLicenses {
+		al := &rs.AllowedLicenses[i]
 		if al.Equals(&lm.License) {
+			recordRule(ctx, "allowed_licenses", licenseID(al), "matched, module allowed")
 			return nil
 		}
+
+		recordRule(ctx, "allowed_licenses", licenseID(al), "not matched")
 	}
 
 	if len(rs.AllowedLicenses) > 0 {
+		RecordStep(ctx, Step{
+			Stage:     StageRule,
+			Component: "allowed_licenses",
+			Message:   fmt.Sprintf("license %s is not allowed, module denied", licenseID(&lm.License)),
+		})
 		return &ErrDeniedLicense{Module: lm}
 	}
 
-	for _, dl := range rs.DeniedLicenses {
+	for i := range rs.DeniedLicenses {
+		dl := &rs.DeniedLicenses[i]
 		if dl.Equals(&lm.License) {
-			return &ErrDeniedLicense{Module: lm}
+			recordRule(ctx, "denied_licenses", licenseID(dl), "matched, module denied")
+			return &ErrDeniedLicense{Module: lm, DeniedBy: dl}
 		}
+
+		recordRule(ctx, "denied_licenses", licenseID(dl), "not matched")
 	}
 
+	RecordStep(ctx, Step{Stage: StageRule, Component: "rule_set", Message: "no rules denied module, module allowed"})
+
 	return nil
 }
 
+func recordRule(ctx context.Context, component, rule, result string) {
+	RecordStep(ctx, Step{Stage: StageRule, Component: component, Message: fmt.Sprintf("%s: %s", rule, result)})
+}
+
 type ErrBlacklistedModule struct {
 	Module  LicensedModule
 	Matcher ModuleMatcher
@@ -95,6 +177,10 @@ func (e *ErrBlacklistedModule) Error() string {
 
 type ErrDeniedLicense struct {
 	Module LicensedModule
+
+	// DeniedBy is a matched entry of DeniedLicenses.
+	// It's nil when module license is not in AllowedLicenses.
+	DeniedBy *License
 }
 
 func (e *ErrDeniedLicense) Error() string {
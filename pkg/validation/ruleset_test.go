@@ -118,6 +118,7 @@ func TestRuleSet_Validate(t *testing.T) {
 				Module:  validation.Module{Name: "github.com/stretchr/testify", Version: semver.MustParse("v1.2.3")},
 				License: validation.License{Name: "MIT License", SPDXID: "MIT"},
 			},
+			DeniedBy: &validation.License{Name: "MIT License", SPDXID: "MIT"},
 		},
 	})
 
@@ -179,3 +180,65 @@ func TestModuleMatcher_Match(t *testing.T) {
 		ExpectedMatch: false,
 	})
 }
+
+func TestRuleSet_Validate_Expression(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		Name          string
+		License       validation.License
+		RuleSet       validation.RuleSet
+		ExpectedError error
+	}
+
+	mod := validation.Module{Name: "github.com/stretchr/testify", Version: semver.MustParse("v1.2.3")}
+
+	f := func(tc testCase) {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.RuleSet.Validate(validation.LicensedModule{Module: mod, License: tc.License})
+			if tc.ExpectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tc.ExpectedError, err)
+			}
+		})
+	}
+
+	f(testCase{
+		Name:    "one of alternatives allowed",
+		License: validation.License{SPDXID: "GPL-3.0 OR MIT"},
+		RuleSet: validation.RuleSet{
+			AllowedLicenses: []validation.License{{SPDXID: "MIT"}},
+		},
+	})
+
+	f(testCase{
+		Name:    "conjunction partially allowed",
+		License: validation.License{SPDXID: "MIT AND GPL-3.0"},
+		RuleSet: validation.RuleSet{
+			AllowedLicenses: []validation.License{{SPDXID: "MIT"}},
+		},
+		ExpectedError: &validation.ErrDeniedLicense{
+			Module: validation.LicensedModule{Module: mod, License: validation.License{SPDXID: "MIT AND GPL-3.0"}},
+		},
+	})
+
+	f(testCase{
+		Name:    "all alternatives denied",
+		License: validation.License{SPDXID: "(GPL-3.0 OR AGPL-3.0) AND MIT"},
+		RuleSet: validation.RuleSet{
+			DeniedLicenses: []validation.License{{SPDXID: "GPL-3.0"}, {SPDXID: "AGPL-3.0"}},
+		},
+		ExpectedError: &validation.ErrDeniedLicense{
+			Module:   validation.LicensedModule{Module: mod, License: validation.License{SPDXID: "(GPL-3.0 OR AGPL-3.0) AND MIT"}},
+			DeniedBy: &validation.License{SPDXID: "GPL-3.0"},
+		},
+	})
+
+	f(testCase{
+		Name:    "exception ignored",
+		License: validation.License{SPDXID: "GPL-2.0 WITH Classpath-exception-2.0"},
+		RuleSet: validation.RuleSet{
+			AllowedLicenses: []validation.License{{SPDXID: "GPL-2.0"}},
+		},
+	})
+}
@@ -33,6 +33,19 @@ const (
 	UnknownLicenseDeny
 )
 
+func (a UnknownLicenseAction) String() string {
+	switch a {
+	case UnknownLicenseAllow:
+		return "allow"
+	case UnknownLicenseWarn:
+		return "warn"
+	case UnknownLicenseDeny:
+		return "deny"
+	default:
+		return fmt.Sprintf("UnknownLicenseAction(%d)", int(a))
+	}
+}
+
 type License struct {
 	// Name is a human-readable name
 	Name string
@@ -28,14 +28,31 @@ func NewRuleSetValidator(logger *zap.Logger, validatorParams RuleSetValidatorPar
 }
 
 func (v *RuleSetValidator) Validate(ctx context.Context, m Module) error {
+	v.log.Info("Validating module", zap.Stringer("module", &m))
+
+	lic, err := v.ResolveLicense(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	err = v.RuleSet.ValidateContext(ctx, LicensedModule{Module: m, License: lic})
+	if err != nil {
+		return fmt.Errorf("rule set validation failed: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveLicense resolves module license the same way as Validate does it:
+// license is resolved by translated module with fallback to original module.
+func (v *RuleSetValidator) ResolveLicense(ctx context.Context, m Module) (License, error) {
 	l := v.log.With(zap.Stringer("module", &m))
-	l.Info("Validating module")
 
 	// firstly we try resolve license by translated module to minimize resolving using slow methods
 
 	translated, err := v.Translator.Translate(ctx, m)
 	if err != nil {
-		return fmt.Errorf("translation failed: %w", err)
+		return License{}, fmt.Errorf("translation failed: %w", err)
 	}
 
 	l = l.With(zap.Stringer("translated", &translated))
@@ -44,28 +61,71 @@ func (v *RuleSetValidator) Validate(ctx context.Context, m Module) error {
 	lic, err := v.LicenseResolver.ResolveLicense(ctx, translated)
 	switch {
 	case errors.Is(err, nil):
-		// pass
+		return lic, nil
 	case errors.Is(err, ErrUnknownLicense):
 		if m.Name == translated.Name {
 			l.Warn("Module has unknown license and translation didn't happen")
-			return ErrUnknownLicense
+			return License{}, ErrUnknownLicense
 		}
 
 		l.Info("Translated module license not resolved. Trying to resolve license for original module")
-		lic, err = v.tryOriginalModule(ctx, m)
+		RecordStep(ctx, Step{
+			Stage:     StageResolve,
+			Component: "ruleset_validator",
+			Message:   fmt.Sprintf("%s: license unknown, trying original module %s", translated.Name, m.Name),
+		})
+		return v.tryOriginalModule(ctx, m)
+	default:
+		return License{}, fmt.Errorf("license resolution failed: %w", err)
+	}
+}
+
+// ResolveLicenses resolves licenses of modules the same way as ResolveLicense does it.
+// Translated modules are passed to license resolver at once, so batch resolvers make less requests.
+func (v *RuleSetValidator) ResolveLicenses(ctx context.Context, modules []Module) []LicenseResult {
+	var (
+		results    = make([]LicenseResult, len(modules))
+		translated = make([]Module, 0, len(modules))
+		indexes    = make([]int, 0, len(modules))
+	)
+
+	for i, m := range modules {
+		t, err := v.Translator.Translate(ctx, m)
 		if err != nil {
-			return err
+			results[i].Err = fmt.Errorf("translation failed: %w", err)
+			continue
 		}
-	default:
-		return fmt.Errorf("license resolution failed: %w", err)
+
+		translated = append(translated, t)
+		indexes = append(indexes, i)
 	}
 
-	err = v.RuleSet.Validate(LicensedModule{Module: m, License: lic})
-	if err != nil {
-		return fmt.Errorf("rule set validation failed: %w", err)
+	v.log.Debug("Resolving licenses", zap.Int("modules", len(translated)))
+
+	var original []int
+	for i, result := range ResolveLicenses(ctx, v.LicenseResolver, translated) {
+		idx := indexes[i]
+		m := modules[idx]
+
+		switch {
+		case errors.Is(result.Err, nil):
+			results[idx] = result
+		case errors.Is(result.Err, ErrUnknownLicense) && m.Name == translated[i].Name:
+			v.log.Warn("Module has unknown license and translation didn't happen", zap.Stringer("module", &m))
+			results[idx].Err = ErrUnknownLicense
+		case errors.Is(result.Err, ErrUnknownLicense):
+			original = append(original, idx)
+		default:
+			results[idx].Err = fmt.Errorf("license resolution failed: %w", result.Err)
+		}
 	}
 
-	return nil
+	parallel(ctx, len(original), func(i int) {
+		idx := original[i]
+		results[idx].License, results[idx].Err = v.tryOriginalModule(ctx, modules[idx])
+	})
+
+	return results
 }
 
 func (v *RuleSetValidator) tryOriginalModule(ctx context.Context, original Module) (License, error) {
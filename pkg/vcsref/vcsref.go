@@ -0,0 +1,102 @@
+// Package vcsref maps go module versions to version control system references (tags and commits)
+// and module paths to directories inside repository.
+package vcsref
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// pseudoVersionRe matches pseudo-versions (i.e. v0.0.0-20200601120000-abcdef123456)
+var pseudoVersionRe = regexp.MustCompile(`^v[0-9]+\.(0\.0-|\d+\.\d+-([^+]*\.)?0\.)\d{14}-[A-Za-z0-9]+(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+// majorSuffixRe matches major version suffix of module path (i.e. /v2)
+var majorSuffixRe = regexp.MustCompile(`^v([2-9]|[1-9][0-9]+)$`)
+
+// Ref is a reference to module version in repository
+type Ref struct {
+	// Tag is a tag name, it's empty for pseudo-versions
+	Tag string
+
+	// Commit is an abbreviated commit hash, it's set only for pseudo-versions
+	Commit string
+}
+
+// String returns tag name or commit hash
+func (r Ref) String() string {
+	if r.Tag != "" {
+		return r.Tag
+	}
+
+	return r.Commit
+}
+
+// IsPseudoVersion reports if version is a pseudo-version
+func IsPseudoVersion(version string) bool {
+	return pseudoVersionRe.MatchString(version)
+}
+
+// FromVersion returns reference for module version. tagPrefix is a directory of module inside repository
+// without major version suffix (see Locate). "+incompatible" build metadata is not a part of tag.
+func FromVersion(tagPrefix string, v *semver.Version) Ref {
+	original := v.Original()
+	if original == "" {
+		original = "v" + v.String()
+	}
+
+	if IsPseudoVersion(original) {
+		rev := strings.TrimSuffix(original, "+"+v.Metadata())
+		return Ref{Commit: rev[strings.LastIndex(rev, "-")+1:]}
+	}
+
+	tag := strings.TrimSuffix(original, "+incompatible")
+	if tagPrefix != "" {
+		tag = tagPrefix + "/" + tag
+	}
+
+	return Ref{Tag: tag}
+}
+
+// Location describes where module is stored inside repository
+type Location struct {
+	// Dirs contains candidate module directories relative to repository root in order of preference.
+	// Module with major version suffix may be stored in directory with such suffix or without it (major branch convention).
+	// Empty string means repository root.
+	Dirs []string
+
+	// TagPrefix is a prefix of module version tags (module directory without major version suffix)
+	TagPrefix string
+}
+
+// Locate returns location of module inside repository with root at repoRoot path.
+// Module path must be equal to repoRoot or be inside it.
+func Locate(repoRoot, modulePath string) Location {
+	subdir := strings.Trim(strings.TrimPrefix(modulePath, repoRoot), "/")
+
+	dir, last := path.Split(subdir)
+	if !majorSuffixRe.MatchString(last) {
+		return Location{Dirs: []string{subdir}, TagPrefix: subdir}
+	}
+
+	dir = strings.TrimSuffix(dir, "/")
+
+	return Location{Dirs: []string{subdir, dir}, TagPrefix: dir}
+}
+
+// Parents returns directory and all its parent directories up to repository root (empty string)
+func Parents(dir string) []string {
+	ret := []string{dir}
+	for dir != "" {
+		dir = path.Dir(dir)
+		if dir == "." || dir == "/" {
+			dir = ""
+		}
+
+		ret = append(ret, dir)
+	}
+
+	return ret
+}
@@ -0,0 +1,50 @@
+package vcsref_test
+
+import (
+	"testing"
+
+	"github.com/xakep666/licensevalidator/pkg/vcsref"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromVersion(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		TagPrefix string
+		Version   string
+		Expected  vcsref.Ref
+	}
+
+	f := func(tc testCase) {
+		t.Run(tc.TagPrefix+"@"+tc.Version, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, vcsref.FromVersion(tc.TagPrefix, semver.MustParse(tc.Version)))
+		})
+	}
+
+	f(testCase{Version: "v1.5.1", Expected: vcsref.Ref{Tag: "v1.5.1"}})
+	f(testCase{Version: "v1.0.0-rc.1", Expected: vcsref.Ref{Tag: "v1.0.0-rc.1"}})
+	f(testCase{TagPrefix: "sub/dir", Version: "v2.1.0", Expected: vcsref.Ref{Tag: "sub/dir/v2.1.0"}})
+	f(testCase{Version: "v3.2.0+incompatible", Expected: vcsref.Ref{Tag: "v3.2.0"}})
+	f(testCase{Version: "v0.0.0-20200601120000-abcdef123456", Expected: vcsref.Ref{Commit: "abcdef123456"}})
+	f(testCase{Version: "v1.2.4-0.20200601120000-abcdef123456", Expected: vcsref.Ref{Commit: "abcdef123456"}})
+	f(testCase{Version: "v1.2.4-pre.0.20200601120000-abcdef123456", Expected: vcsref.Ref{Commit: "abcdef123456"}})
+	f(testCase{Version: "v2.0.0-20200601120000-abcdef123456+incompatible", Expected: vcsref.Ref{Commit: "abcdef123456"}})
+}
+
+func TestLocate(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, vcsref.Location{Dirs: []string{""}}, vcsref.Locate("example.com/repo", "example.com/repo"))
+	assert.Equal(t, vcsref.Location{Dirs: []string{"v2", ""}}, vcsref.Locate("example.com/repo", "example.com/repo/v2"))
+	assert.Equal(t, vcsref.Location{Dirs: []string{"sub/v10", "sub"}, TagPrefix: "sub"}, vcsref.Locate("example.com/repo", "example.com/repo/sub/v10"))
+	assert.Equal(t, vcsref.Location{Dirs: []string{"sub/v1"}, TagPrefix: "sub/v1"}, vcsref.Locate("example.com/repo", "example.com/repo/sub/v1"))
+}
+
+func TestParents(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{""}, vcsref.Parents(""))
+	assert.Equal(t, []string{"a/b", "a", ""}, vcsref.Parents("a/b"))
+}